@@ -39,15 +39,24 @@ def sudo(command: str) -> str:
     return cmd.sudo("-A", str.split(command))
 
 
-def create_veth(host: str, container: str, ip: str, mac: str, ns: str, neighbors: List[str]):
+def create_veth(host: str, container: str, ip: str, mac: str, ns: str, neighbors: List[str],
+                ipv6: bool = False):
     sudo("ip link add %s mtu 8000 type veth peer name %s mtu 8000" % (host, container))
     sudo("sysctl -qw net.ipv6.conf.%s.disable_ipv6=1" % host)
     sudo("ip link set %s up" % host)
     sudo("ip link set %s netns %s" % (container, ns))
-    sudo("ip netns exec %s sysctl -qw net.ipv6.conf.%s.disable_ipv6=1" % (ns, container))
+    if ipv6:
+        # Keep the IPv6 stack quiet, so that no neighbor discovery or router
+        # solicitation packets are captured by the test cases.
+        for opt in ["accept_dad=0", "accept_ra=0", "router_solicitations=0",
+                    "addr_gen_mode=1", "disable_ipv6=0"]:
+            sudo("ip netns exec %s sysctl -qw net.ipv6.conf.%s.%s" % (ns, container, opt))
+    else:
+        sudo("ip netns exec %s sysctl -qw net.ipv6.conf.%s.disable_ipv6=1" % (ns, container))
     sudo("ip netns exec %s ethtool -K %s rx off tx off" % (ns, container))
     sudo("ip netns exec %s ip link set %s address %s" % (ns, container, mac))
-    sudo("ip netns exec %s ip addr add %s dev %s" % (ns, ip, container))
+    nodad = " nodad" if ipv6 else ""
+    sudo("ip netns exec %s ip addr add %s dev %s%s" % (ns, ip, container, nodad))
     for n in neighbors:
         sudo("ip netns exec %s ip neigh add %s lladdr f0:0d:ca:fe:be:ef nud permanent dev %s"
              % (ns, n, container))
@@ -76,6 +85,11 @@ class RouterTest(base.TestBase):
         help="use BFD",
     )
 
+    scmp_suppress = cli.Flag(
+        "scmp_suppress",
+        help="suppress SCMP parameter problem messages",
+    )
+
     def setup_prepare(self):
         super().setup_prepare()
 
@@ -98,6 +112,11 @@ class RouterTest(base.TestBase):
             exec_docker(f"run -v {self.artifacts}/conf:/etc/scion -d "
                         "--network container:pause --name router "
                         "scion/router:latest")
+        elif self.scmp_suppress:
+            exec_docker(f"run -v {self.artifacts}/conf:/etc/scion -d "
+                        "--network container:pause --name router "
+                        "scion/router:latest "
+                        "--config /etc/scion/router_scmp_suppress.toml")
         else:
             exec_docker(f"run -v {self.artifacts}/conf:/etc/scion -d "
                         "--network container:pause --name router "
@@ -107,10 +126,12 @@ class RouterTest(base.TestBase):
 
     def _run(self):
         braccept = self.get_executable("braccept")
-        bfd_arg = ""
+        mode_arg = ""
         if self.bfd:
-            bfd_arg = "--bfd"
-        sudo("%s --artifacts %s %s" % (braccept.executable, self.artifacts, bfd_arg))
+            mode_arg = "--bfd"
+        elif self.scmp_suppress:
+            mode_arg = "--scmp_suppress"
+        sudo("%s --artifacts %s %s" % (braccept.executable, self.artifacts, mode_arg))
 
     def teardown(self):
         cmd.docker["logs", "router"].run_fg(retcode=None)
@@ -122,6 +143,7 @@ class RouterTest(base.TestBase):
         # Set default TTL for outgoing packets to the common value 64, so that packets sent
         # from router will match the expected value.
         sudo("ip netns exec %s sysctl -w net.ipv4.ip_default_ttl=64" % ns)
+        sudo("ip netns exec %s sysctl -w net.ipv6.conf.default.hop_limit=64" % ns)
 
         create_veth("veth_int_host", "veth_int", "192.168.0.11/24", "f0:0d:ca:fe:00:01", ns,
                     ["192.168.0.12", "192.168.0.13", "192.168.0.14", "192.168.0.51", "192.168.0.61",
@@ -134,6 +156,13 @@ class RouterTest(base.TestBase):
                     ["192.168.14.3"])
         create_veth("veth_151_host", "veth_151", "192.168.15.2/31", "f0:0d:ca:fe:00:15", ns,
                     ["192.168.15.3"])
+        # Interfaces with an IPv6 underlay.
+        create_veth("veth_132_host", "veth_132", "fd00:f00d:cafe:13::2/127", "f0:0d:ca:fe:00:32",
+                    ns, ["fd00:f00d:cafe:13::3"], ipv6=True)
+        create_veth("veth_142_host", "veth_142", "fd00:f00d:cafe:14::2/127", "f0:0d:ca:fe:00:42",
+                    ns, ["fd00:f00d:cafe:14::3"], ipv6=True)
+        create_veth("veth_152_host", "veth_152", "fd00:f00d:cafe:15::2/127", "f0:0d:ca:fe:00:52",
+                    ns, ["fd00:f00d:cafe:15::3"], ipv6=True)
 
 
 if __name__ == "__main__":
@@ -407,30 +407,41 @@ func realMain(ctx context.Context) error {
 		libmetrics.GaugeWith(renewalGauges, "type", "in-process").Set(0)
 		libmetrics.GaugeWith(renewalGauges, "type", "delegating").Set(0)
 		srvCtr := libmetrics.NewPromCounter(metrics.RenewalServerRequestsTotal)
+		srvDur := libmetrics.NewPromHistogram(metrics.RenewalServerStepDuration)
 		renewalServer := &renewalgrpc.RenewalServer{
 			IA:        topo.IA(),
 			CMSSigner: signer,
 			Metrics: renewalgrpc.RenewalServerMetrics{
-				Success:       srvCtr.With(prom.LabelResult, prom.Success),
-				BackendErrors: srvCtr.With(prom.LabelResult, prom.StatusErr),
+				Success:        srvCtr.With(prom.LabelResult, prom.Success),
+				BackendErrors:  srvCtr.With(prom.LabelResult, prom.StatusErr),
+				ContextDone:    srvCtr.With(prom.LabelResult, prom.ErrTimeout),
+				HandleDuration: srvDur.With("step", "handle"),
+				SignDuration:   srvDur.With("step", "sign"),
 			},
 		}
 
 		switch globalCfg.CA.Mode {
 		case config.InProcess:
 			libmetrics.GaugeWith(renewalGauges, "type", "in-process").Set(1)
+			cmsLabels := []string{"type", "in-process"}
+			if globalCfg.CA.DisableISDASMetricsLabel {
+				cmsLabels = append(cmsLabels, "isd_as", "")
+			}
 			cmsCtr := libmetrics.CounterWith(
 				libmetrics.NewPromCounter(metrics.RenewalHandledRequestsTotal),
-				"type", "in-process",
+				cmsLabels...,
 			)
+			cmsValidity := libmetrics.NewPromHistogram(metrics.RenewalIssuedValidity).
+				With("type", "in-process")
 			chainBuilder = cs.NewChainBuilder(
 				cs.ChainBuilderConfig{
-					IA:                   topo.IA(),
-					DB:                   trustDB,
-					MaxValidity:          globalCfg.CA.MaxASValidity.Duration,
-					ConfigDir:            globalCfg.General.ConfigDir,
-					Metrics:              metrics.RenewalMetrics,
-					ForceECDSAWithSHA512: !globalCfg.Features.AppropriateDigest,
+					IA:                      topo.IA(),
+					DB:                      trustDB,
+					MaxValidity:             globalCfg.CA.MaxASValidity.Duration,
+					ConfigDir:               globalCfg.General.ConfigDir,
+					Metrics:                 metrics.RenewalMetrics,
+					ForceECDSAWithSHA512:    !globalCfg.Features.AppropriateDigest,
+					RejectExcessiveValidity: globalCfg.CA.RejectExcessiveValidity,
 				},
 			)
 
@@ -440,20 +451,28 @@ func realMain(ctx context.Context) error {
 				Verifier: renewal.RequestVerifier{
 					TRCFetcher: trustDB,
 				},
+				CSRValidator: renewalgrpc.ASProfileValidator{},
 				Metrics: renewalgrpc.CMSHandlerMetrics{
-					Success:       cmsCtr.With(prom.LabelResult, prom.Success),
-					DatabaseError: cmsCtr.With(prom.LabelResult, prom.ErrDB),
-					InternalError: cmsCtr.With(prom.LabelResult, prom.ErrInternal),
-					NotFoundError: cmsCtr.With(prom.LabelResult, prom.ErrNotFound),
-					ParseError:    cmsCtr.With(prom.LabelResult, prom.ErrParse),
-					VerifyError:   cmsCtr.With(prom.LabelResult, prom.ErrVerify),
+					Success:         cmsCtr.With(prom.LabelResult, prom.Success),
+					ContextDone:     cmsCtr.With(prom.LabelResult, prom.ErrTimeout),
+					DatabaseError:   cmsCtr.With(prom.LabelResult, prom.ErrDB),
+					InternalError:   cmsCtr.With(prom.LabelResult, prom.ErrInternal),
+					InvalidCSR:      cmsCtr.With(prom.LabelResult, prom.ErrValidate),
+					NotFoundError:   cmsCtr.With(prom.LabelResult, prom.ErrNotFound),
+					ParseError:      cmsCtr.With(prom.LabelResult, prom.ErrParse),
+					RequestTooLarge: cmsCtr.With(prom.LabelResult, prom.ErrInvalidReq),
+					VerifyError:     cmsCtr.With(prom.LabelResult, prom.ErrVerify),
+
+					ExcessiveValidity: cmsCtr.With(prom.LabelResult, prom.ErrExcessiveValidity),
+					IssuedValidity:    cmsValidity,
+					ISDASLabel:        !globalCfg.CA.DisableISDASMetricsLabel,
 				},
 			}
 		case config.Delegating:
 			libmetrics.GaugeWith(renewalGauges, "type", "delegating").Set(1)
 			delCtr := libmetrics.CounterWith(
 				libmetrics.NewPromCounter(metrics.RenewalHandledRequestsTotal),
-				"type", "delegating",
+				"type", "delegating", "isd_as", "",
 			)
 			sharedSecret := caconfig.NewPEMSymmetricKey(globalCfg.CA.Service.SharedSecret)
 			subject := globalCfg.General.ID
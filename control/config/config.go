@@ -264,6 +264,14 @@ func (cfg *Policies) ConfigName() string {
 type CA struct {
 	// MaxASValidity is the maximum AS certificate lifetime.
 	MaxASValidity util.DurWrap `toml:"max_as_validity,omitempty"`
+	// RejectExcessiveValidity rejects certificate requests that ask for a
+	// validity period longer than MaxASValidity. By default, the validity is
+	// clamped to MaxASValidity.
+	RejectExcessiveValidity bool `toml:"reject_excessive_validity,omitempty"`
+	// DisableISDASMetricsLabel disables the isd_as label of the renewal
+	// request metrics of the in-process handler. The label results in one
+	// time series per requesting AS.
+	DisableISDASMetricsLabel bool `toml:"disable_isd_as_metrics_label,omitempty"`
 	// Mode defines whether the Control Service should handle certificate
 	// issuance requests on its own, or whether to delegate handling to a
 	// dedicated Certificate Authority. If it is the empty string, the
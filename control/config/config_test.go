@@ -100,6 +100,8 @@ func InitTestCA(cfg *CA) {
 
 func CheckTestCA(t *testing.T, cfg *CA) {
 	assert.Equal(t, DefaultMaxASValidity, cfg.MaxASValidity.Duration)
+	assert.False(t, cfg.RejectExcessiveValidity)
+	assert.False(t, cfg.DisableISDASMetricsLabel)
 	assert.Equal(t, cfg.Mode, InProcess)
 	CheckTestService(t, &cfg.Service)
 }
@@ -34,6 +34,16 @@ const caSample = `
 # loaded that satisfies the condition. (default 3d)
 max_as_validity = "3d"
 
+# Whether certificate requests that ask for a validity period longer than
+# max_as_validity are rejected. If false, the validity of the issued certificate
+# is clamped to max_as_validity. (default false)
+reject_excessive_validity = false
+
+# Whether the isd_as label of the renewal request metrics is disabled. The
+# label carries the ISD-AS of verified requesters, which results in one time
+# series per requesting AS. (default false)
+disable_isd_as_metrics_label = false
+
 # The mode the CA handler of this control service operates in.
 #
 # - disabled:   In this mode, control AS is not a CA.
@@ -71,6 +71,8 @@ type Metrics struct {
 	PathDBQueriesTotal                     *prometheus.CounterVec
 	RenewalServerRequestsTotal             *prometheus.CounterVec
 	RenewalHandledRequestsTotal            *prometheus.CounterVec
+	RenewalServerStepDuration              *prometheus.HistogramVec
+	RenewalIssuedValidity                  *prometheus.HistogramVec
 	RenewalRegisteredHandlers              *prometheus.GaugeVec
 	SegmentLookupRequestsTotal             *prometheus.CounterVec
 	SegmentLookupSegmentsSentTotal         *prometheus.CounterVec
@@ -114,6 +116,13 @@ func NewMetrics() *Metrics {
 		},
 		[]string{prom.LabelResult},
 	))
+	renewalExcessive := metrics.NewPromCounter(promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "renewal_excessive_validity_requests_total",
+			Help: "Number of certificate requests with a validity exceeding the CA policy",
+		},
+		[]string{},
+	))
 	renewalGenerated := metrics.NewPromGauge(promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "renewal_last_signer_generation_time_second",
@@ -215,7 +224,23 @@ func NewMetrics() *Metrics {
 				Help: "Total number of renewal requests served by each handler type" +
 					" (legacy, in-process, delegating).",
 			},
-			[]string{prom.LabelResult, "type"},
+			[]string{prom.LabelResult, "type", "isd_as"},
+		),
+		RenewalServerStepDuration: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "renewal_request_step_duration_seconds",
+				Help:    "Time spent in each step (handle, sign) of serving a renewal request.",
+				Buckets: prom.DefaultLatencyBuckets,
+			},
+			[]string{"step"},
+		),
+		RenewalIssuedValidity: promauto.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "renewal_issued_certificate_validity_hours",
+				Help:    "Validity period in hours of the issued AS certificates.",
+				Buckets: []float64{1, 6, 12, 24, 48, 72, 168, 336, 720, 2160, 8760},
+			},
+			[]string{"type"},
 		),
 		RenewalRegisteredHandlers: promauto.NewGaugeVec(
 			prometheus.GaugeOpts{
@@ -314,8 +339,9 @@ func NewMetrics() *Metrics {
 			SignedChains: func(result string) metrics.Counter {
 				return renewalChains.With(prom.LabelResult, result)
 			},
-			LastGeneratedCA: renewalGenerated,
-			ExpirationCA:    renewalExpiration,
+			LastGeneratedCA:   renewalGenerated,
+			ExpirationCA:      renewalExpiration,
+			ExcessiveValidity: renewalExcessive,
 		},
 	}
 }
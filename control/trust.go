@@ -126,6 +126,10 @@ type ChainBuilderConfig struct {
 	ConfigDir   string
 	Metrics     renewal.Metrics
 
+	// RejectExcessiveValidity rejects requests that ask for a validity period
+	// longer than MaxValidity instead of clamping it.
+	RejectExcessiveValidity bool
+
 	// ForceECDSAWithSHA512 forces the CA policy to use ECDSAWithSHA512 as the
 	// signature algorithm for signing the issued certificate. This field
 	// forces the old behavior extending the acceptable signature algorithms
@@ -156,6 +160,8 @@ func NewChainBuilder(cfg ChainBuilderConfig) renewal.ChainBuilder {
 			LastGeneratedCA: cfg.Metrics.LastGeneratedCA,
 			ExpirationCA:    cfg.Metrics.ExpirationCA,
 		},
-		SignedChains: cfg.Metrics.SignedChains,
+		SignedChains:            cfg.Metrics.SignedChains,
+		RejectExcessiveValidity: cfg.RejectExcessiveValidity,
+		ExcessiveValidity:       cfg.Metrics.ExcessiveValidity,
 	}
 }
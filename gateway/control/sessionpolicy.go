@@ -187,7 +187,7 @@ func (sp SessionPolicy) Copy() SessionPolicy {
 }
 
 func copyTrafficMatcher(m pktcls.Cond) pktcls.Cond {
-	copy, err := pktcls.BuildClassTree(m.String())
+	copy, err := pktcls.ParseCond(m.String())
 	if err != nil {
 		panic(err)
 	}
@@ -222,3 +222,18 @@ func TestCopyPathPolicy(t *testing.T) {
 	assert.Equal(t, input, p)
 	assert.NotSame(t, input, p)
 }
+
+func TestSessionPolicyCopy(t *testing.T) {
+	// The traffic matcher is copied through its String representation, which
+	// must be parseable for all predicates.
+	input := control.SessionPolicy{
+		ID: 1,
+		IA: addr.MustParseIA("1-ff00:0:110"),
+		TrafficMatcher: pktcls.NewCondAllOf(
+			pktcls.NewCondIPv4(&pktcls.IPv4MatchTTL{Op: pktcls.CmpLt, TTL: 5}),
+			pktcls.NewCondNot(pktcls.NewCondIPv6(&pktcls.IPv6MatchFlowLabel{FlowLabel: 1})),
+		),
+	}
+	p := input.Copy()
+	assert.Equal(t, input.TrafficMatcher, p.TrafficMatcher)
+}
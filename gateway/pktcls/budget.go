@@ -0,0 +1,114 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls
+
+import (
+	"fmt"
+
+	"github.com/gopacket/gopacket"
+
+	"github.com/scionproto/scion/pkg/metrics"
+)
+
+const typeCondBudget = "CondBudget"
+
+var _ Cond = (*CondBudget)(nil)
+
+// CondBudget limits the cost of evaluating the embedded condition. Each
+// condition that is evaluated, including AnyOf, AllOf and Not, consumes one
+// unit of the budget. If more than Budget conditions would have to be
+// evaluated for a packet, the evaluation is aborted, Exceeded is incremented
+// and Default is returned.
+//
+// CondBudget guards the data path against pathological condition trees. It is
+// meant to wrap conditions after they have been loaded and is not part of the
+// JSON encoding of classes.
+type CondBudget struct {
+	// Operand is the condition that is evaluated.
+	Operand Cond
+	// Budget is the maximum number of conditions evaluated per packet.
+	Budget int
+	// Default is the result if the budget is exceeded.
+	Default bool
+	// Exceeded counts the packets for which the budget was exceeded. It is
+	// optional.
+	Exceeded metrics.Counter
+}
+
+func (c *CondBudget) Eval(v gopacket.Layer) bool {
+	if c.Operand == nil {
+		return false
+	}
+	remaining := c.Budget
+	result, ok := evalBudget(c.Operand, v, &remaining)
+	if !ok {
+		metrics.CounterInc(c.Exceeded)
+		return c.Default
+	}
+	return result
+}
+
+func (c *CondBudget) Type() string {
+	return typeCondBudget
+}
+
+func (c *CondBudget) String() string {
+	return fmt.Sprintf("budget(%d,%t,%v)", c.Budget, c.Default, c.Operand)
+}
+
+// evalBudget evaluates the condition and decrements remaining for every
+// evaluated condition. It returns false as second value if the budget is
+// exhausted before the result is known.
+func evalBudget(c Cond, v gopacket.Layer, remaining *int) (bool, bool) {
+	if *remaining <= 0 {
+		return false, false
+	}
+	*remaining--
+	switch c := c.(type) {
+	case CondAnyOf:
+		if len(c) == 0 {
+			return true, true
+		}
+		for _, child := range c {
+			r, ok := evalBudget(child, v, remaining)
+			if !ok {
+				return false, false
+			}
+			if r {
+				return true, true
+			}
+		}
+		return false, true
+	case CondAllOf:
+		for _, child := range c {
+			r, ok := evalBudget(child, v, remaining)
+			if !ok {
+				return false, false
+			}
+			if !r {
+				return false, true
+			}
+		}
+		return true, true
+	case CondNot:
+		if c.Operand == nil {
+			return false, true
+		}
+		r, ok := evalBudget(c.Operand, v, remaining)
+		return !r, ok
+	default:
+		return c.Eval(v), true
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scionproto/scion/gateway/pktcls"
+	"github.com/scionproto/scion/pkg/metrics"
+)
+
+func TestCondBudget(t *testing.T) {
+	pkt := createUDPPacket(150, 80)
+	srcPort := func(low, high uint16) pktcls.Cond {
+		return pktcls.NewCondPorts(&pktcls.PortMatchSource{MinPort: low, MaxPort: high})
+	}
+	// nonMatching returns a list of n port conditions that do not match pkt.
+	nonMatching := func(n int) []pktcls.Cond {
+		var conds []pktcls.Cond
+		for i := 0; i < n; i++ {
+			conds = append(conds, srcPort(1000, 2000))
+		}
+		return conds
+	}
+
+	testCases := map[string]struct {
+		Cond        pktcls.Cond
+		Budget      int
+		Default     bool
+		ExpEval     bool
+		ExpExceeded float64
+	}{
+		"within budget": {
+			// any + 3 leaves
+			Cond:    pktcls.NewCondAnyOf(append(nonMatching(2), srcPort(100, 199))...),
+			Budget:  4,
+			ExpEval: true,
+		},
+		"short circuit stays within budget": {
+			Cond: pktcls.NewCondAnyOf(
+				append([]pktcls.Cond{srcPort(100, 199)}, nonMatching(100)...)...),
+			Budget:  2,
+			ExpEval: true,
+		},
+		"exceeded returns default true": {
+			Cond:        pktcls.NewCondAnyOf(append(nonMatching(100), srcPort(100, 199))...),
+			Budget:      10,
+			Default:     true,
+			ExpEval:     true,
+			ExpExceeded: 1,
+		},
+		"exceeded returns default false": {
+			Cond:        pktcls.NewCondAnyOf(append(nonMatching(100), srcPort(100, 199))...),
+			Budget:      10,
+			Default:     false,
+			ExpEval:     false,
+			ExpExceeded: 1,
+		},
+		"deep nesting exceeded": {
+			Cond: func() pktcls.Cond {
+				var c pktcls.Cond = srcPort(100, 199)
+				for i := 0; i < 50; i++ {
+					c = pktcls.NewCondNot(pktcls.NewCondNot(c))
+				}
+				return c
+			}(),
+			Budget:      50,
+			Default:     false,
+			ExpEval:     false,
+			ExpExceeded: 1,
+		},
+		"not within budget": {
+			Cond:    pktcls.NewCondNot(pktcls.NewCondAllOf(nonMatching(3)...)),
+			Budget:  3,
+			ExpEval: true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			exceeded := metrics.NewTestCounter()
+			cond := &pktcls.CondBudget{
+				Operand:  tc.Cond,
+				Budget:   tc.Budget,
+				Default:  tc.Default,
+				Exceeded: exceeded,
+			}
+			assert.Equal(t, tc.ExpEval, cond.Eval(pkt))
+			assert.Equal(t, tc.ExpExceeded, metrics.CounterValue(exceeded))
+			if tc.ExpExceeded == 0 {
+				assert.Equal(t, tc.Cond.Eval(pkt), cond.Eval(pkt))
+			}
+		})
+	}
+}
@@ -18,6 +18,8 @@ import (
 	"encoding/json"
 
 	"github.com/gopacket/gopacket"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
 )
 
 var (
@@ -73,6 +75,9 @@ func (cm *ClassMap) UnmarshalJSON(b []byte) error {
 		return err
 	}
 	for className, class := range *cm {
+		if class == nil {
+			return serrors.New("class without condition", "class", className)
+		}
 		class.name = className
 	}
 	return nil
@@ -88,6 +93,9 @@ func (cm *ClassMap) UnmarshalYAML(unmarshal func(any) error) error {
 		return err
 	}
 	for className, class := range *cm {
+		if class == nil {
+			return serrors.New("class without condition", "class", className)
+		}
 		class.name = className
 	}
 	return nil
@@ -26,7 +26,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/scionproto/scion/gateway/pktcls"
+	"github.com/scionproto/scion/pkg/addr"
 	"github.com/scionproto/scion/pkg/private/xtest"
+	"github.com/scionproto/scion/pkg/slayers/path/scion"
 )
 
 var (
@@ -67,12 +69,58 @@ func TestClassMapMarshalUnMarshal(t *testing.T) {
 							},
 						}),
 						pktcls.NewCondPorts(&pktcls.PortMatchSource{MinPort: 1, MaxPort: 10}),
+						pktcls.NewCondIPv4(&pktcls.IPv4MatchProtocol{Protocol: 253}),
 					),
 				),
 				"classC": pktcls.NewClass(
 					"classC",
 					pktcls.NewCondAllOf(),
 				),
+				"congested": pktcls.NewClass(
+					"congested",
+					pktcls.NewCondIPv4(&pktcls.IPv4MatchECN{ECN: 0x3}),
+				),
+				"last hop": pktcls.NewClass(
+					"last hop",
+					pktcls.NewCondIPv4(&pktcls.IPv4MatchTTL{Op: pktcls.CmpEq, TTL: 1}),
+				),
+				"options": pktcls.NewClass(
+					"options",
+					pktcls.NewCondAnyOf(
+						pktcls.NewCondIPv4(&pktcls.IPv4MatchHasOptions{Any: true}),
+						pktcls.NewCondIPv4(&pktcls.IPv4MatchHasOptions{OptionType: 131}),
+					),
+				),
+				"scion": pktcls.NewClass(
+					"scion",
+					pktcls.NewCondAllOf(
+						pktcls.NewCondSCION(&pktcls.SCIONMatchPathType{PathType: scion.PathType}),
+						pktcls.NewCondSCION(&pktcls.SCIONMatchTrafficClass{TrafficClass: 0xb8}),
+						pktcls.NewCondSCION(&pktcls.SCIONMatchSrcIA{
+							IA: addr.MustParseIA("1-ff00:0:110"),
+						}),
+						pktcls.NewCondSCION(&pktcls.SCIONMatchDstIA{IA: addr.MustParseIA("2-0")}),
+					),
+				),
+				"not marked ISD 3": pktcls.NewClass(
+					"not marked ISD 3",
+					pktcls.NewCondNot(
+						pktcls.NewCondAllOf(
+							pktcls.NewCondIPv4(&pktcls.IPv4MatchSource{
+								Net: &net.IPNet{
+									IP:   net.IP{10, 0, 0, 0},
+									Mask: net.IPv4Mask(255, 0, 0, 0),
+								},
+							}),
+							pktcls.NewCondAnyOf(
+								pktcls.NewCondIPv4(&pktcls.IPv4MatchDSCP{DSCP: 0x2e}),
+								pktcls.NewCondNot(
+									pktcls.NewCondIPv4(&pktcls.IPv4MatchProtocol{Protocol: 17}),
+								),
+							),
+						),
+					),
+				),
 			},
 		},
 		{
@@ -167,6 +215,104 @@ func TestClassUnmarshalError(t *testing.T) {
 			"Name": "Unable to parse ToS operand string"
 		}
 		`, `
+		{
+			"CondIPv4": {
+				"MatchProtocol": {
+					"Protocol": "256"
+				}
+			},
+			"Name": "Protocol number too large"
+		}
+		`, `
+		{
+			"CondIPv4": {
+				"MatchProtocol": {
+					"Protocol": ""
+				}
+			},
+			"Name": "Empty protocol"
+		}		`, `
+		{
+			"CondIPv4": {
+				"MatchECN": {
+					"ECN": "ECT(2)"
+				}
+			},
+			"Name": "Unknown ECN codepoint"
+		}
+		`, `
+		{
+			"CondIPv4": {
+				"MatchECN": {
+					"ECN": 3
+				}
+			},
+			"Name": "Numeric ECN codepoint"
+		}		`, `
+		{
+			"CondIPv4": {
+				"MatchTTL": {
+					"Op": "<"
+				}
+			},
+			"Name": "No TTL operand"
+		}
+		`, `
+		{
+			"CondIPv4": {
+				"MatchTTL": {
+					"Op": "<",
+					"TTL": "256"
+				}
+			},
+			"Name": "TTL operand too large"
+		}
+		`, `
+		{
+			"CondIPv4": {
+				"MatchTTL": {
+					"Op": "<=",
+					"TTL": "5"
+				}
+			},
+			"Name": "Unknown TTL operator"
+		}
+		`, `
+		{
+			"CondIPv4": {
+				"MatchTTL": {
+					"Op": "range",
+					"TTL": "5"
+				}
+			},
+			"Name": "Unsupported TTL operator"
+		}
+		`, `
+		{
+			"CondIPv4": {
+				"MatchHasOptions": {}
+			},
+			"Name": "No option operand"
+		}
+		`, `
+		{
+			"CondIPv4": {
+				"MatchHasOptions": {
+					"Option": "256"
+				}
+			},
+			"Name": "Option type too large"
+		}
+		`, `
+		{
+			"CondIPv4": {
+				"MatchHasOptions": {
+					"Option": "lsrr"
+				}
+			},
+			"Name": "Unknown option mode"
+		}
+		`, `
 		{
 			"CondIPv4": {
 				"MatchDestination": {
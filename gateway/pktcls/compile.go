@@ -0,0 +1,223 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+)
+
+const typeCondNetSet = "CondNetSet"
+
+// Compile returns a condition that evaluates to the same result as c, but is
+// faster to evaluate for conditions with many network predicates. In every
+// AnyOf condition, the IPv4MatchSource predicates and the IPv4MatchDestination
+// predicates are each replaced by a single condition that looks up the address
+// in a prefix trie. All other conditions are evaluated as before.
+// The compiled condition consumes fewer units of a CondBudget.
+//
+// Like CondBudget, the compiled condition is meant to be used after the
+// classes have been loaded and is not part of the JSON encoding of classes.
+func Compile(c Cond) Cond {
+	switch c := c.(type) {
+	case CondAnyOf:
+		return compileAnyOf(c)
+	case CondAllOf:
+		compiled := make(CondAllOf, 0, len(c))
+		for _, child := range c {
+			compiled = append(compiled, Compile(child))
+		}
+		return compiled
+	case CondNot:
+		if c.Operand == nil {
+			return c
+		}
+		return CondNot{Operand: Compile(c.Operand)}
+	case *CondBudget:
+		compiled := *c
+		if c.Operand != nil {
+			compiled.Operand = Compile(c.Operand)
+		}
+		return &compiled
+	default:
+		return c
+	}
+}
+
+func compileAnyOf(c CondAnyOf) Cond {
+	// An empty AnyOf is true, an AnyOf with only network predicates is not.
+	if len(c) == 0 {
+		return c
+	}
+	src := &condNetSet{}
+	dst := &condNetSet{dst: true}
+	var rest []Cond
+	for _, child := range c {
+		child = Compile(child)
+		if !src.add(child) && !dst.add(child) {
+			rest = append(rest, child)
+		}
+	}
+	var compiled CondAnyOf
+	// Networks are checked first, since they are the cheapest to evaluate.
+	for _, s := range []*condNetSet{src, dst} {
+		switch len(s.nets) {
+		case 0:
+		case 1:
+			compiled = append(compiled, s.conds[0])
+		default:
+			compiled = append(compiled, s)
+		}
+	}
+	compiled = append(compiled, rest...)
+	if len(compiled) == 1 {
+		return compiled[0]
+	}
+	return compiled
+}
+
+var _ Cond = (*condNetSet)(nil)
+
+// condNetSet returns true if the source, or destination, address of an IPv4
+// packet is contained in any of the networks.
+type condNetSet struct {
+	dst   bool
+	trie  prefixTrie
+	nets  []*net.IPNet
+	conds []Cond
+}
+
+// add adds the network of the condition to the set. It returns false if the
+// condition is not a network predicate of the direction of the set, or if the
+// network is not a canonical IPv4 network.
+func (s *condNetSet) add(c Cond) bool {
+	ipv4, ok := c.(*CondIPv4)
+	if !ok {
+		return false
+	}
+	var n *net.IPNet
+	switch p := ipv4.Predicate.(type) {
+	case *IPv4MatchSource:
+		if s.dst {
+			return false
+		}
+		n = p.Net
+	case *IPv4MatchDestination:
+		if !s.dst {
+			return false
+		}
+		n = p.Net
+	default:
+		return false
+	}
+	if n == nil || n.IP.To4() == nil || len(n.Mask) != net.IPv4len {
+		return false
+	}
+	ones, bits := n.Mask.Size()
+	if bits == 0 {
+		return false
+	}
+	s.trie.insert(binary.BigEndian.Uint32(n.IP.To4()), ones)
+	s.nets = append(s.nets, n)
+	s.conds = append(s.conds, c)
+	return true
+}
+
+func (s *condNetSet) Eval(v gopacket.Layer) bool {
+	p, ok := v.(*layers.IPv4)
+	if !ok {
+		return false
+	}
+	ip := p.SrcIP
+	if s.dst {
+		ip = p.DstIP
+	}
+	ip = ip.To4()
+	if ip == nil {
+		return false
+	}
+	return s.trie.contains(binary.BigEndian.Uint32(ip))
+}
+
+func (s *condNetSet) Type() string {
+	return typeCondNetSet
+}
+
+func (s *condNetSet) String() string {
+	options := make([]string, 0, len(s.conds))
+	for _, c := range s.conds {
+		options = append(options, c.String())
+	}
+	return fmt.Sprintf("any(%s)", strings.Join(options, ","))
+}
+
+// prefixTrie is a binary trie of IPv4 prefixes. Since only the existence of a
+// matching prefix is of interest, the lookup stops at the shortest match.
+type prefixTrie struct {
+	nodes []trieNode
+}
+
+type trieNode struct {
+	// children contains the indices of the children, zero if there is none.
+	children [2]uint32
+	// terminal is set if a prefix ends at the node.
+	terminal bool
+}
+
+func (t *prefixTrie) insert(prefix uint32, ones int) {
+	if len(t.nodes) == 0 {
+		t.nodes = append(t.nodes, trieNode{})
+	}
+	n := uint32(0)
+	for i := 0; i < ones; i++ {
+		if t.nodes[n].terminal {
+			// A shorter prefix already covers this one.
+			return
+		}
+		bit := prefix >> (31 - i) & 1
+		if t.nodes[n].children[bit] == 0 {
+			t.nodes = append(t.nodes, trieNode{})
+			t.nodes[n].children[bit] = uint32(len(t.nodes) - 1)
+		}
+		n = t.nodes[n].children[bit]
+	}
+	t.nodes[n].terminal = true
+	// Longer prefixes are covered by this one.
+	t.nodes[n].children = [2]uint32{}
+}
+
+func (t *prefixTrie) contains(addr uint32) bool {
+	if len(t.nodes) == 0 {
+		return false
+	}
+	n := uint32(0)
+	for i := 0; ; i++ {
+		if t.nodes[n].terminal {
+			return true
+		}
+		if i == 32 {
+			return false
+		}
+		n = t.nodes[n].children[addr>>(31-i)&1]
+		if n == 0 {
+			return false
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls_test
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"testing"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/gateway/pktcls"
+)
+
+func TestCompile(t *testing.T) {
+	src := func(s string) pktcls.Cond {
+		_, n, err := net.ParseCIDR(s)
+		require.NoError(t, err)
+		return pktcls.NewCondIPv4(&pktcls.IPv4MatchSource{Net: n})
+	}
+	dst := func(s string) pktcls.Cond {
+		_, n, err := net.ParseCIDR(s)
+		require.NoError(t, err)
+		return pktcls.NewCondIPv4(&pktcls.IPv4MatchDestination{Net: n})
+	}
+	tos := pktcls.NewCondIPv4(&pktcls.IPv4MatchToS{TOS: 0x80})
+
+	testCases := map[string]pktcls.Cond{
+		"overlapping networks": pktcls.NewCondAnyOf(
+			src("10.1.0.0/16"), src("10.0.0.0/8"), src("10.1.2.0/24"), src("192.168.1.1/32"),
+		),
+		"default route": pktcls.NewCondAnyOf(src("10.0.0.0/8"), src("0.0.0.0/0")),
+		"source and destination": pktcls.NewCondAnyOf(
+			src("10.0.0.0/8"), dst("10.0.0.0/8"), src("172.16.0.0/12"), dst("192.168.0.0/16"),
+		),
+		"mixed predicates": pktcls.NewCondAnyOf(
+			src("10.0.0.0/8"), tos, src("172.16.0.0/12"),
+			pktcls.NewCondPorts(&pktcls.PortMatchDestination{MinPort: 80, MaxPort: 80}),
+		),
+		"nested": pktcls.NewCondAllOf(
+			tos,
+			pktcls.NewCondNot(pktcls.NewCondAnyOf(src("10.0.0.0/8"), src("11.0.0.0/8"))),
+			pktcls.NewCondAnyOf(dst("192.168.0.0/24"), dst("192.168.2.0/24")),
+		),
+		"non canonical networks": pktcls.NewCondAnyOf(
+			src("10.0.0.0/8"),
+			src("2001:db8::/32"),
+			pktcls.NewCondIPv4(&pktcls.IPv4MatchSource{Net: &net.IPNet{
+				IP:   net.IP{172, 16, 0, 0},
+				Mask: net.IPMask{255, 0, 255, 0},
+			}}),
+		),
+		"empty": pktcls.NewCondAnyOf(),
+	}
+	for name, c := range testCases {
+		t.Run(name, func(t *testing.T) {
+			compiled := pktcls.Compile(c)
+			eq, pkt := pktcls.Equivalent(c, compiled, 0)
+			assert.True(t, eq, "counterexample %v", pkt)
+			assert.Equal(t, c.Eval(nil), compiled.Eval(nil))
+			assert.Equal(t, c.Eval(&layers.IPv6{}), compiled.Eval(&layers.IPv6{}))
+		})
+	}
+
+	t.Run("budget", func(t *testing.T) {
+		b := &pktcls.CondBudget{
+			Operand: pktcls.NewCondAnyOf(
+				src("10.0.0.0/8"), src("11.0.0.0/8"), src("12.0.0.0/8"),
+			),
+			Budget: 1,
+		}
+		pkt := &layers.IPv4{SrcIP: net.IP{12, 0, 0, 1}}
+		assert.False(t, b.Eval(pkt))
+		compiled := pktcls.Compile(b)
+		require.IsType(t, &pktcls.CondBudget{}, compiled)
+		assert.True(t, compiled.Eval(pkt))
+		assert.Len(t, b.Operand, 3, "original is not modified")
+	})
+}
+
+func BenchmarkCompile(b *testing.B) {
+	r := rand.New(rand.NewPCG(1, 2))
+	c := make(pktcls.CondAnyOf, 0, 1000)
+	for range 1000 {
+		ip := binary.BigEndian.AppendUint32(nil, r.Uint32())
+		n := &net.IPNet{IP: ip, Mask: net.CIDRMask(8+r.IntN(25), 32)}
+		n.IP = n.IP.Mask(n.Mask)
+		c = append(c, pktcls.NewCondIPv4(&pktcls.IPv4MatchSource{Net: n}))
+	}
+	pkts := make([]gopacket.Layer, 0, 64)
+	for range cap(pkts) {
+		pkts = append(pkts, &layers.IPv4{
+			SrcIP: binary.BigEndian.AppendUint32(nil, r.Uint32()),
+		})
+	}
+	for name, cond := range map[string]pktcls.Cond{
+		"linear":   c,
+		"compiled": pktcls.Compile(c),
+	} {
+		b.Run(fmt.Sprintf("%s/cidrs=%d", name, len(c)), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				cond.Eval(pkts[i%len(pkts)])
+			}
+		})
+	}
+}
@@ -226,9 +226,98 @@ func (c *CondIPv4) UnmarshalJSON(b []byte) error {
 	return err
 }
 
+var _ Cond = (*CondIPv6)(nil)
+
+// CondIPv6 conditions return true if the embedded IPv6 predicate returns true.
+type CondIPv6 struct {
+	Predicate IPv6Predicate
+}
+
+func NewCondIPv6(p IPv6Predicate) *CondIPv6 {
+	return &CondIPv6{Predicate: p}
+}
+
+func (c *CondIPv6) Eval(v gopacket.Layer) bool {
+	if c.Predicate == nil || v == nil {
+		return false
+	}
+	p, ok := v.(*layers.IPv6)
+	if !ok {
+		return false
+	}
+	return c.Predicate.Eval(p)
+}
+
+func (c *CondIPv6) Type() string {
+	return TypeCondIPv6
+}
+
+func (c *CondIPv6) String() string {
+	if c.Predicate == nil {
+		return "<nil>"
+	}
+	return c.Predicate.String()
+}
+
+func (c *CondIPv6) MarshalJSON() ([]byte, error) {
+	return marshalInterface(c.Predicate)
+}
+
+func (c *CondIPv6) UnmarshalJSON(b []byte) error {
+	var err error
+	c.Predicate, err = unmarshalIPv6Predicate(b)
+	return err
+}
+
+var _ Cond = (*CondSCION)(nil)
+
+// CondSCION conditions return true if the evaluated layer is a SCION packet
+// (see MatchIsSCION) and the embedded SCION predicate returns true.
+type CondSCION struct {
+	Predicate SCIONPredicate
+}
+
+func NewCondSCION(p SCIONPredicate) *CondSCION {
+	return &CondSCION{Predicate: p}
+}
+
+func (c *CondSCION) Eval(v gopacket.Layer) bool {
+	if c.Predicate == nil {
+		return false
+	}
+	s, ok := decodeSCION(v)
+	if !ok {
+		return false
+	}
+	return c.Predicate.Eval(s)
+}
+
+func (c *CondSCION) Type() string {
+	return TypeCondSCION
+}
+
+func (c *CondSCION) String() string {
+	if c.Predicate == nil {
+		return "<nil>"
+	}
+	return c.Predicate.String()
+}
+
+func (c *CondSCION) MarshalJSON() ([]byte, error) {
+	return marshalInterface(c.Predicate)
+}
+
+func (c *CondSCION) UnmarshalJSON(b []byte) error {
+	var err error
+	c.Predicate, err = unmarshalSCIONPredicate(b)
+	return err
+}
+
 var _ Cond = (*CondPorts)(nil)
 
-// CondPorts conditions return true if the embedded port predicate returns true.
+// CondPorts conditions return true if the embedded port predicate returns true
+// for the UDP or TCP ports of an IPv4 or IPv6 packet. Packets without a
+// decodable UDP or TCP header do not match.
 type CondPorts struct {
 	Predicate PortPredicate
 }
@@ -243,19 +332,23 @@ func (c *CondPorts) Eval(v gopacket.Layer) bool {
 	}
 	// Port predicates are independent on particular L3 or L4 protocol.
 	// Here we extract the ports and pass them to the embedded predicate.
-	l3 := v.LayerType()
-	if l3 != layers.LayerTypeIPv4 {
-		return false
-	}
-	ipv4, ok := v.(*layers.IPv4)
-	if !ok {
+	var next gopacket.LayerType
+	var payload []byte
+	switch ip := v.(type) {
+	case *layers.IPv4:
+		next, payload = ip.NextLayerType(), ip.LayerPayload()
+	case *layers.IPv6:
+		// IPv6 extension headers are not skipped, packets that carry them
+		// do not match.
+		next, payload = ip.NextLayerType(), ip.LayerPayload()
+	default:
 		return false
 	}
 
-	switch ipv4.NextLayerType() {
+	switch next {
 	case layers.LayerTypeUDP:
 		udp := &layers.UDP{}
-		err := udp.DecodeFromBytes(ipv4.LayerPayload(), gopacket.NilDecodeFeedback)
+		err := udp.DecodeFromBytes(payload, gopacket.NilDecodeFeedback)
 		if err != nil {
 			return false
 		}
@@ -265,7 +358,7 @@ func (c *CondPorts) Eval(v gopacket.Layer) bool {
 		})
 	case layers.LayerTypeTCP:
 		tcp := &layers.TCP{}
-		err := tcp.DecodeFromBytes(ipv4.LayerPayload(), gopacket.NilDecodeFeedback)
+		err := tcp.DecodeFromBytes(payload, gopacket.NilDecodeFeedback)
 		if err != nil {
 			return false
 		}
@@ -16,12 +16,14 @@
 package pktcls_test
 
 import (
+	"encoding/json"
 	"net"
 	"testing"
 
 	"github.com/gopacket/gopacket"
 	"github.com/gopacket/gopacket/layers"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/scionproto/scion/gateway/pktcls"
 )
@@ -118,6 +120,24 @@ func TestIPCond(t *testing.T) {
 			},
 			ExpEval: true,
 		},
+		{
+			Name: "Match IPv4 source outside of network",
+			Cond: pktcls.NewCondNot(
+				pktcls.NewCondIPv4(
+					&pktcls.IPv4MatchSource{
+						Net: &net.IPNet{
+							IP:   net.IP{10, 0, 0, 0},
+							Mask: net.IPv4Mask(255, 0, 0, 0),
+						},
+					},
+				),
+			),
+			Packet: &layers.IPv4{
+				SrcIP: net.IP{172, 17, 1, 1},
+				DstIP: net.IP{10, 0, 0, 2},
+			},
+			ExpEval: true,
+		},
 		{
 			Name: "Match IPv4 protocol",
 			Cond: pktcls.NewCondAllOf(
@@ -155,6 +175,107 @@ func TestIPCond(t *testing.T) {
 			},
 			ExpEval: false,
 		},
+		{
+			Name: "Match IPv4 ECN CE",
+			Cond: pktcls.NewCondIPv4(
+				&pktcls.IPv4MatchECN{ECN: 0x3},
+			),
+			Packet: &layers.IPv4{
+				TOS: 0xb8 | 0x3,
+			},
+			ExpEval: true,
+		},
+		{
+			Name: "Do not match IPv4 ECN ECT(0)",
+			Cond: pktcls.NewCondIPv4(
+				&pktcls.IPv4MatchECN{ECN: 0x2},
+			),
+			Packet: &layers.IPv4{
+				TOS: 0xb8 | 0x1,
+			},
+			ExpEval: false,
+		},
+		{
+			Name: "Match IPv4 TTL below threshold",
+			Cond: pktcls.NewCondIPv4(
+				&pktcls.IPv4MatchTTL{Op: pktcls.CmpLt, TTL: 5},
+			),
+			Packet: &layers.IPv4{
+				TTL: 4,
+			},
+			ExpEval: true,
+		},
+		{
+			Name: "Do not match IPv4 TTL at threshold",
+			Cond: pktcls.NewCondIPv4(
+				&pktcls.IPv4MatchTTL{Op: pktcls.CmpLt, TTL: 5},
+			),
+			Packet: &layers.IPv4{
+				TTL: 5,
+			},
+			ExpEval: false,
+		},
+		{
+			Name: "Match IPv4 TTL equal",
+			Cond: pktcls.NewCondIPv4(
+				&pktcls.IPv4MatchTTL{Op: pktcls.CmpEq, TTL: 64},
+			),
+			Packet: &layers.IPv4{
+				TTL: 64,
+			},
+			ExpEval: true,
+		},
+		{
+			Name: "Match IPv4 TTL above threshold",
+			Cond: pktcls.NewCondIPv4(
+				&pktcls.IPv4MatchTTL{Op: pktcls.CmpGt, TTL: 64},
+			),
+			Packet: &layers.IPv4{
+				TTL: 128,
+			},
+			ExpEval: true,
+		},
+		{
+			Name: "Match IPv4 any option",
+			Cond: pktcls.NewCondIPv4(
+				&pktcls.IPv4MatchHasOptions{Any: true},
+			),
+			Packet: &layers.IPv4{
+				Options: []layers.IPv4Option{{OptionType: 148, OptionLength: 4}},
+			},
+			ExpEval: true,
+		},
+		{
+			Name: "Do not match IPv4 any option without options",
+			Cond: pktcls.NewCondIPv4(
+				&pktcls.IPv4MatchHasOptions{Any: true},
+			),
+			Packet:  &layers.IPv4{},
+			ExpEval: false,
+		},
+		{
+			Name: "Match IPv4 loose source route option",
+			Cond: pktcls.NewCondIPv4(
+				&pktcls.IPv4MatchHasOptions{OptionType: 131},
+			),
+			Packet: &layers.IPv4{
+				Options: []layers.IPv4Option{
+					{OptionType: 1, OptionLength: 1},
+					{OptionType: 131, OptionLength: 7},
+				},
+			},
+			ExpEval: true,
+		},
+		{
+			Name: "Do not match IPv4 strict source route option",
+			Cond: pktcls.NewCondIPv4(
+				&pktcls.IPv4MatchHasOptions{OptionType: 137},
+			),
+			Packet: &layers.IPv4{
+				Options: []layers.IPv4Option{{OptionType: 131, OptionLength: 7}},
+			},
+			ExpEval: false,
+		},
 	}
 
 	for _, test := range testCases {
@@ -169,6 +290,8 @@ func TestPortCond(t *testing.T) {
 		Cond    pktcls.Cond
 		SrcPort uint16
 		DstPort uint16
+		// Packet, if set, is evaluated instead of a UDP packet with the ports.
+		Packet  gopacket.Layer
 		ExpEval bool
 	}{
 		"Match UDP src port": {
@@ -195,12 +318,60 @@ func TestPortCond(t *testing.T) {
 			DstPort: 200,
 			ExpEval: false,
 		},
+		"Match UDP dst port over IPv6": {
+			Cond: pktcls.NewCondPorts(
+				&pktcls.PortMatchDestination{
+					MinPort: 8000,
+					MaxPort: 8100,
+				},
+			),
+			Packet:  createUDPPacketIPv6(40000, 8080),
+			ExpEval: true,
+		},
+		"Do not match UDP src port over IPv6": {
+			Cond: pktcls.NewCondPorts(
+				&pktcls.PortMatchSource{
+					MinPort: 8000,
+					MaxPort: 8100,
+				},
+			),
+			Packet:  createUDPPacketIPv6(8101, 8080),
+			ExpEval: false,
+		},
+		"No transport layer": {
+			Cond: pktcls.NewCondPorts(
+				&pktcls.PortMatchSource{
+					MinPort: 0,
+					MaxPort: 65535,
+				},
+			),
+			Packet: &layers.IPv4{
+				Protocol: layers.IPProtocolICMPv4,
+			},
+			ExpEval: false,
+		},
+		"Truncated transport layer": {
+			Cond: pktcls.NewCondPorts(
+				&pktcls.PortMatchSource{
+					MinPort: 0,
+					MaxPort: 65535,
+				},
+			),
+			Packet: &layers.IPv4{
+				Protocol:  layers.IPProtocolUDP,
+				BaseLayer: layers.BaseLayer{Payload: []byte{0x1f, 0x40}},
+			},
+			ExpEval: false,
+		},
 	}
 
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
-			pkt := createUDPPacket(tc.SrcPort, tc.DstPort)
+			pkt := tc.Packet
+			if pkt == nil {
+				pkt = createUDPPacket(tc.SrcPort, tc.DstPort)
+			}
 			assert.Equal(t, tc.ExpEval, tc.Cond.Eval(pkt))
 		})
 	}
@@ -238,6 +409,98 @@ func createUDPPacket(src, dst uint16) gopacket.Layer {
 	return pkt
 }
 
+func createUDPPacketIPv6(src, dst uint16) gopacket.Layer {
+	ip := &layers.IPv6{
+		Version:    6,
+		HopLimit:   64,
+		SrcIP:      net.ParseIP("2001:db8:14::3"),
+		DstIP:      net.ParseIP("2001:db8:14::2"),
+		NextHeader: layers.IPProtocolUDP,
+	}
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(src),
+		DstPort: layers.UDPPort(dst),
+	}
+	_ = udp.SetNetworkLayerForChecksum(ip)
+	input := gopacket.NewSerializeBuffer()
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+	if err := gopacket.SerializeLayers(input, options,
+		ip, udp, gopacket.Payload([]byte("payload"))); err != nil {
+		panic(err)
+	}
+	pkt := &layers.IPv6{}
+	if err := pkt.DecodeFromBytes(input.Bytes(), gopacket.NilDecodeFeedback); err != nil {
+		panic(err)
+	}
+	return pkt
+}
+
+func TestIPv4MatchECNString(t *testing.T) {
+	assert.Equal(t, "ecn=Not-ECT", (&pktcls.IPv4MatchECN{ECN: 0x0}).String())
+	assert.Equal(t, "ecn=ECT(1)", (&pktcls.IPv4MatchECN{ECN: 0x1}).String())
+	assert.Equal(t, "ecn=ECT(0)", (&pktcls.IPv4MatchECN{ECN: 0x2}).String())
+	assert.Equal(t, "ecn=CE", (&pktcls.IPv4MatchECN{ECN: 0x3}).String())
+}
+
+func TestIPv4MatchProtocolNumeric(t *testing.T) {
+	for raw, want := range map[string]uint8{
+		`{"Protocol":"UDP"}`: 17,
+		`{"Protocol":"tcp"}`: 6,
+		`{"Protocol":"17"}`:  17,
+		`{"Protocol":"253"}`: 253,
+		`{"Protocol":"0"}`:   0,
+	} {
+		var m pktcls.IPv4MatchProtocol
+		require.NoError(t, json.Unmarshal([]byte(raw), &m), raw)
+		assert.Equal(t, want, m.Protocol, raw)
+	}
+	assert.Equal(t, "protocol=UDP", (&pktcls.IPv4MatchProtocol{Protocol: 17}).String())
+	assert.Equal(t, "protocol=253", (&pktcls.IPv4MatchProtocol{Protocol: 253}).String())
+
+	// The numeric form is parsed back.
+	pred := pktcls.NewCondIPv4(&pktcls.IPv4MatchProtocol{Protocol: 253})
+	parsed, err := pktcls.ParseCond(pred.String())
+	require.NoError(t, err)
+	assert.Equal(t, pred, parsed)
+	_, err = pktcls.ParseCond("protocol=256")
+	assert.Error(t, err)
+}
+
+func TestIPv4MatchTTLString(t *testing.T) {
+	assert.Equal(t, "ttl<5", (&pktcls.IPv4MatchTTL{Op: pktcls.CmpLt, TTL: 5}).String())
+	assert.Equal(t, "ttl>64", (&pktcls.IPv4MatchTTL{Op: pktcls.CmpGt, TTL: 64}).String())
+	assert.Equal(t, "ttl=1", (&pktcls.IPv4MatchTTL{Op: pktcls.CmpEq, TTL: 1}).String())
+}
+
+func TestIPv4MatchTTLUnmarshal(t *testing.T) {
+	for raw, want := range map[string]pktcls.IPv4MatchTTL{
+		`{"Op":"<","TTL":"5"}`:    {Op: pktcls.CmpLt, TTL: 5},
+		`{"Op":">","TTL":"0x40"}`: {Op: pktcls.CmpGt, TTL: 64},
+	} {
+		var m pktcls.IPv4MatchTTL
+		require.NoError(t, json.Unmarshal([]byte(raw), &m))
+		assert.Equal(t, want, m)
+	}
+}
+
+func TestIPv4MatchHasOptions(t *testing.T) {
+	assert.Equal(t, "options=any", (&pktcls.IPv4MatchHasOptions{Any: true}).String())
+	assert.Equal(t, "options=131", (&pktcls.IPv4MatchHasOptions{OptionType: 131}).String())
+	for raw, want := range map[string]pktcls.IPv4MatchHasOptions{
+		`{"Option":"any"}`:  {Any: true},
+		`{"Option":"ANY"}`:  {Any: true},
+		`{"Option":"131"}`:  {OptionType: 131},
+		`{"Option":"0x89"}`: {OptionType: 137},
+	} {
+		var m pktcls.IPv4MatchHasOptions
+		require.NoError(t, json.Unmarshal([]byte(raw), &m), raw)
+		assert.Equal(t, want, m, raw)
+	}
+}
+
 func TestStringer(t *testing.T) {
 	_, net, _ := net.ParseCIDR("12.12.12.0/26")
 	tests := map[string]struct {
@@ -26,15 +26,51 @@
 // conditions always return their internal value. IPv4 conditions include
 // predicates that compare the analyzed packet to preset values. Supported IPv4
 // conditions currently include destination network match, source network match
-// and ToS/DSCP fields match. Multiple predicates can be checked by enumerating
-// them under AllOf or AnyOf.
+// and ToS/DSCP/ECN fields match. For lab setups, the source and destination
+// address can also be matched against a hostname pattern using a cached reverse
+// DNS lookup; these predicates do not match if the lookup fails. The UDP or TCP
+// payload can be matched against a byte pattern at a fixed offset. The total
+// packet length can be compared to a value or a range, and the TTL to a
+// value. Packets that carry IPv4 options, or an option of a specific type, can
+// be matched, e.g., to drop source routed traffic, and so can packets with a
+// valid or an invalid header checksum. IPv6 conditions match the source or
+// destination network, the traffic class, or the flow label of IPv6 packets; a
+// zero flow label matches any flow label. Port conditions match the UDP or TCP
+// source or destination port of IPv4 and IPv6 packets against an inclusive
+// range. Multiple predicates can be checked by enumerating them under AllOf or
+// AnyOf. To match all packets except those of a network, negate the network
+// predicate with Not, e.g., "not(src=10.0.0.0/8)" or "!src=10.0.0.0/8". There
+// is no negated form of the network predicates themselves, such that each
+// condition has a single encoding and Compile only has to handle plain
+// network predicates.
+// MatchIsSCION returns true for SCION packets and can be used to separate SCION
+// traffic from legacy IP traffic. SCION conditions include predicates that
+// compare fields of the SCION header of the analyzed packet, such as the path
+// type, the traffic class and its DSCP subset, or the source and destination
+// ISD-AS, to preset values. The ISD-AS predicates treat a zero ISD or AS as a
+// wildcard. CondBudget limits the number of conditions that are evaluated per
+// packet and can be used to protect the data path from pathological condition
+// trees. Compile speeds up the evaluation of conditions with many IPv4 network
+// predicates by looking up the addresses in a prefix trie. InstrumentCond
+// counts the evaluations of the IPv4 predicates of a condition by predicate
+// type and result, to show which predicates match in production.
 //
 // The package contains support for JSON marshaling and unmarshaling of
 // classes. Due to the custom formatting of the JSON output, marshaling must be
 // done by first adding the classes to a ClassMap. Unmarshaling back to the Map
 // is guaranteed to yield an object that is identical to the initial one.
+// Classes that are split across multiple JSON files in a directory can be
+// loaded with LoadClassifiers. Validate checks a JSON document against the
+// known types and fields and reports all problems at once, which gives better
+// feedback on hand-written or generated documents than unmarshaling. IPv4
+// predicates of other packages can be registered with RegisterPredicate, after
+// which they are unmarshaled like the predicates of this package.
 //
 // All conditions also implement fmt.Stringer, the `String` method produces a
-// human readable representation. The human readable representation can also be
-// parsed with `BuildClassTree` and can be validated by `ValidateTrafficClass`.
+// human readable representation. The human readable representation of the
+// conditions that can be encoded in JSON can be parsed back with `ParseCond`,
+// which also accepts a compact infix notation, e.g.,
+// "src=10.0.0.0/8 && dscp=0x2e || protocol=udp". `BuildClassTree` and
+// `ValidateTrafficClass` only support the traffic class grammar, which covers
+// the network, ToS, DSCP, protocol name and port predicates.
 package pktcls
@@ -0,0 +1,350 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls
+
+import (
+	"cmp"
+	"encoding/binary"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"net"
+	"slices"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+)
+
+// Equivalent reports whether the conditions a and b evaluate to the same
+// result for all IPv4 packets. The checked packets are built from the values
+// the conditions inspect: the boundaries of the matched networks, port ranges
+// and lengths and the matched ToS, DSCP, protocol, TTL and option type values,
+// together with their neighbors. If options are matched, packets without
+// options and with an option of each relevant type are generated. If the
+// checksum is matched, packets with a valid and with a broken header checksum
+// are generated. Otherwise, the checksum is valid. Every combination of these
+// values is checked. If there are more than samples combinations, only samples
+// pseudo-random combinations are checked, and a positive result is not a proof
+// of equivalence. A non-positive samples value checks all combinations.
+//
+// If the conditions differ, the first packet for which they evaluate
+// differently is returned.
+//
+// Only the IPv4 header fields and the UDP and TCP ports are enumerated. The
+// following conditions and predicates are evaluated on the generated packets,
+// but no packets are generated specifically for them: the SCION conditions
+// (CondSCION and MatchIsSCION), the payload predicate (IPv4MatchPayload) and
+// the host name predicates (IPv4MatchSourceHost and
+// IPv4MatchDestinationHost).
+func Equivalent(a, b Cond, samples int) (bool, *layers.IPv4) {
+	var v fieldValues
+	v.collect(a)
+	v.collect(b)
+	dims := v.dimensions()
+
+	total := 1
+	for _, d := range dims {
+		if total > math.MaxInt/len(d) {
+			total = math.MaxInt
+			break
+		}
+		total *= len(d)
+	}
+	check := func(idx []int) *layers.IPv4 {
+		pkt := buildPacket(dims, idx)
+		if a.Eval(pkt) != b.Eval(pkt) {
+			return pkt
+		}
+		return nil
+	}
+	idx := make([]int, len(dims))
+	if samples <= 0 || total <= samples {
+		for i := 0; i < total; i++ {
+			n := i
+			for d := range dims {
+				idx[d] = n % len(dims[d])
+				n /= len(dims[d])
+			}
+			if pkt := check(idx); pkt != nil {
+				return false, pkt
+			}
+		}
+		return true, nil
+	}
+	// A fixed seed keeps the result reproducible.
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < samples; i++ {
+		for d := range dims {
+			idx[d] = r.IntN(len(dims[d]))
+		}
+		if pkt := check(idx); pkt != nil {
+			return false, pkt
+		}
+	}
+	return true, nil
+}
+
+// fieldValues contains the values of each packet field that are relevant for
+// the evaluation of a condition.
+type fieldValues struct {
+	src, dst         valueSet[uint32]
+	tos, proto, ttl  valueSet[uint8]
+	srcPort, dstPort valueSet[uint16]
+	length           valueSet[uint16]
+	// options contains the option type plus one of the single option of the
+	// packet, or zero for a packet without options.
+	options valueSet[uint16]
+	// checksum contains one for packets with a broken header checksum, and
+	// zero for packets with a valid one.
+	checksum valueSet[uint8]
+}
+
+func (v *fieldValues) collect(c Cond) {
+	switch c := c.(type) {
+	case CondAnyOf:
+		for _, child := range c {
+			v.collect(child)
+		}
+	case CondAllOf:
+		for _, child := range c {
+			v.collect(child)
+		}
+	case CondNot:
+		v.collect(c.Operand)
+	case *CondIPv4:
+		v.collectIPv4(c.Predicate)
+	case *CondPorts:
+		v.collectPorts(c.Predicate)
+	}
+}
+
+func (v *fieldValues) collectIPv4(p IPv4Predicate) {
+	switch p := p.(type) {
+	case *IPv4MatchSource:
+		addNet(&v.src, p.Net)
+	case *IPv4MatchDestination:
+		addNet(&v.dst, p.Net)
+	case *IPv4MatchToS:
+		v.tos.add(p.TOS, p.TOS+1)
+	case *IPv4MatchDSCP:
+		// Vary the ECN bits, too, since ToS predicates inspect them.
+		v.tos.add(p.DSCP<<2, p.DSCP<<2|1, (p.DSCP+1)<<2)
+	case *IPv4MatchECN:
+		v.tos.add(p.ECN&0x3, p.ECN&0x3^1)
+	case *IPv4MatchProtocol:
+		v.proto.add(p.Protocol, p.Protocol+1)
+	case *IPv4MatchTTL:
+		addNeighbors(&v.ttl, p.TTL)
+	case *IPv4MatchHasOptions:
+		// The NOP and EOL options are options of another type than the
+		// matched one, at least one of them.
+		v.options.add(0, uint16(ipv4OptionEOL)+1, uint16(ipv4OptionNOP)+1)
+		if !p.Any {
+			v.options.add(uint16(p.OptionType) + 1)
+		}
+	case *IPv4MatchChecksumValid:
+		v.checksum.add(0, 1)
+	case *IPv4MatchLength:
+		if p.Op == CmpRange {
+			addRange(&v.length, p.Length, p.MaxLength)
+		} else {
+			addRange(&v.length, p.Length, p.Length)
+		}
+	case *InstrumentedPredicate:
+		v.collectIPv4(p.Predicate)
+	}
+}
+
+func (v *fieldValues) collectPorts(p PortPredicate) {
+	// Ports are only inspected for UDP and TCP packets.
+	v.proto.add(uint8(layers.IPProtocolUDP), uint8(layers.IPProtocolTCP))
+	switch p := p.(type) {
+	case *PortMatchSource:
+		addRange(&v.srcPort, p.MinPort, p.MaxPort)
+	case *PortMatchDestination:
+		addRange(&v.dstPort, p.MinPort, p.MaxPort)
+	}
+}
+
+// dimensions returns the sorted values for each field. Fields without
+// relevant values contain only the zero value, except for the TTL, which
+// defaults to 64. A zero length leaves the packet without payload.
+func (v *fieldValues) dimensions() [][]uint32 {
+	ttl := sortedValues(v.ttl)
+	if len(v.ttl) == 0 {
+		ttl = []uint32{64}
+	}
+	return [][]uint32{
+		sortedValues(v.src),
+		sortedValues(v.dst),
+		sortedValues(v.tos),
+		sortedValues(v.proto),
+		sortedValues(v.srcPort),
+		sortedValues(v.dstPort),
+		ttl,
+		sortedValues(v.length),
+		sortedValues(v.options),
+		sortedValues(v.checksum),
+	}
+}
+
+// buildPacket builds the IPv4 packet for the given index into each dimension.
+// The payload is padded to reach the total length, unless the length is
+// smaller than the headers. The header checksum is broken if requested.
+func buildPacket(dims [][]uint32, idx []int) *layers.IPv4 {
+	val := func(d int) uint32 { return dims[d][idx[d]] }
+
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      uint8(val(6)),
+		TOS:      uint8(val(2)),
+		Protocol: layers.IPProtocol(val(3)),
+		SrcIP:    binary.BigEndian.AppendUint32(nil, val(0)),
+		DstIP:    binary.BigEndian.AppendUint32(nil, val(1)),
+	}
+	l := []gopacket.SerializableLayer{ip}
+	size := 20
+	if opt := val(8); opt > 0 {
+		ip.Options = ipv4Options(uint8(opt - 1))
+		size += 4
+	}
+	switch ip.Protocol {
+	case layers.IPProtocolUDP:
+		udp := &layers.UDP{
+			SrcPort: layers.UDPPort(val(4)),
+			DstPort: layers.UDPPort(val(5)),
+		}
+		if err := udp.SetNetworkLayerForChecksum(ip); err != nil {
+			panic(err)
+		}
+		l = append(l, udp)
+		size += 8
+	case layers.IPProtocolTCP:
+		tcp := &layers.TCP{
+			SrcPort: layers.TCPPort(val(4)),
+			DstPort: layers.TCPPort(val(5)),
+		}
+		if err := tcp.SetNetworkLayerForChecksum(ip); err != nil {
+			panic(err)
+		}
+		l = append(l, tcp)
+		size += 20
+	}
+	if length := int(val(7)); length > size {
+		l = append(l, gopacket.Payload(make([]byte, length-size)))
+	}
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if err := gopacket.SerializeLayers(buf, opts, l...); err != nil {
+		panic(err)
+	}
+	raw := buf.Bytes()
+	if val(9) != 0 {
+		raw[10] ^= 0xff
+	}
+	pkt := &layers.IPv4{}
+	if err := pkt.DecodeFromBytes(raw, gopacket.NilDecodeFeedback); err != nil {
+		panic(err)
+	}
+	return pkt
+}
+
+// ipv4OptionEOL and ipv4OptionNOP are the IPv4 option types that consist of a
+// single byte.
+const (
+	ipv4OptionEOL = 0
+	ipv4OptionNOP = 1
+)
+
+// ipv4Options returns 4 bytes of options that only contain options of the
+// type t. The options of other types carry two bytes of data.
+func ipv4Options(t uint8) []layers.IPv4Option {
+	switch t {
+	case ipv4OptionEOL:
+		// The padding is decoded as part of the end of the options.
+		return []layers.IPv4Option{{OptionType: t, OptionLength: 1}}
+	case ipv4OptionNOP:
+		opt := layers.IPv4Option{OptionType: t, OptionLength: 1}
+		return []layers.IPv4Option{opt, opt, opt, opt}
+	default:
+		return []layers.IPv4Option{{OptionType: t, OptionLength: 4, OptionData: []byte{0, 0}}}
+	}
+}
+
+type valueSet[T cmp.Ordered] map[T]struct{}
+
+func (s *valueSet[T]) add(values ...T) {
+	if *s == nil {
+		*s = make(valueSet[T])
+	}
+	for _, v := range values {
+		(*s)[v] = struct{}{}
+	}
+}
+
+// addNet adds the first and the last address of the network and the
+// addresses right outside of it.
+func addNet(s *valueSet[uint32], n *net.IPNet) {
+	if n == nil {
+		return
+	}
+	ip, mask := n.IP.To4(), n.Mask
+	if ip == nil || len(mask) != net.IPv4len {
+		return
+	}
+	first := binary.BigEndian.Uint32(ip) & binary.BigEndian.Uint32(mask)
+	last := first | ^binary.BigEndian.Uint32(mask)
+	s.add(first, last)
+	if first > 0 {
+		s.add(first - 1)
+	}
+	if last < math.MaxUint32 {
+		s.add(last + 1)
+	}
+}
+
+// addNeighbors adds the value and the values right next to it.
+func addNeighbors(s *valueSet[uint8], value uint8) {
+	s.add(value)
+	if value > 0 {
+		s.add(value - 1)
+	}
+	if value < math.MaxUint8 {
+		s.add(value + 1)
+	}
+}
+
+// addRange adds the bounds of the range and the values right outside of it.
+func addRange(s *valueSet[uint16], low, high uint16) {
+	s.add(low, high)
+	if low > 0 {
+		s.add(low - 1)
+	}
+	if high < math.MaxUint16 {
+		s.add(high + 1)
+	}
+}
+
+func sortedValues[T uint8 | uint16 | uint32](s valueSet[T]) []uint32 {
+	if len(s) == 0 {
+		return []uint32{0}
+	}
+	var r []uint32
+	for _, v := range slices.Sorted(maps.Keys(s)) {
+		r = append(r, uint32(v))
+	}
+	return r
+}
@@ -0,0 +1,185 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/gateway/pktcls"
+)
+
+func TestEquivalent(t *testing.T) {
+	src := func(s string) pktcls.Cond {
+		_, n, err := net.ParseCIDR(s)
+		require.NoError(t, err)
+		return pktcls.NewCondIPv4(&pktcls.IPv4MatchSource{Net: n})
+	}
+	tos := func(v uint8) pktcls.Cond {
+		return pktcls.NewCondIPv4(&pktcls.IPv4MatchToS{TOS: v})
+	}
+	dscp := func(v uint8) pktcls.Cond {
+		return pktcls.NewCondIPv4(&pktcls.IPv4MatchDSCP{DSCP: v})
+	}
+	ttl := func(op pktcls.CmpOp, v uint8) pktcls.Cond {
+		return pktcls.NewCondIPv4(&pktcls.IPv4MatchTTL{Op: op, TTL: v})
+	}
+	length := func(op pktcls.CmpOp, v, high uint16) pktcls.Cond {
+		return pktcls.NewCondIPv4(&pktcls.IPv4MatchLength{Op: op, Length: v, MaxLength: high})
+	}
+	option := func(t uint8) pktcls.Cond {
+		return pktcls.NewCondIPv4(&pktcls.IPv4MatchHasOptions{OptionType: t})
+	}
+	anyOption := pktcls.NewCondIPv4(&pktcls.IPv4MatchHasOptions{Any: true})
+	dstPort := func(low, high uint16) pktcls.Cond {
+		return pktcls.NewCondPorts(&pktcls.PortMatchDestination{MinPort: low, MaxPort: high})
+	}
+
+	testCases := map[string]struct {
+		A, B       pktcls.Cond
+		Samples    int
+		Equivalent bool
+	}{
+		"identical": {
+			A:          src("10.0.0.0/8"),
+			B:          src("10.0.0.0/8"),
+			Equivalent: true,
+		},
+		"de morgan": {
+			A: pktcls.NewCondNot(pktcls.NewCondAnyOf(src("10.0.0.0/8"), tos(0x80))),
+			B: pktcls.NewCondAllOf(
+				pktcls.NewCondNot(src("10.0.0.0/8")),
+				pktcls.NewCondNot(tos(0x80)),
+			),
+			Equivalent: true,
+		},
+		"split network": {
+			A:          src("192.168.1.0/24"),
+			B:          pktcls.NewCondAnyOf(src("192.168.1.0/25"), src("192.168.1.128/25")),
+			Equivalent: true,
+		},
+		"smaller network": {
+			A:          src("192.168.1.0/24"),
+			B:          src("192.168.1.0/25"),
+			Equivalent: false,
+		},
+		"split port range": {
+			A:          dstPort(1000, 2000),
+			B:          pktcls.NewCondAnyOf(dstPort(1000, 1499), dstPort(1500, 2000)),
+			Equivalent: true,
+		},
+		"port range gap": {
+			A:          dstPort(1000, 2000),
+			B:          pktcls.NewCondAnyOf(dstPort(1000, 1499), dstPort(1501, 2000)),
+			Equivalent: false,
+		},
+		"dscp is not tos": {
+			A:          dscp(0x20),
+			B:          tos(0x80),
+			Equivalent: false,
+		},
+		"dscp and ecn are tos": {
+			A: tos(0xbb),
+			B: pktcls.NewCondAllOf(dscp(0x2e),
+				pktcls.NewCondIPv4(&pktcls.IPv4MatchECN{ECN: 0x3})),
+			Equivalent: true,
+		},
+		"different ttl": {
+			A:          ttl(pktcls.CmpEq, 5),
+			B:          ttl(pktcls.CmpEq, 6),
+			Equivalent: false,
+		},
+		"ttl bounds": {
+			A:          ttl(pktcls.CmpLt, 6),
+			B:          pktcls.NewCondNot(ttl(pktcls.CmpGt, 5)),
+			Equivalent: true,
+		},
+		"ttl off by one": {
+			A:          ttl(pktcls.CmpLt, 6),
+			B:          pktcls.NewCondNot(ttl(pktcls.CmpGt, 6)),
+			Equivalent: false,
+		},
+		"different length": {
+			A:          length(pktcls.CmpEq, 100, 0),
+			B:          length(pktcls.CmpEq, 200, 0),
+			Equivalent: false,
+		},
+		"split length range": {
+			A: length(pktcls.CmpRange, 100, 1500),
+			B: pktcls.NewCondAnyOf(length(pktcls.CmpRange, 100, 999),
+				length(pktcls.CmpRange, 1000, 1500)),
+			Equivalent: true,
+		},
+		"length range upper bound": {
+			A: length(pktcls.CmpRange, 100, 1500),
+			B: pktcls.NewCondAllOf(length(pktcls.CmpGt, 99, 0),
+				length(pktcls.CmpLt, 1500, 0)),
+			Equivalent: false,
+		},
+		"options": {
+			A:          anyOption,
+			B:          pktcls.CondFalse,
+			Equivalent: false,
+		},
+		"option types": {
+			A:          option(131),
+			B:          option(137),
+			Equivalent: false,
+		},
+		"option type implies options": {
+			A:          pktcls.NewCondAllOf(anyOption, option(148)),
+			B:          option(148),
+			Equivalent: true,
+		},
+		"checksum": {
+			A:          pktcls.NewCondIPv4(&pktcls.IPv4MatchChecksumValid{Valid: true}),
+			B:          pktcls.CondTrue,
+			Equivalent: false,
+		},
+		"checksum negation": {
+			A: pktcls.NewCondIPv4(&pktcls.IPv4MatchChecksumValid{Valid: true}),
+			B: pktcls.NewCondNot(
+				pktcls.NewCondIPv4(&pktcls.IPv4MatchChecksumValid{Valid: false})),
+			Equivalent: true,
+		},
+		"always true": {
+			A:          pktcls.NewCondAnyOf(src("0.0.0.0/1"), src("128.0.0.0/1")),
+			B:          pktcls.CondTrue,
+			Equivalent: true,
+		},
+		"sampled": {
+			A: pktcls.NewCondAllOf(src("10.0.0.0/8"), tos(0x80), dstPort(1000, 2000)),
+			B: pktcls.NewCondAllOf(src("10.0.0.0/8"), tos(0x80),
+				pktcls.NewCondAnyOf(dstPort(1000, 1499), dstPort(1500, 2000))),
+			Samples:    10,
+			Equivalent: true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			eq, pkt := pktcls.Equivalent(tc.A, tc.B, tc.Samples)
+			assert.Equal(t, tc.Equivalent, eq)
+			if tc.Equivalent {
+				assert.Nil(t, pkt)
+				return
+			}
+			require.NotNil(t, pkt)
+			assert.NotEqual(t, tc.A.Eval(pkt), tc.B.Eval(pkt), "counterexample %v", pkt)
+		})
+	}
+}
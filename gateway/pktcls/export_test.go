@@ -0,0 +1,74 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	HostCacheSize  = hostCacheSize
+	HostMaxLookups = hostMaxLookups
+)
+
+// SetHostLookup replaces the resolver of a host predicate with one that uses
+// lookup.
+func SetHostLookup(
+	p IPv4Predicate,
+	lookup func(ctx context.Context, addr string) ([]string, error),
+	ttl time.Duration,
+	timeout time.Duration,
+) {
+	r := newHostResolver(lookup, ttl, timeout)
+	switch m := p.(type) {
+	case *IPv4MatchSourceHost:
+		m.resolver = r
+	case *IPv4MatchDestinationHost:
+		m.resolver = r
+	default:
+		panic("not a host predicate")
+	}
+}
+
+// HostCached returns whether the resolver of a host predicate has a cache entry
+// for addr.
+func HostCached(p IPv4Predicate, addr string) bool {
+	r := hostResolverOf(p)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.entries[addr]
+	return ok
+}
+
+// HostCacheLen returns the number of cache entries of the resolver of a host
+// predicate.
+func HostCacheLen(p IPv4Predicate) int {
+	r := hostResolverOf(p)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+func hostResolverOf(p IPv4Predicate) *hostResolver {
+	switch m := p.(type) {
+	case *IPv4MatchSourceHost:
+		return m.resolver
+	case *IPv4MatchDestinationHost:
+		return m.resolver
+	default:
+		panic("not a host predicate")
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls
+
+import (
+	"encoding/json"
+
+	"github.com/gopacket/gopacket/layers"
+
+	"github.com/scionproto/scion/pkg/metrics"
+)
+
+// Label values of the result label of instrumented predicates.
+const (
+	ResultMatch   = "match"
+	ResultNoMatch = "no_match"
+)
+
+var _ IPv4Predicate = (*InstrumentedPredicate)(nil)
+
+// InstrumentedPredicate counts the evaluations of the embedded predicate. It
+// behaves like the embedded predicate otherwise, and it is encoded like the
+// embedded predicate in JSON. Create it with NewInstrumentedPredicate.
+type InstrumentedPredicate struct {
+	Predicate       IPv4Predicate
+	match, mismatch metrics.Counter
+}
+
+// NewInstrumentedPredicate returns the predicate p, instrumented with the
+// counter evals. The counter is incremented on every evaluation, with the
+// label "type" set to the type of p and the label "result" set to
+// ResultMatch or ResultNoMatch. If evals is nil, p is returned as is, so that
+// uninstrumented predicates do not pay for the instrumentation.
+func NewInstrumentedPredicate(p IPv4Predicate, evals metrics.Counter) IPv4Predicate {
+	if evals == nil || p == nil {
+		return p
+	}
+	return &InstrumentedPredicate{
+		Predicate: p,
+		// The label values are resolved once, not on every evaluation.
+		match:    evals.With("type", p.Type(), "result", ResultMatch),
+		mismatch: evals.With("type", p.Type(), "result", ResultNoMatch),
+	}
+}
+
+func (p *InstrumentedPredicate) Eval(v *layers.IPv4) bool {
+	if p.Predicate.Eval(v) {
+		metrics.CounterInc(p.match)
+		return true
+	}
+	metrics.CounterInc(p.mismatch)
+	return false
+}
+
+func (p *InstrumentedPredicate) Type() string {
+	return p.Predicate.Type()
+}
+
+func (p *InstrumentedPredicate) String() string {
+	return p.Predicate.String()
+}
+
+func (p *InstrumentedPredicate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.Predicate)
+}
+
+// InstrumentCond returns a copy of the condition in which all IPv4 predicates
+// are instrumented with the counter evals (see NewInstrumentedPredicate). If
+// evals is nil, c is returned as is.
+//
+// Like CondBudget, the instrumentation is meant to be applied to conditions
+// after they have been loaded. Compile does not merge instrumented network
+// predicates, so instrumenting a compiled condition only instruments the
+// predicates that were not merged.
+func InstrumentCond(c Cond, evals metrics.Counter) Cond {
+	if evals == nil {
+		return c
+	}
+	switch c := c.(type) {
+	case CondAnyOf:
+		r := make(CondAnyOf, 0, len(c))
+		for _, child := range c {
+			r = append(r, InstrumentCond(child, evals))
+		}
+		return r
+	case CondAllOf:
+		r := make(CondAllOf, 0, len(c))
+		for _, child := range c {
+			r = append(r, InstrumentCond(child, evals))
+		}
+		return r
+	case CondNot:
+		return NewCondNot(InstrumentCond(c.Operand, evals))
+	case *CondBudget:
+		r := *c
+		r.Operand = InstrumentCond(c.Operand, evals)
+		return &r
+	case *CondIPv4:
+		return NewCondIPv4(NewInstrumentedPredicate(c.Predicate, evals))
+	default:
+		return c
+	}
+}
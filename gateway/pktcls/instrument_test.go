@@ -0,0 +1,92 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls_test
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/gopacket/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/gateway/pktcls"
+	"github.com/scionproto/scion/pkg/metrics"
+)
+
+func TestInstrumentedPredicate(t *testing.T) {
+	_, network, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+	src := &pktcls.IPv4MatchSource{Net: network}
+
+	t.Run("nil counter", func(t *testing.T) {
+		assert.Same(t, src, pktcls.NewInstrumentedPredicate(src, nil))
+	})
+	t.Run("counts results", func(t *testing.T) {
+		evals := metrics.NewTestCounter()
+		p := pktcls.NewInstrumentedPredicate(src, evals)
+		assert.True(t, p.Eval(&layers.IPv4{SrcIP: net.IP{10, 0, 0, 1}}))
+		assert.True(t, p.Eval(&layers.IPv4{SrcIP: net.IP{10, 0, 0, 2}}))
+		assert.False(t, p.Eval(&layers.IPv4{SrcIP: net.IP{192, 168, 0, 1}}))
+		assert.Equal(t, float64(2), metrics.CounterValue(
+			evals.With("type", "MatchSource", "result", pktcls.ResultMatch)))
+		assert.Equal(t, float64(1), metrics.CounterValue(
+			evals.With("type", "MatchSource", "result", pktcls.ResultNoMatch)))
+	})
+	t.Run("transparent", func(t *testing.T) {
+		p := pktcls.NewInstrumentedPredicate(src, metrics.NewTestCounter())
+		assert.Equal(t, src.Type(), p.Type())
+		assert.Equal(t, src.String(), p.String())
+		classes := pktcls.ClassMap{"c": pktcls.NewClass("c", pktcls.NewCondIPv4(p))}
+		raw, err := json.Marshal(classes)
+		require.NoError(t, err)
+		var parsed pktcls.ClassMap
+		require.NoError(t, json.Unmarshal(raw, &parsed))
+		assert.Equal(t, pktcls.NewCondIPv4(src), parsed["c"].Cond)
+	})
+}
+
+func TestInstrumentCond(t *testing.T) {
+	cond := pktcls.NewCondAnyOf(
+		pktcls.NewCondNot(pktcls.NewCondIPv4(&pktcls.IPv4MatchToS{TOS: 0x80})),
+		pktcls.NewCondAllOf(
+			pktcls.NewCondIPv4(&pktcls.IPv4MatchDSCP{DSCP: 0x2e}),
+			pktcls.NewCondPorts(&pktcls.PortMatchDestination{MinPort: 80, MaxPort: 80}),
+		),
+	)
+	t.Run("nil counter", func(t *testing.T) {
+		assert.Equal(t, cond, pktcls.InstrumentCond(cond, nil))
+	})
+	t.Run("counts per predicate", func(t *testing.T) {
+		evals := metrics.NewTestCounter()
+		instrumented := pktcls.InstrumentCond(cond, evals)
+		pkt := &layers.IPv4{TOS: 0x80}
+		assert.Equal(t, cond.Eval(pkt), instrumented.Eval(pkt))
+		assert.Equal(t, float64(1), metrics.CounterValue(
+			evals.With("type", "MatchToS", "result", pktcls.ResultMatch)))
+		assert.Equal(t, float64(1), metrics.CounterValue(
+			evals.With("type", "MatchDSCP", "result", pktcls.ResultNoMatch)))
+		// The original condition is not modified.
+		cond.Eval(pkt)
+		assert.Equal(t, float64(1), metrics.CounterValue(
+			evals.With("type", "MatchToS", "result", pktcls.ResultMatch)))
+	})
+	t.Run("equivalent", func(t *testing.T) {
+		eq, _ := pktcls.Equivalent(cond, pktcls.InstrumentCond(cond,
+			metrics.NewTestCounter()), 0)
+		assert.True(t, eq)
+	})
+}
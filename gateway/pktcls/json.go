@@ -41,19 +41,39 @@ type Typer interface {
 // concrete type is unmarshaled.
 
 const (
-	TypeCondAllOf            = "CondAllOf"
-	TypeCondAnyOf            = "CondAnyOf"
-	TypeCondNot              = "CondNot"
-	TypeCondBool             = "CondBool"
-	TypeCondIPv4             = "CondIPv4"
-	TypeIPv4MatchSource      = "MatchSource"
-	TypeIPv4MatchDestination = "MatchDestination"
-	TypeIPv4MatchToS         = "MatchToS"
-	TypeIPv4MatchDSCP        = "MatchDSCP"
-	TypeIPv4MatchProtocol    = "MatchProtocol"
-	TypeCondPorts            = "CondPorts"
-	TypePortMatchSource      = "MatchSourcePort"
-	TypePortMatchDestination = "MatchDestinationPort"
+	TypeCondAllOf                = "CondAllOf"
+	TypeCondAnyOf                = "CondAnyOf"
+	TypeCondNot                  = "CondNot"
+	TypeCondBool                 = "CondBool"
+	TypeCondIPv4                 = "CondIPv4"
+	TypeIPv4MatchSource          = "MatchSource"
+	TypeIPv4MatchDestination     = "MatchDestination"
+	TypeIPv4MatchToS             = "MatchToS"
+	TypeIPv4MatchDSCP            = "MatchDSCP"
+	TypeIPv4MatchECN             = "MatchECN"
+	TypeIPv4MatchProtocol        = "MatchProtocol"
+	TypeIPv4MatchSourceHost      = "MatchSourceHost"
+	TypeIPv4MatchDestinationHost = "MatchDestinationHost"
+	TypeIPv4MatchPayload         = "MatchPayload"
+	TypeIPv4MatchLength          = "MatchLength"
+	TypeIPv4MatchTTL             = "MatchTTL"
+	TypeIPv4MatchHasOptions      = "MatchHasOptions"
+	TypeIPv4MatchChecksumValid   = "MatchChecksumValid"
+	TypeCondIPv6                 = "CondIPv6"
+	TypeIPv6MatchSource          = "MatchIPv6Source"
+	TypeIPv6MatchDestination     = "MatchIPv6Destination"
+	TypeIPv6MatchTrafficClass    = "MatchIPv6TrafficClass"
+	TypeIPv6MatchFlowLabel       = "MatchIPv6FlowLabel"
+	TypeCondPorts                = "CondPorts"
+	TypePortMatchSource          = "MatchSourcePort"
+	TypePortMatchDestination     = "MatchDestinationPort"
+	TypeMatchIsSCION             = "MatchIsSCION"
+	TypeCondSCION                = "CondSCION"
+	TypeSCIONMatchPathType       = "MatchSCIONPathType"
+	TypeSCIONMatchTrafficClass   = "MatchSCIONTrafficClass"
+	TypeSCIONMatchDSCP           = "MatchSCIONDSCP"
+	TypeSCIONMatchSrcIA          = "MatchSCIONSrcIA"
+	TypeSCIONMatchDstIA          = "MatchSCIONDstIA"
 )
 
 // generic container for marshaling custom data
@@ -116,10 +136,62 @@ func unmarshalInterface(b []byte) (Typer, error) {
 			var p IPv4MatchDSCP
 			err := json.Unmarshal(*v, &p)
 			return &p, err
+		case TypeIPv4MatchECN:
+			var p IPv4MatchECN
+			err := json.Unmarshal(*v, &p)
+			return &p, err
 		case TypeIPv4MatchProtocol:
 			var p IPv4MatchProtocol
 			err := json.Unmarshal(*v, &p)
 			return &p, err
+		case TypeIPv4MatchSourceHost:
+			var p IPv4MatchSourceHost
+			err := json.Unmarshal(*v, &p)
+			return &p, err
+		case TypeIPv4MatchDestinationHost:
+			var p IPv4MatchDestinationHost
+			err := json.Unmarshal(*v, &p)
+			return &p, err
+		case TypeIPv4MatchPayload:
+			var p IPv4MatchPayload
+			err := json.Unmarshal(*v, &p)
+			return &p, err
+		case TypeIPv4MatchLength:
+			var p IPv4MatchLength
+			err := json.Unmarshal(*v, &p)
+			return &p, err
+		case TypeIPv4MatchTTL:
+			var p IPv4MatchTTL
+			err := json.Unmarshal(*v, &p)
+			return &p, err
+		case TypeIPv4MatchHasOptions:
+			var p IPv4MatchHasOptions
+			err := json.Unmarshal(*v, &p)
+			return &p, err
+		case TypeIPv4MatchChecksumValid:
+			var p IPv4MatchChecksumValid
+			err := json.Unmarshal(*v, &p)
+			return &p, err
+		case TypeCondIPv6:
+			var c CondIPv6
+			err := json.Unmarshal(*v, &c)
+			return &c, err
+		case TypeIPv6MatchSource:
+			var p IPv6MatchSource
+			err := json.Unmarshal(*v, &p)
+			return &p, err
+		case TypeIPv6MatchDestination:
+			var p IPv6MatchDestination
+			err := json.Unmarshal(*v, &p)
+			return &p, err
+		case TypeIPv6MatchTrafficClass:
+			var p IPv6MatchTrafficClass
+			err := json.Unmarshal(*v, &p)
+			return &p, err
+		case TypeIPv6MatchFlowLabel:
+			var p IPv6MatchFlowLabel
+			err := json.Unmarshal(*v, &p)
+			return &p, err
 		case TypeCondPorts:
 			var c CondPorts
 			err := json.Unmarshal(*v, &c)
@@ -132,7 +204,36 @@ func unmarshalInterface(b []byte) (Typer, error) {
 			var p PortMatchDestination
 			err := json.Unmarshal(*v, &p)
 			return &p, err
+		case TypeMatchIsSCION:
+			return MatchIsSCION{}, nil
+		case TypeCondSCION:
+			var c CondSCION
+			err := json.Unmarshal(*v, &c)
+			return &c, err
+		case TypeSCIONMatchPathType:
+			var p SCIONMatchPathType
+			err := json.Unmarshal(*v, &p)
+			return &p, err
+		case TypeSCIONMatchTrafficClass:
+			var p SCIONMatchTrafficClass
+			err := json.Unmarshal(*v, &p)
+			return &p, err
+		case TypeSCIONMatchDSCP:
+			var p SCIONMatchDSCP
+			err := json.Unmarshal(*v, &p)
+			return &p, err
+		case TypeSCIONMatchSrcIA:
+			var p SCIONMatchSrcIA
+			err := json.Unmarshal(*v, &p)
+			return &p, err
+		case TypeSCIONMatchDstIA:
+			var p SCIONMatchDstIA
+			err := json.Unmarshal(*v, &p)
+			return &p, err
 		default:
+			if p, ok, err := unmarshalRegisteredPredicate(k, v); ok {
+				return p, err
+			}
 			return nil, serrors.New("Unknown type", "type", k)
 		}
 	}
@@ -165,6 +266,19 @@ func unmarshalIPv4Predicate(b []byte) (IPv4Predicate, error) {
 	return p, nil
 }
 
+// unmarshalIPv6Predicate extracts an IPv6Predicate from a JSON encoding
+func unmarshalIPv6Predicate(b []byte) (IPv6Predicate, error) {
+	t, err := unmarshalInterface(b)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := t.(IPv6Predicate)
+	if !ok {
+		return nil, serrors.New("Unable to extract IPv6Predicate from interface")
+	}
+	return p, nil
+}
+
 // unmarshalPortPredicate extracts an PortPredicate from a JSON encoding
 func unmarshalPortPredicate(b []byte) (PortPredicate, error) {
 	t, err := unmarshalInterface(b)
@@ -178,6 +292,19 @@ func unmarshalPortPredicate(b []byte) (PortPredicate, error) {
 	return p, nil
 }
 
+// unmarshalSCIONPredicate extracts a SCIONPredicate from a JSON encoding
+func unmarshalSCIONPredicate(b []byte) (SCIONPredicate, error) {
+	t, err := unmarshalInterface(b)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := t.(SCIONPredicate)
+	if !ok {
+		return nil, serrors.New("Unable to extract SCIONPredicate from interface")
+	}
+	return p, nil
+}
+
 // Special case slices because we only need them for Conds
 
 func marshalCondSlice(conds []Cond) ([]byte, error) {
@@ -0,0 +1,60 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+// LoadClassifiers reads all files with the .json extension in dir. Each file
+// must contain a JSON encoded ClassMap. The classes of all files are merged
+// into a single ClassMap. Class names must be unique across all files.
+// Subdirectories are not traversed.
+func LoadClassifiers(dir string) (ClassMap, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, serrors.Wrap("reading classifier directory", err, "dir", dir)
+	}
+	classes := make(ClassMap)
+	origin := make(map[string]string)
+	// Entries are sorted by file name, which makes the errors deterministic.
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, serrors.Wrap("reading classifier file", err, "file", file)
+		}
+		var cm ClassMap
+		if err := json.Unmarshal(raw, &cm); err != nil {
+			return nil, serrors.Wrap("parsing classifier file", err, "file", file)
+		}
+		for name, class := range cm {
+			if other, ok := origin[name]; ok {
+				return nil, serrors.New("duplicate class name", "class", name,
+					"file", file, "other_file", other)
+			}
+			origin[name] = file
+			classes[name] = class
+		}
+	}
+	return classes, nil
+}
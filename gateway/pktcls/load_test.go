@@ -0,0 +1,109 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/gateway/pktcls"
+)
+
+func TestLoadClassifiers(t *testing.T) {
+	const (
+		tos = `{"CondIPv4": {"MatchToS": {"TOS": "0x80"}}}`
+		dst = `{"CondIPv4": {"MatchDestination": {"Net": "192.168.1.0/24"}}}`
+	)
+	testCases := map[string]struct {
+		Files        map[string]string
+		Classes      []string
+		ErrAssertion assert.ErrorAssertionFunc
+		ErrContains  string
+	}{
+		"empty directory": {
+			ErrAssertion: assert.NoError,
+		},
+		"multiple files": {
+			Files: map[string]string{
+				"a.json": `{"tos": ` + tos + `}`,
+				"b.json": `{"dst": ` + dst + `, "any": {"CondBool": true}}`,
+			},
+			Classes:      []string{"any", "dst", "tos"},
+			ErrAssertion: assert.NoError,
+		},
+		"non json files ignored": {
+			Files: map[string]string{
+				"a.json":    `{"tos": ` + tos + `}`,
+				"README.md": "garbage",
+				"b.json~":   "garbage",
+			},
+			Classes:      []string{"tos"},
+			ErrAssertion: assert.NoError,
+		},
+		"invalid file": {
+			Files: map[string]string{
+				"a.json": `{"tos": ` + tos + `}`,
+				"b.json": `{"dst": {"CondUnknown": true}}`,
+			},
+			ErrAssertion: assert.Error,
+			ErrContains:  "b.json",
+		},
+		"missing condition": {
+			Files: map[string]string{
+				"a.json": `{"tos": null}`,
+			},
+			ErrAssertion: assert.Error,
+			ErrContains:  "a.json",
+		},
+		"duplicate name": {
+			Files: map[string]string{
+				"a.json": `{"tos": ` + tos + `}`,
+				"b.json": `{"tos": ` + dst + `}`,
+			},
+			ErrAssertion: assert.Error,
+			ErrContains:  "duplicate class name",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			for file, content := range tc.Files {
+				err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644)
+				require.NoError(t, err)
+			}
+			classes, err := pktcls.LoadClassifiers(dir)
+			tc.ErrAssertion(t, err)
+			if err != nil {
+				assert.ErrorContains(t, err, tc.ErrContains)
+				return
+			}
+			var names []string
+			for name, class := range classes {
+				assert.Equal(t, name, class.GetName())
+				names = append(names, name)
+			}
+			assert.ElementsMatch(t, tc.Classes, names)
+		})
+	}
+}
+
+func TestLoadClassifiersMissingDir(t *testing.T) {
+	_, err := pktcls.LoadClassifiers(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
@@ -290,7 +290,7 @@ func buildTrafficClassParser(class string) *traffic_class.TrafficClassParser {
 // number. The function is case insensitive.
 func protocolNameToNumber(name string) (uint8, error) {
 	for number, meta := range layers.IPProtocolMetadata {
-		if strings.EqualFold(name, meta.Name) {
+		if meta.Name != "" && strings.EqualFold(name, meta.Name) {
 			return uint8(number), nil
 		}
 	}
@@ -0,0 +1,280 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls
+
+import (
+	"strings"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+// ParseCond parses a condition from an infix expression. Predicates have the
+// syntax of their String method, e.g., "ttl<5" or "srcia=1-ff00:0:110", and are
+// combined with the following operators, listed by decreasing precedence:
+//
+//	( expr )       grouping
+//	! expr         CondNot
+//	expr && expr   CondAllOf
+//	expr || expr   CondAnyOf
+//
+// The functional notation of the String methods of CondAllOf, CondAnyOf and
+// CondNot, e.g., "all(src=10.0.0.0/8,not(dscp=0x2e))", is accepted, too. Thus,
+// the String representation of a condition can be parsed back with ParseCond.
+//
+// The grammar is:
+//
+//	expr  = and { "||" and }
+//	and   = unary { "&&" unary }
+//	unary = "!" unary | "(" expr ")" | call | predicate
+//	call  = ( "all" | "any" | "not" ) "(" [ expr { "," expr } ] ")"
+//
+// Chains of the same operator result in a single CondAllOf or CondAnyOf with
+// all operands, e.g., "src=10.0.0.0/8 && dscp=0x2e || protocol=udp" results in
+// CondAnyOf{CondAllOf{src, dscp}, protocol}. The result is the same as the
+// one of unmarshaling the JSON encoding of the condition.
+func ParseCond(expr string) (Cond, error) {
+	p := &exprParser{expr: expr}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEnd {
+		return nil, p.unexpected(tok)
+	}
+	return cond, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenPredicate
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOpen
+	tokenClose
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+type exprParser struct {
+	expr   string
+	tokens []token
+	next   int
+}
+
+// tokenize splits the expression into tokens. Everything that is neither
+// whitespace nor an operator is part of a predicate.
+func (p *exprParser) tokenize() error {
+	operators := []struct {
+		text string
+		kind tokenKind
+	}{
+		{"&&", tokenAnd},
+		{"||", tokenOr},
+		{"!", tokenNot},
+		{"(", tokenOpen},
+		{")", tokenClose},
+		{",", tokenComma},
+	}
+	i := 0
+outer:
+	for i < len(p.expr) {
+		if isExprSpace(p.expr[i]) {
+			i++
+			continue
+		}
+		for _, op := range operators {
+			if strings.HasPrefix(p.expr[i:], op.text) {
+				p.tokens = append(p.tokens, token{kind: op.kind, text: op.text, pos: i})
+				i += len(op.text)
+				continue outer
+			}
+		}
+		start := i
+		// Parentheses in the value of a predicate, e.g., "ecn=ECT(1)", are
+		// part of the predicate.
+		value, depth := false, 0
+	predicate:
+		for ; i < len(p.expr); i++ {
+			switch c := p.expr[i]; {
+			case c == '=':
+				value = true
+			case c == '(' && value:
+				depth++
+			case c == ')' && depth > 0:
+				depth--
+			case !isPredicateChar(c):
+				break predicate
+			}
+		}
+		if start == i {
+			return serrors.New("Invalid operator", "expr", p.expr, "pos", i)
+		}
+		p.tokens = append(p.tokens, token{kind: tokenPredicate, text: p.expr[start:i],
+			pos: start})
+	}
+	p.tokens = append(p.tokens, token{kind: tokenEnd, pos: len(p.expr)})
+	return nil
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *exprParser) pop() token {
+	tok := p.tokens[p.next]
+	if tok.kind != tokenEnd {
+		p.next++
+	}
+	return tok
+}
+
+func (p *exprParser) parseOr() (Cond, error) {
+	cond, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenOr {
+		return cond, nil
+	}
+	conds := CondAnyOf{cond}
+	for p.peek().kind == tokenOr {
+		p.pop()
+		cond, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond)
+	}
+	return conds, nil
+}
+
+func (p *exprParser) parseAnd() (Cond, error) {
+	cond, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenAnd {
+		return cond, nil
+	}
+	conds := CondAllOf{cond}
+	for p.peek().kind == tokenAnd {
+		p.pop()
+		cond, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond)
+	}
+	return conds, nil
+}
+
+func (p *exprParser) parseUnary() (Cond, error) {
+	tok := p.pop()
+	switch tok.kind {
+	case tokenNot:
+		cond, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return NewCondNot(cond), nil
+	case tokenOpen:
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok := p.pop(); tok.kind != tokenClose {
+			return nil, p.unexpected(tok)
+		}
+		return cond, nil
+	case tokenPredicate:
+		if p.peek().kind == tokenOpen {
+			return p.parseCall(tok)
+		}
+		cond, err := parsePredicate(tok.text)
+		if err != nil {
+			return nil, serrors.Wrap("Invalid predicate", err, "expr", p.expr,
+				"pos", tok.pos, "predicate", tok.text)
+		}
+		return cond, nil
+	default:
+		return nil, p.unexpected(tok)
+	}
+}
+
+// parseCall parses the functional notation of CondAllOf, CondAnyOf and
+// CondNot. The opening parenthesis is the next token.
+func (p *exprParser) parseCall(name token) (Cond, error) {
+	p.pop()
+	var args []Cond
+	if p.peek().kind == tokenClose {
+		p.pop()
+	} else {
+		for {
+			cond, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, cond)
+			tok := p.pop()
+			if tok.kind == tokenClose {
+				break
+			}
+			if tok.kind != tokenComma {
+				return nil, p.unexpected(tok)
+			}
+		}
+	}
+	switch strings.ToLower(name.text) {
+	case "all":
+		return NewCondAllOf(args...), nil
+	case "any":
+		return NewCondAnyOf(args...), nil
+	case "not":
+		if len(args) != 1 {
+			return nil, serrors.New("Not requires exactly one operand", "expr", p.expr,
+				"pos", name.pos, "operands", len(args))
+		}
+		return NewCondNot(args[0]), nil
+	default:
+		return nil, serrors.New("Unknown function", "expr", p.expr, "pos", name.pos,
+			"function", name.text)
+	}
+}
+
+func (p *exprParser) unexpected(tok token) error {
+	if tok.kind == tokenEnd {
+		return serrors.New("Unexpected end of expression", "expr", p.expr)
+	}
+	return serrors.New("Unexpected token", "expr", p.expr, "pos", tok.pos, "token", tok.text)
+}
+
+func isExprSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isPredicateChar(c byte) bool {
+	return !isExprSpace(c) && !strings.ContainsRune("&|!(),", rune(c))
+}
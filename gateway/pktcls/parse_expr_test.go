@@ -0,0 +1,346 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls_test
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/gateway/pktcls"
+	"github.com/scionproto/scion/pkg/addr"
+	"github.com/scionproto/scion/pkg/slayers/path/onehop"
+)
+
+func TestParseCond(t *testing.T) {
+	_, network, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+	src := pktcls.NewCondIPv4(&pktcls.IPv4MatchSource{Net: network})
+	dscp := pktcls.NewCondIPv4(&pktcls.IPv4MatchDSCP{DSCP: 0x2e})
+	udp := pktcls.NewCondIPv4(&pktcls.IPv4MatchProtocol{Protocol: 17})
+	port := pktcls.NewCondPorts(&pktcls.PortMatchDestination{MinPort: 80, MaxPort: 443})
+	_, network6, err := net.ParseCIDR("2001:db8::/32")
+	require.NoError(t, err)
+	ttl := pktcls.NewCondIPv4(&pktcls.IPv4MatchTTL{Op: pktcls.CmpLt, TTL: 5})
+
+	testCases := map[string]struct {
+		Expr        string
+		Cond        pktcls.Cond
+		ErrContains string
+	}{
+		"predicate": {
+			Expr: "src=10.0.0.0/8",
+			Cond: src,
+		},
+		"and before or": {
+			Expr: "src=10.0.0.0/8 && dscp=0x2e || protocol=udp",
+			Cond: pktcls.NewCondAnyOf(pktcls.NewCondAllOf(src, dscp), udp),
+		},
+		"and before or on the right": {
+			Expr: "protocol=udp || src=10.0.0.0/8 && dscp=0x2e",
+			Cond: pktcls.NewCondAnyOf(udp, pktcls.NewCondAllOf(src, dscp)),
+		},
+		"parentheses": {
+			Expr: "src=10.0.0.0/8 && (dscp=0x2e || protocol=udp)",
+			Cond: pktcls.NewCondAllOf(src, pktcls.NewCondAnyOf(dscp, udp)),
+		},
+		"not before and": {
+			Expr: "!src=10.0.0.0/8 && dscp=0x2e",
+			Cond: pktcls.NewCondAllOf(pktcls.NewCondNot(src), dscp),
+		},
+		"not of group": {
+			Expr: "!(src=10.0.0.0/8 && dscp=0x2e)",
+			Cond: pktcls.NewCondNot(pktcls.NewCondAllOf(src, dscp)),
+		},
+		"double not": {
+			Expr: "!!src=10.0.0.0/8",
+			Cond: pktcls.NewCondNot(pktcls.NewCondNot(src)),
+		},
+		"chains are flattened": {
+			Expr: "src=10.0.0.0/8&&dscp=0x2e&&protocol=udp||dstport=80-443||bool=true",
+			Cond: pktcls.NewCondAnyOf(pktcls.NewCondAllOf(src, dscp, udp), port,
+				pktcls.CondTrue),
+		},
+		"nested groups": {
+			Expr: "((src=10.0.0.0/8))",
+			Cond: src,
+		},
+		"protocol number": {
+			Expr: "protocol=17 || protocol=253",
+			Cond: pktcls.NewCondAnyOf(udp,
+				pktcls.NewCondIPv4(&pktcls.IPv4MatchProtocol{Protocol: 253})),
+		},
+		"protocol name is case insensitive": {
+			Expr: "protocol=UDP",
+			Cond: udp,
+		},
+		"ecn": {
+			Expr: "ecn=CE",
+			Cond: pktcls.NewCondIPv4(&pktcls.IPv4MatchECN{ECN: 0x3}),
+		},
+		"ecn with parentheses": {
+			Expr: "(ecn=ECT(1)) && !ecn=ECT(0)",
+			Cond: pktcls.NewCondAllOf(
+				pktcls.NewCondIPv4(&pktcls.IPv4MatchECN{ECN: 0x1}),
+				pktcls.NewCondNot(pktcls.NewCondIPv4(&pktcls.IPv4MatchECN{ECN: 0x2})),
+			),
+		},
+		"ttl less": {
+			Expr: "ttl<5",
+			Cond: ttl,
+		},
+		"ttl greater": {
+			Expr: "ttl>200",
+			Cond: pktcls.NewCondIPv4(&pktcls.IPv4MatchTTL{Op: pktcls.CmpGt, TTL: 200}),
+		},
+		"ttl equal": {
+			Expr: "ttl=64",
+			Cond: pktcls.NewCondIPv4(&pktcls.IPv4MatchTTL{Op: pktcls.CmpEq, TTL: 64}),
+		},
+		"length": {
+			Expr: "len>1500",
+			Cond: pktcls.NewCondIPv4(&pktcls.IPv4MatchLength{Op: pktcls.CmpGt, Length: 1500}),
+		},
+		"length range": {
+			Expr: "len=1000-1500",
+			Cond: pktcls.NewCondIPv4(&pktcls.IPv4MatchLength{
+				Op: pktcls.CmpRange, Length: 1000, MaxLength: 1500,
+			}),
+		},
+		"any option": {
+			Expr: "options=any",
+			Cond: pktcls.NewCondIPv4(&pktcls.IPv4MatchHasOptions{Any: true}),
+		},
+		"option type": {
+			Expr: "options=131",
+			Cond: pktcls.NewCondIPv4(&pktcls.IPv4MatchHasOptions{OptionType: 131}),
+		},
+		"checksum": {
+			Expr: "checksum=invalid",
+			Cond: pktcls.NewCondIPv4(&pktcls.IPv4MatchChecksumValid{Valid: false}),
+		},
+		"payload": {
+			Expr: "payload[4]=0xcafe",
+			Cond: pktcls.NewCondIPv4(&pktcls.IPv4MatchPayload{
+				Offset: 4, Pattern: []byte{0xca, 0xfe},
+			}),
+		},
+		"source host": {
+			Expr: "srchost=*.internal.example",
+			Cond: pktcls.NewCondIPv4(&pktcls.IPv4MatchSourceHost{Host: "*.internal.example"}),
+		},
+		"destination host": {
+			Expr: "dsthost=db.example",
+			Cond: pktcls.NewCondIPv4(&pktcls.IPv4MatchDestinationHost{Host: "db.example"}),
+		},
+		"ipv6 source": {
+			Expr: "src6=2001:db8::/32",
+			Cond: pktcls.NewCondIPv6(&pktcls.IPv6MatchSource{Net: network6}),
+		},
+		"ipv6 destination": {
+			Expr: "dst6=2001:db8::/32",
+			Cond: pktcls.NewCondIPv6(&pktcls.IPv6MatchDestination{Net: network6}),
+		},
+		"ipv6 traffic class": {
+			Expr: "tc6=0xb8",
+			Cond: pktcls.NewCondIPv6(&pktcls.IPv6MatchTrafficClass{TrafficClass: 0xb8}),
+		},
+		"ipv6 flow label": {
+			Expr: "flowlabel6=12345",
+			Cond: pktcls.NewCondIPv6(&pktcls.IPv6MatchFlowLabel{FlowLabel: 12345}),
+		},
+		"is scion": {
+			Expr: "isscion && !pathtype=onehop",
+			Cond: pktcls.NewCondAllOf(
+				pktcls.MatchIsSCION{},
+				pktcls.NewCondNot(pktcls.NewCondSCION(
+					&pktcls.SCIONMatchPathType{PathType: onehop.PathType},
+				)),
+			),
+		},
+		"numeric path type": {
+			Expr: "pathtype=7",
+			Cond: pktcls.NewCondSCION(&pktcls.SCIONMatchPathType{PathType: 7}),
+		},
+		"scion traffic class": {
+			Expr: "sciontc=0xb8",
+			Cond: pktcls.NewCondSCION(&pktcls.SCIONMatchTrafficClass{TrafficClass: 0xb8}),
+		},
+		"scion dscp": {
+			Expr: "sciondscp=0x2e",
+			Cond: pktcls.NewCondSCION(&pktcls.SCIONMatchDSCP{DSCP: 0x2e}),
+		},
+		"source ia": {
+			Expr: "srcia=1-ff00:0:110",
+			Cond: pktcls.NewCondSCION(&pktcls.SCIONMatchSrcIA{
+				IA: addr.MustParseIA("1-ff00:0:110"),
+			}),
+		},
+		"destination ia": {
+			Expr: "dstia=1-0",
+			Cond: pktcls.NewCondSCION(&pktcls.SCIONMatchDstIA{IA: addr.MustParseIA("1-0")}),
+		},
+		"functional notation": {
+			Expr: "all(src=10.0.0.0/8,not(ttl<5),any(dscp=0x2e || protocol=udp))",
+			Cond: pktcls.NewCondAllOf(src, pktcls.NewCondNot(ttl),
+				pktcls.NewCondAnyOf(pktcls.NewCondAnyOf(dscp, udp))),
+		},
+		"empty call": {
+			Expr: "any()",
+			Cond: pktcls.NewCondAnyOf(),
+		},
+		"upper case call": {
+			Expr: "NOT(src=10.0.0.0/8)",
+			Cond: pktcls.NewCondNot(src),
+		},
+		"empty": {
+			Expr:        "  ",
+			ErrContains: "Unexpected end of expression",
+		},
+		"missing operand": {
+			Expr:        "src=10.0.0.0/8 &&",
+			ErrContains: "Unexpected end of expression",
+		},
+		"missing operator": {
+			Expr:        "src=10.0.0.0/8 dscp=0x2e",
+			ErrContains: "Unexpected token",
+		},
+		"unbalanced parentheses": {
+			Expr:        "(src=10.0.0.0/8 || dscp=0x2e",
+			ErrContains: "Unexpected end of expression",
+		},
+		"unmatched closing parenthesis": {
+			Expr:        "src=10.0.0.0/8)",
+			ErrContains: "Unexpected token",
+		},
+		"single ampersand": {
+			Expr:        "src=10.0.0.0/8 & dscp=0x2e",
+			ErrContains: "Invalid operator",
+		},
+		"missing value": {
+			Expr:        "ttl<",
+			ErrContains: "Missing value",
+		},
+		"value out of range": {
+			Expr:        "ttl<300",
+			ErrContains: "Invalid predicate",
+		},
+		"unsupported operator": {
+			Expr:        "srcia<1-0",
+			ErrContains: "Unsupported operator",
+		},
+		"unexpected operand": {
+			Expr:        "isscion=true",
+			ErrContains: "Unexpected operand",
+		},
+		"missing index": {
+			Expr:        "payload=0xcafe",
+			ErrContains: "Invalid index",
+		},
+		"invalid range": {
+			Expr:        "len=1000-",
+			ErrContains: "Invalid range",
+		},
+		"invalid checksum state": {
+			Expr:        "checksum=maybe",
+			ErrContains: "Invalid checksum state",
+		},
+		"not with two operands": {
+			Expr:        "not(src=10.0.0.0/8,dscp=0x2e)",
+			ErrContains: "Not requires exactly one operand",
+		},
+		"unknown function": {
+			Expr:        "one(src=10.0.0.0/8)",
+			ErrContains: "Unknown function",
+		},
+		"missing comma": {
+			Expr:        "all(src=10.0.0.0/8 dscp=0x2e)",
+			ErrContains: "Unexpected token",
+		},
+		"comma outside of call": {
+			Expr:        "src=10.0.0.0/8,dscp=0x2e",
+			ErrContains: "Unexpected token",
+		},
+		"invalid predicate": {
+			Expr:        "src=10.0.0.0/8 || dscp=2e",
+			ErrContains: "Invalid predicate",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cond, err := pktcls.ParseCond(tc.Expr)
+			if tc.ErrContains != "" {
+				assert.ErrorContains(t, err, tc.ErrContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.Cond, cond)
+
+			// The parsed condition is the same as the unmarshaled one.
+			classes := pktcls.ClassMap{"c": pktcls.NewClass("c", cond)}
+			raw, err := json.Marshal(classes)
+			require.NoError(t, err)
+			var parsed pktcls.ClassMap
+			require.NoError(t, json.Unmarshal(raw, &parsed))
+			assert.Equal(t, classes, parsed)
+		})
+	}
+}
+
+func TestParseCondString(t *testing.T) {
+	// The String representation of every condition that can be encoded in
+	// JSON is parsed back to the same condition.
+	_, network6, err := net.ParseCIDR("2001:db8::/32")
+	require.NoError(t, err)
+	conds := []pktcls.Cond{
+		pktcls.NewCondIPv4(&pktcls.IPv4MatchProtocol{Protocol: 17}),
+		pktcls.NewCondIPv4(&pktcls.IPv4MatchProtocol{Protocol: 253}),
+		pktcls.NewCondAllOf(),
+		pktcls.NewCondAnyOf(pktcls.CondTrue, pktcls.CondFalse),
+		pktcls.NewCondIPv4(&pktcls.IPv4MatchECN{ECN: 0x1}),
+		pktcls.NewCondIPv4(&pktcls.IPv4MatchLength{
+			Op: pktcls.CmpRange, Length: 1000, MaxLength: 1500,
+		}),
+		pktcls.NewCondIPv4(&pktcls.IPv4MatchLength{Op: pktcls.CmpLt, Length: 64}),
+		pktcls.NewCondIPv4(&pktcls.IPv4MatchChecksumValid{Valid: true}),
+		pktcls.NewCondIPv4(&pktcls.IPv4MatchPayload{Offset: 0, Pattern: []byte{0x00}}),
+		pktcls.NewCondIPv4(&pktcls.IPv4MatchSourceHost{Host: "*.internal.example"}),
+		pktcls.NewCondIPv4(&pktcls.IPv4MatchDestinationHost{Host: "db.example"}),
+		pktcls.NewCondIPv6(&pktcls.IPv6MatchSource{Net: network6}),
+		pktcls.NewCondIPv6(&pktcls.IPv6MatchDestination{Net: network6}),
+		pktcls.NewCondIPv6(&pktcls.IPv6MatchTrafficClass{TrafficClass: 0}),
+		pktcls.NewCondIPv6(&pktcls.IPv6MatchFlowLabel{FlowLabel: 0xfffff}),
+		pktcls.NewCondNot(pktcls.MatchIsSCION{}),
+		pktcls.NewCondSCION(&pktcls.SCIONMatchPathType{PathType: 7}),
+		pktcls.NewCondSCION(&pktcls.SCIONMatchDSCP{DSCP: 0x2e}),
+		pktcls.NewCondSCION(&pktcls.SCIONMatchDstIA{IA: addr.MustParseIA("0-ff00:0:110")}),
+	}
+	var classes pktcls.ClassMap
+	raw, err := os.ReadFile("testdata/class_1.json")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(raw, &classes))
+	for _, class := range classes {
+		conds = append(conds, class.Cond)
+	}
+	for _, cond := range conds {
+		parsed, err := pktcls.ParseCond(cond.String())
+		require.NoError(t, err, cond.String())
+		assert.Equal(t, cond, parsed, cond.String())
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+// textOperand is the part of the text representation of a predicate that
+// follows its name, e.g., "<5" of "ttl<5" or "[4]=0xcafe" of
+// "payload[4]=0xcafe".
+type textOperand struct {
+	name  string
+	index string
+	op    string
+	value string
+}
+
+// check returns an error if the operator of the operand is not one of ops, or
+// if the operand has an unexpected index or no value.
+func (o textOperand) check(indexed bool, ops ...string) error {
+	switch {
+	case (o.index != "") != indexed:
+		return serrors.New("Invalid index", "predicate", o.name, "index", o.index)
+	case len(ops) == 0 && o.op != "":
+		return serrors.New("Unexpected operand", "predicate", o.name, "op", o.op)
+	case len(ops) == 0:
+		return nil
+	case !slices.Contains(ops, o.op):
+		return serrors.New("Unsupported operator", "predicate", o.name, "op", o.op)
+	case o.value == "":
+		return serrors.New("Missing value", "predicate", o.name)
+	}
+	return nil
+}
+
+// cmpOp returns the comparison operator of the operand.
+func (o textOperand) cmpOp() CmpOp {
+	switch o.op {
+	case "<":
+		return CmpLt
+	case ">":
+		return CmpGt
+	default:
+		return CmpEq
+	}
+}
+
+type textParser func(o textOperand) (Cond, error)
+
+// textPredicates are the parsers of the predicates that are not part of the
+// traffic class grammar, by the name used in their String method. The protocol
+// predicate is parsed here, too, because the grammar does not accept protocol
+// numbers, which String uses for protocols without a name. The values
+// are decoded by the JSON unmarshaler of the predicate, such that the text
+// representation accepts the same values as the JSON encoding.
+var textPredicates = map[string]textParser{
+	"protocol":   ipv4Field[IPv4MatchProtocol]("Protocol"),
+	"ecn":        ipv4Field[IPv4MatchECN]("ECN"),
+	"ttl":        parseTTLText,
+	"len":        parseLengthText,
+	"options":    ipv4Field[IPv4MatchHasOptions]("Option"),
+	"checksum":   parseChecksumText,
+	"payload":    parsePayloadText,
+	"srchost":    ipv4Field[IPv4MatchSourceHost]("Host"),
+	"dsthost":    ipv4Field[IPv4MatchDestinationHost]("Host"),
+	"src6":       ipv6Field[IPv6MatchSource]("Net"),
+	"dst6":       ipv6Field[IPv6MatchDestination]("Net"),
+	"tc6":        ipv6Field[IPv6MatchTrafficClass]("TrafficClass"),
+	"flowlabel6": ipv6Field[IPv6MatchFlowLabel]("FlowLabel"),
+	"isscion":    parseIsSCIONText,
+	"pathtype":   scionField[SCIONMatchPathType]("PathType"),
+	"sciontc":    scionField[SCIONMatchTrafficClass]("TrafficClass"),
+	"sciondscp":  scionField[SCIONMatchDSCP]("DSCP"),
+	"srcia":      scionField[SCIONMatchSrcIA]("IA"),
+	"dstia":      scionField[SCIONMatchDstIA]("IA"),
+}
+
+// parsePredicate parses a single predicate. Predicates of the traffic class
+// grammar, e.g., "src=10.0.0.0/8", are parsed with BuildClassTree.
+func parsePredicate(s string) (Cond, error) {
+	o := splitPredicate(s)
+	parse, ok := textPredicates[o.name]
+	if !ok {
+		return BuildClassTree(s)
+	}
+	return parse(o)
+}
+
+// splitPredicate splits the text representation of a predicate into its name
+// and operand.
+func splitPredicate(s string) textOperand {
+	i := 0
+	for i < len(s) && ('a' <= s[i] && s[i] <= 'z' || '0' <= s[i] && s[i] <= '9') {
+		i++
+	}
+	o := textOperand{name: s[:i]}
+	rest := s[i:]
+	if index, ok := strings.CutPrefix(rest, "["); ok {
+		if end := strings.IndexByte(index, ']'); end >= 0 {
+			o.index, rest = index[:end], index[end+1:]
+		}
+	}
+	if rest != "" {
+		o.op, o.value = rest[:1], rest[1:]
+	}
+	return o
+}
+
+// unmarshalText decodes the JSON fields into the predicate p.
+func unmarshalText(p json.Unmarshaler, fields jsonContainer) error {
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return serrors.Wrap("Unable to encode operand", err)
+	}
+	return p.UnmarshalJSON(raw)
+}
+
+// ipv4Field returns the parser of the IPv4 predicate P of the form
+// "name=value", where the value is the JSON field of the predicate.
+func ipv4Field[T any, P interface {
+	*T
+	IPv4Predicate
+	json.Unmarshaler
+}](field string) textParser {
+	return func(o textOperand) (Cond, error) {
+		if err := o.check(false, "="); err != nil {
+			return nil, err
+		}
+		p := P(new(T))
+		if err := unmarshalText(p, jsonContainer{field: o.value}); err != nil {
+			return nil, err
+		}
+		return NewCondIPv4(p), nil
+	}
+}
+
+// ipv6Field is the equivalent of ipv4Field for IPv6 predicates.
+func ipv6Field[T any, P interface {
+	*T
+	IPv6Predicate
+	json.Unmarshaler
+}](field string) textParser {
+	return func(o textOperand) (Cond, error) {
+		if err := o.check(false, "="); err != nil {
+			return nil, err
+		}
+		p := P(new(T))
+		if err := unmarshalText(p, jsonContainer{field: o.value}); err != nil {
+			return nil, err
+		}
+		return NewCondIPv6(p), nil
+	}
+}
+
+// scionField is the equivalent of ipv4Field for SCION predicates.
+func scionField[T any, P interface {
+	*T
+	SCIONPredicate
+	json.Unmarshaler
+}](field string) textParser {
+	return func(o textOperand) (Cond, error) {
+		if err := o.check(false, "="); err != nil {
+			return nil, err
+		}
+		p := P(new(T))
+		if err := unmarshalText(p, jsonContainer{field: o.value}); err != nil {
+			return nil, err
+		}
+		return NewCondSCION(p), nil
+	}
+}
+
+// parseTTLText parses "ttl=N", "ttl<N" and "ttl>N".
+func parseTTLText(o textOperand) (Cond, error) {
+	if err := o.check(false, "=", "<", ">"); err != nil {
+		return nil, err
+	}
+	p := &IPv4MatchTTL{}
+	if err := unmarshalText(p, jsonContainer{"Op": o.cmpOp(), "TTL": o.value}); err != nil {
+		return nil, err
+	}
+	return NewCondIPv4(p), nil
+}
+
+// parseLengthText parses "len=N", "len<N", "len>N" and the range "len=N-M".
+func parseLengthText(o textOperand) (Cond, error) {
+	if err := o.check(false, "=", "<", ">"); err != nil {
+		return nil, err
+	}
+	fields := jsonContainer{"Op": o.cmpOp(), "Length": json.Number(o.value)}
+	if low, high, ok := strings.Cut(o.value, "-"); ok && o.op == "=" {
+		if low == "" || high == "" {
+			return nil, serrors.New("Invalid range", "predicate", o.name, "value", o.value)
+		}
+		fields = jsonContainer{
+			"Op":        CmpRange,
+			"Length":    json.Number(low),
+			"MaxLength": json.Number(high),
+		}
+	}
+	p := &IPv4MatchLength{}
+	if err := unmarshalText(p, fields); err != nil {
+		return nil, err
+	}
+	return NewCondIPv4(p), nil
+}
+
+// parseChecksumText parses "checksum=valid" and "checksum=invalid".
+func parseChecksumText(o textOperand) (Cond, error) {
+	if err := o.check(false, "="); err != nil {
+		return nil, err
+	}
+	switch o.value {
+	case "valid":
+		return NewCondIPv4(&IPv4MatchChecksumValid{Valid: true}), nil
+	case "invalid":
+		return NewCondIPv4(&IPv4MatchChecksumValid{Valid: false}), nil
+	default:
+		return nil, serrors.New("Invalid checksum state", "predicate", o.name,
+			"value", o.value)
+	}
+}
+
+// parsePayloadText parses "payload[offset]=0xpattern".
+func parsePayloadText(o textOperand) (Cond, error) {
+	if err := o.check(true, "="); err != nil {
+		return nil, err
+	}
+	p := &IPv4MatchPayload{}
+	fields := jsonContainer{"Offset": json.Number(o.index), "Pattern": o.value}
+	if err := unmarshalText(p, fields); err != nil {
+		return nil, err
+	}
+	return NewCondIPv4(p), nil
+}
+
+// parseIsSCIONText parses "isscion", which has no operand.
+func parseIsSCIONText(o textOperand) (Cond, error) {
+	if err := o.check(false); err != nil {
+		return nil, err
+	}
+	return MatchIsSCION{}, nil
+}
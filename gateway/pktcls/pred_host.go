@@ -0,0 +1,260 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopacket/gopacket/layers"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+const (
+	// hostLookupTimeout is the hard upper bound for a single reverse lookup.
+	hostLookupTimeout = 100 * time.Millisecond
+	// hostCacheTTL is the time a lookup result, positive or negative, is
+	// cached.
+	hostCacheTTL = 30 * time.Second
+	// hostCacheSize is the maximum number of cached addresses.
+	hostCacheSize = 1024
+	// hostMaxLookups is the maximum number of concurrent reverse lookups.
+	hostMaxLookups = 16
+)
+
+// defaultHostResolver is shared by all host predicates that do not have a
+// resolver set explicitly.
+var defaultHostResolver = newHostResolver(
+	net.DefaultResolver.LookupAddr, hostCacheTTL, hostLookupTimeout)
+
+var _ IPv4Predicate = (*IPv4MatchSourceHost)(nil)
+
+// IPv4MatchSourceHost checks whether the reverse DNS name of the source IPv4
+// address matches the Host pattern. The pattern uses the syntax of path.Match,
+// e.g., "*.internal.example", and is matched case-insensitively.
+//
+// The predicate is intended for lab setups and should not be used on the hot
+// path. Lookups run in the background, are cached and bounded by a short
+// timeout. The predicate does not match until the lookup for an address has
+// completed, nor if the lookup fails or times out.
+type IPv4MatchSourceHost struct {
+	Host string
+
+	resolver *hostResolver
+}
+
+func (m *IPv4MatchSourceHost) Type() string {
+	return TypeIPv4MatchSourceHost
+}
+
+func (m *IPv4MatchSourceHost) Eval(p *layers.IPv4) bool {
+	return resolverOrDefault(m.resolver).match(p.SrcIP, m.Host)
+}
+
+func (m *IPv4MatchSourceHost) String() string {
+	return fmt.Sprintf("srchost=%s", m.Host)
+}
+
+func (m *IPv4MatchSourceHost) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		jsonContainer{
+			"Host": m.Host,
+		},
+	)
+}
+
+func (m *IPv4MatchSourceHost) UnmarshalJSON(b []byte) error {
+	s, err := unmarshalHostPattern(b, TypeIPv4MatchSourceHost)
+	if err != nil {
+		return err
+	}
+	m.Host = s
+	return nil
+}
+
+var _ IPv4Predicate = (*IPv4MatchDestinationHost)(nil)
+
+// IPv4MatchDestinationHost checks whether the reverse DNS name of the
+// destination IPv4 address matches the Host pattern. It behaves like
+// IPv4MatchSourceHost otherwise.
+type IPv4MatchDestinationHost struct {
+	Host string
+
+	resolver *hostResolver
+}
+
+func (m *IPv4MatchDestinationHost) Type() string {
+	return TypeIPv4MatchDestinationHost
+}
+
+func (m *IPv4MatchDestinationHost) Eval(p *layers.IPv4) bool {
+	return resolverOrDefault(m.resolver).match(p.DstIP, m.Host)
+}
+
+func (m *IPv4MatchDestinationHost) String() string {
+	return fmt.Sprintf("dsthost=%s", m.Host)
+}
+
+func (m *IPv4MatchDestinationHost) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		jsonContainer{
+			"Host": m.Host,
+		},
+	)
+}
+
+func (m *IPv4MatchDestinationHost) UnmarshalJSON(b []byte) error {
+	s, err := unmarshalHostPattern(b, TypeIPv4MatchDestinationHost)
+	if err != nil {
+		return err
+	}
+	m.Host = s
+	return nil
+}
+
+func unmarshalHostPattern(b []byte, name string) (string, error) {
+	s, err := unmarshalStringField(b, name, "Host")
+	if err != nil {
+		return "", err
+	}
+	if _, err := path.Match(s, ""); err != nil {
+		return "", serrors.Wrap("Unable to parse "+name+" operand", err, "host", s)
+	}
+	return s, nil
+}
+
+func resolverOrDefault(r *hostResolver) *hostResolver {
+	if r == nil {
+		return defaultHostResolver
+	}
+	return r
+}
+
+type lookupAddrFunc func(ctx context.Context, addr string) ([]string, error)
+
+type hostEntry struct {
+	names   []string
+	expires time.Time
+}
+
+// hostResolver resolves IP addresses to host names and caches the results.
+// Lookups run in the background, such that packet classification never waits
+// for DNS.
+type hostResolver struct {
+	lookup  lookupAddrFunc
+	ttl     time.Duration
+	timeout time.Duration
+
+	group   singleflight.Group
+	pending chan struct{}
+
+	mu      sync.Mutex
+	entries map[string]hostEntry
+}
+
+func newHostResolver(lookup lookupAddrFunc, ttl, timeout time.Duration) *hostResolver {
+	return &hostResolver{
+		lookup:  lookup,
+		ttl:     ttl,
+		timeout: timeout,
+		pending: make(chan struct{}, hostMaxLookups),
+		entries: make(map[string]hostEntry),
+	}
+}
+
+// match returns true if any of the names of ip matches pattern.
+func (r *hostResolver) match(ip net.IP, pattern string) bool {
+	if ip == nil {
+		return false
+	}
+	pattern = strings.ToLower(pattern)
+	for _, name := range r.resolve(ip.String()) {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve returns the cached names of addr. If addr is not cached or its entry
+// expired, a lookup is started in the background. Until it completes, an
+// uncached address has no names and an expired entry keeps its names.
+func (r *hostResolver) resolve(addr string) []string {
+	r.mu.Lock()
+	e, ok := r.entries[addr]
+	r.mu.Unlock()
+	if !ok || !time.Now().Before(e.expires) {
+		// Concurrent misses for the same address share a single lookup. The
+		// result channel is buffered, so it does not need to be drained.
+		r.group.DoChan(addr, func() (any, error) {
+			r.refresh(addr)
+			return nil, nil
+		})
+	}
+	return e.names
+}
+
+// refresh looks up addr and caches the normalized names. Failed lookups result
+// in no names and are cached like successful ones, such that an unresponsive
+// DNS server is not queried for every packet.
+func (r *hostResolver) refresh(addr string) {
+	select {
+	case r.pending <- struct{}{}:
+		defer func() { <-r.pending }()
+	default:
+		// Too many lookups are in flight, e.g., because of packets with
+		// spoofed source addresses. The address is retried on the next miss.
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	names, err := r.lookup(ctx, addr)
+	if err != nil {
+		names = nil
+	}
+	for i, name := range names {
+		names[i] = strings.ToLower(strings.TrimSuffix(name, "."))
+	}
+
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.entries[addr]; !ok && len(r.entries) >= hostCacheSize {
+		r.evict(now)
+	}
+	r.entries[addr] = hostEntry{names: names, expires: now.Add(r.ttl)}
+}
+
+// evict removes a single entry from the cache, preferring an expired one. The
+// caller must hold r.mu.
+func (r *hostResolver) evict(now time.Time) {
+	var victim string
+	for addr, e := range r.entries {
+		victim = addr
+		if !now.Before(e.expires) {
+			break
+		}
+	}
+	delete(r.entries, victim)
+}
@@ -0,0 +1,223 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gopacket/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/gateway/pktcls"
+)
+
+func TestIPv4MatchHost(t *testing.T) {
+	names := map[string][]string{
+		"10.0.0.1": {"Host1.Internal.Example."},
+		"10.0.0.2": {"host2.external.example."},
+	}
+	lookup := func(_ context.Context, addr string) ([]string, error) {
+		if n, ok := names[addr]; ok {
+			return append([]string(nil), n...), nil
+		}
+		return nil, errors.New("not found")
+	}
+	testCases := map[string]struct {
+		Pred    pktcls.IPv4Predicate
+		Src     net.IP
+		Dst     net.IP
+		ExpEval bool
+	}{
+		"source matches": {
+			Pred:    &pktcls.IPv4MatchSourceHost{Host: "*.internal.example"},
+			Src:     net.IP{10, 0, 0, 1},
+			Dst:     net.IP{10, 0, 0, 2},
+			ExpEval: true,
+		},
+		"source does not match": {
+			Pred:    &pktcls.IPv4MatchSourceHost{Host: "*.internal.example"},
+			Src:     net.IP{10, 0, 0, 2},
+			Dst:     net.IP{10, 0, 0, 1},
+			ExpEval: false,
+		},
+		"destination matches": {
+			Pred:    &pktcls.IPv4MatchDestinationHost{Host: "*.external.example"},
+			Src:     net.IP{10, 0, 0, 1},
+			Dst:     net.IP{10, 0, 0, 2},
+			ExpEval: true,
+		},
+		"lookup failure": {
+			Pred:    &pktcls.IPv4MatchSourceHost{Host: "*"},
+			Src:     net.IP{10, 0, 0, 3},
+			Dst:     net.IP{10, 0, 0, 1},
+			ExpEval: false,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			pktcls.SetHostLookup(tc.Pred, lookup, time.Minute, time.Second)
+			pkt := &layers.IPv4{SrcIP: tc.Src, DstIP: tc.Dst}
+			addr := tc.Src.String()
+			if _, ok := tc.Pred.(*pktcls.IPv4MatchDestinationHost); ok {
+				addr = tc.Dst.String()
+			}
+			// The first evaluation only starts the lookup.
+			assert.False(t, tc.Pred.Eval(pkt))
+			awaitHostLookup(t, tc.Pred, addr)
+			assert.Equal(t, tc.ExpEval, tc.Pred.Eval(pkt))
+		})
+	}
+}
+
+func TestIPv4MatchHostTimeout(t *testing.T) {
+	lookup := func(ctx context.Context, _ string) ([]string, error) {
+		<-ctx.Done()
+		return []string{"host.internal.example."}, ctx.Err()
+	}
+	pred := &pktcls.IPv4MatchSourceHost{Host: "*.internal.example"}
+	pktcls.SetHostLookup(pred, lookup, time.Minute, 10*time.Millisecond)
+	assert.False(t, pred.Eval(&layers.IPv4{SrcIP: net.IP{10, 0, 0, 1}}))
+	awaitHostLookup(t, pred, "10.0.0.1")
+	assert.False(t, pred.Eval(&layers.IPv4{SrcIP: net.IP{10, 0, 0, 1}}))
+}
+
+func TestIPv4MatchHostNonBlocking(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	lookup := func(_ context.Context, _ string) ([]string, error) {
+		calls.Add(1)
+		<-release
+		return []string{"host.internal.example."}, nil
+	}
+	pred := &pktcls.IPv4MatchSourceHost{Host: "*.internal.example"}
+	pktcls.SetHostLookup(pred, lookup, time.Minute, time.Minute)
+	pkt := &layers.IPv4{SrcIP: net.IP{10, 0, 0, 1}}
+	// Evaluations do not wait for the pending lookup, and concurrent misses
+	// for the same address share it.
+	for i := 0; i < 10; i++ {
+		assert.False(t, pred.Eval(pkt))
+	}
+	close(release)
+	awaitHostLookup(t, pred, "10.0.0.1")
+	assert.True(t, pred.Eval(pkt))
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestIPv4MatchHostCache(t *testing.T) {
+	var calls atomic.Int32
+	lookup := func(_ context.Context, _ string) ([]string, error) {
+		calls.Add(1)
+		return []string{"host.internal.example."}, nil
+	}
+	pred := &pktcls.IPv4MatchSourceHost{Host: "*.internal.example"}
+	pktcls.SetHostLookup(pred, lookup, time.Minute, time.Second)
+	pkt := &layers.IPv4{SrcIP: net.IP{10, 0, 0, 1}}
+	pred.Eval(pkt)
+	awaitHostLookup(t, pred, "10.0.0.1")
+	for i := 0; i < 3; i++ {
+		assert.True(t, pred.Eval(pkt))
+	}
+	assert.Equal(t, int32(1), calls.Load())
+
+	// With a zero TTL, entries expire immediately. They still match while
+	// they are refreshed in the background.
+	calls.Store(0)
+	pktcls.SetHostLookup(pred, lookup, 0, time.Second)
+	pred.Eval(pkt)
+	awaitHostLookup(t, pred, "10.0.0.1")
+	assert.True(t, pred.Eval(pkt))
+	assert.Eventually(t, func() bool {
+		pred.Eval(pkt)
+		return calls.Load() >= 3
+	}, time.Second, time.Millisecond)
+}
+
+func TestIPv4MatchHostCacheLimits(t *testing.T) {
+	t.Run("concurrent lookups", func(t *testing.T) {
+		var calls atomic.Int32
+		release := make(chan struct{})
+		lookup := func(_ context.Context, _ string) ([]string, error) {
+			calls.Add(1)
+			<-release
+			return nil, nil
+		}
+		pred := &pktcls.IPv4MatchSourceHost{Host: "*"}
+		pktcls.SetHostLookup(pred, lookup, time.Minute, time.Minute)
+		for i := 0; i < 2*pktcls.HostMaxLookups; i++ {
+			pred.Eval(&layers.IPv4{SrcIP: net.IP{10, 0, 0, byte(i)}})
+		}
+		assert.Eventually(t, func() bool {
+			return calls.Load() == pktcls.HostMaxLookups
+		}, time.Second, time.Millisecond)
+		close(release)
+		assert.Eventually(t, func() bool {
+			return pktcls.HostCacheLen(pred) == pktcls.HostMaxLookups
+		}, time.Second, time.Millisecond)
+		assert.Equal(t, int32(pktcls.HostMaxLookups), calls.Load())
+	})
+	t.Run("eviction", func(t *testing.T) {
+		lookup := func(_ context.Context, _ string) ([]string, error) {
+			return nil, nil
+		}
+		pred := &pktcls.IPv4MatchSourceHost{Host: "*"}
+		pktcls.SetHostLookup(pred, lookup, time.Minute, time.Second)
+		// Entries are evicted one at a time once the cache is full, such that
+		// recent entries are kept.
+		for i := 0; i < pktcls.HostCacheSize+pktcls.HostMaxLookups; i++ {
+			ip := net.IP{10, 0, byte(i >> 8), byte(i)}
+			pred.Eval(&layers.IPv4{SrcIP: ip})
+			awaitHostLookup(t, pred, ip.String())
+			assert.LessOrEqual(t, pktcls.HostCacheLen(pred), pktcls.HostCacheSize)
+		}
+		assert.Equal(t, pktcls.HostCacheSize, pktcls.HostCacheLen(pred))
+	})
+}
+
+// awaitHostLookup waits until the lookup of addr by the resolver of pred has
+// completed.
+func awaitHostLookup(t *testing.T, pred pktcls.IPv4Predicate, addr string) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return pktcls.HostCached(pred, addr)
+	}, time.Second, time.Millisecond)
+}
+
+func TestIPv4MatchHostJSON(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		classes := pktcls.ClassMap{
+			"lab": pktcls.NewClass("lab", pktcls.NewCondAnyOf(
+				pktcls.NewCondIPv4(&pktcls.IPv4MatchSourceHost{Host: "*.internal.example"}),
+				pktcls.NewCondIPv4(&pktcls.IPv4MatchDestinationHost{Host: "db.example"}),
+			)),
+		}
+		raw, err := json.Marshal(classes)
+		require.NoError(t, err)
+		assert.Contains(t, string(raw), `{"MatchSourceHost":{"Host":"*.internal.example"}}`)
+		var parsed pktcls.ClassMap
+		require.NoError(t, json.Unmarshal(raw, &parsed))
+		assert.Equal(t, classes, parsed)
+	})
+	t.Run("bad pattern", func(t *testing.T) {
+		var p pktcls.IPv4MatchSourceHost
+		assert.Error(t, json.Unmarshal([]byte(`{"Host":"[.example"}`), &p))
+	})
+}
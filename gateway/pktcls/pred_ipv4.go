@@ -19,7 +19,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"strconv"
+	"strings"
 
+	"github.com/gopacket/gopacket"
 	"github.com/gopacket/gopacket/layers"
 
 	"github.com/scionproto/scion/pkg/private/serrors"
@@ -202,6 +205,65 @@ func (m *IPv4MatchDSCP) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+var _ IPv4Predicate = (*IPv4MatchECN)(nil)
+
+// IPv4MatchECN checks whether the ECN subset of the TOS field matches.
+type IPv4MatchECN struct {
+	ECN uint8
+}
+
+func (m *IPv4MatchECN) Type() string {
+	return TypeIPv4MatchECN
+}
+
+func (m *IPv4MatchECN) Eval(p *layers.IPv4) bool {
+	return m.ECN == p.TOS&0x3
+}
+
+func (m *IPv4MatchECN) String() string {
+	return fmt.Sprintf("ecn=%s", ecnName(m.ECN))
+}
+
+func (m *IPv4MatchECN) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		jsonContainer{
+			"ECN": ecnName(m.ECN),
+		},
+	)
+}
+
+func (m *IPv4MatchECN) UnmarshalJSON(b []byte) error {
+	s, err := unmarshalStringField(b, TypeIPv4MatchECN, "ECN")
+	if err != nil {
+		return err
+	}
+	n, err := ecnNameToValue(s)
+	if err != nil {
+		return err
+	}
+	m.ECN = n
+	return nil
+}
+
+// ecnNames are the names of the ECN codepoints as defined in RFC 3168.
+var ecnNames = [4]string{"Not-ECT", "ECT(1)", "ECT(0)", "CE"}
+
+func ecnName(v uint8) string {
+	if int(v) < len(ecnNames) {
+		return ecnNames[v]
+	}
+	return fmt.Sprintf("%#x", v)
+}
+
+func ecnNameToValue(name string) (uint8, error) {
+	for v, n := range ecnNames {
+		if strings.EqualFold(name, n) {
+			return uint8(v), nil
+		}
+	}
+	return 0, serrors.New("unknown ECN codepoint name", "name", name)
+}
+
 var _ IPv4Predicate = (*IPv4MatchProtocol)(nil)
 
 // IPv4Matchprotocol checks whether the the L4 protocol matches.
@@ -218,13 +280,13 @@ func (m *IPv4MatchProtocol) Eval(p *layers.IPv4) bool {
 }
 
 func (m *IPv4MatchProtocol) String() string {
-	return fmt.Sprintf("protocol=%s", layers.IPProtocolMetadata[m.Protocol].Name)
+	return fmt.Sprintf("protocol=%s", protocolName(m.Protocol))
 }
 
 func (m *IPv4MatchProtocol) MarshalJSON() ([]byte, error) {
 	return json.Marshal(
 		jsonContainer{
-			"Protocol": layers.IPProtocolMetadata[m.Protocol].Name,
+			"Protocol": protocolName(m.Protocol),
 		},
 	)
 }
@@ -234,10 +296,222 @@ func (m *IPv4MatchProtocol) UnmarshalJSON(b []byte) error {
 	if err != nil {
 		return err
 	}
-	n, err := protocolNameToNumber(s)
+	n, err := parseProtocol(s)
 	if err != nil {
 		return err
 	}
 	m.Protocol = n
 	return nil
 }
+
+// protocolName returns the name of the IP protocol, or its decimal number if
+// the protocol has no name.
+func protocolName(number uint8) string {
+	if name := layers.IPProtocolMetadata[number].Name; name != "" {
+		return name
+	}
+	return strconv.Itoa(int(number))
+}
+
+// parseProtocol parses an IP protocol name or decimal number.
+func parseProtocol(s string) (uint8, error) {
+	if s != "" && strings.Trim(s, "0123456789") == "" {
+		n, err := strconv.ParseUint(s, 10, 8)
+		if err != nil {
+			return 0, serrors.Wrap("invalid IP protocol number", err, "protocol", s)
+		}
+		return uint8(n), nil
+	}
+	return protocolNameToNumber(s)
+}
+
+var _ IPv4Predicate = (*IPv4MatchTTL)(nil)
+
+// IPv4MatchTTL compares the TTL field to TTL. Only the CmpEq, CmpLt and CmpGt
+// operators are supported.
+type IPv4MatchTTL struct {
+	Op  CmpOp
+	TTL uint8
+}
+
+func (m *IPv4MatchTTL) Type() string {
+	return TypeIPv4MatchTTL
+}
+
+func (m *IPv4MatchTTL) Eval(p *layers.IPv4) bool {
+	if m.Op == CmpRange {
+		return false
+	}
+	return m.Op.eval(uint64(p.TTL), uint64(m.TTL), 0)
+}
+
+func (m *IPv4MatchTTL) String() string {
+	return m.Op.format("ttl", uint64(m.TTL), 0)
+}
+
+func (m *IPv4MatchTTL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		jsonContainer{
+			"Op":  m.Op,
+			"TTL": strconv.Itoa(int(m.TTL)),
+		},
+	)
+}
+
+func (m *IPv4MatchTTL) UnmarshalJSON(b []byte) error {
+	s, err := unmarshalStringField(b, TypeIPv4MatchTTL, "Op")
+	if err != nil {
+		return err
+	}
+	var op CmpOp
+	if err := op.UnmarshalText([]byte(s)); err != nil {
+		return err
+	}
+	if op == CmpRange {
+		return serrors.New("Unsupported comparison operator", "name", TypeIPv4MatchTTL,
+			"op", op)
+	}
+	// Format is a decimal or 0x hex number in quoted string
+	i, err := unmarshalUintField(b, TypeIPv4MatchTTL, "TTL", 8)
+	if err != nil {
+		return err
+	}
+	m.Op = op
+	m.TTL = uint8(i)
+	return nil
+}
+
+var _ IPv4Predicate = (*IPv4MatchHasOptions)(nil)
+
+// ipv4OptionsAny is the JSON encoding of an IPv4MatchHasOptions predicate that
+// matches any option.
+const ipv4OptionsAny = "any"
+
+// IPv4MatchHasOptions checks whether the IPv4 header carries options, e.g.,
+// source routing options. If Any is set, packets with any option match,
+// otherwise only packets with an option of type OptionType.
+type IPv4MatchHasOptions struct {
+	Any        bool
+	OptionType uint8
+}
+
+func (m *IPv4MatchHasOptions) Type() string {
+	return TypeIPv4MatchHasOptions
+}
+
+func (m *IPv4MatchHasOptions) Eval(p *layers.IPv4) bool {
+	if m.Any {
+		return len(p.Options) > 0
+	}
+	for _, o := range p.Options {
+		if o.OptionType == m.OptionType {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *IPv4MatchHasOptions) String() string {
+	return fmt.Sprintf("options=%s", m.option())
+}
+
+func (m *IPv4MatchHasOptions) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		jsonContainer{
+			"Option": m.option(),
+		},
+	)
+}
+
+func (m *IPv4MatchHasOptions) option() string {
+	if m.Any {
+		return ipv4OptionsAny
+	}
+	return strconv.Itoa(int(m.OptionType))
+}
+
+func (m *IPv4MatchHasOptions) UnmarshalJSON(b []byte) error {
+	s, err := unmarshalStringField(b, TypeIPv4MatchHasOptions, "Option")
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(s, ipv4OptionsAny) {
+		*m = IPv4MatchHasOptions{Any: true}
+		return nil
+	}
+	// Format is a decimal or 0x hex number in quoted string
+	i, err := unmarshalUintField(b, TypeIPv4MatchHasOptions, "Option", 8)
+	if err != nil {
+		return err
+	}
+	*m = IPv4MatchHasOptions{OptionType: uint8(i)}
+	return nil
+}
+
+var _ IPv4Predicate = (*IPv4MatchChecksumValid)(nil)
+
+// IPv4MatchChecksumValid checks whether the IPv4 header checksum is valid. If
+// Valid is false, packets with an invalid checksum match instead.
+//
+// The checksum is recomputed over the header bytes the packet was decoded
+// from, instead of comparing it to the parsed Checksum field, which gopacket
+// does not set for all headers. Packets that were not decoded from bytes are
+// serialized first.
+type IPv4MatchChecksumValid struct {
+	Valid bool
+}
+
+func (m *IPv4MatchChecksumValid) Type() string {
+	return TypeIPv4MatchChecksumValid
+}
+
+func (m *IPv4MatchChecksumValid) Eval(p *layers.IPv4) bool {
+	return ipv4ChecksumValid(p) == m.Valid
+}
+
+func (m *IPv4MatchChecksumValid) String() string {
+	if m.Valid {
+		return "checksum=valid"
+	}
+	return "checksum=invalid"
+}
+
+func (m *IPv4MatchChecksumValid) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		jsonContainer{
+			"Valid": m.Valid,
+		},
+	)
+}
+
+func (m *IPv4MatchChecksumValid) UnmarshalJSON(b []byte) error {
+	var v struct {
+		Valid *bool
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return serrors.Wrap("Unable to parse MatchChecksumValid operand", err)
+	}
+	if v.Valid == nil {
+		return serrors.New("Bool field missing", "name", TypeIPv4MatchChecksumValid,
+			"field", "Valid")
+	}
+	m.Valid = *v.Valid
+	return nil
+}
+
+// ipv4ChecksumValid reports whether the checksum of the IPv4 header is valid,
+// i.e., whether the one's complement sum over the header including the
+// checksum is zero.
+func ipv4ChecksumValid(p *layers.IPv4) bool {
+	hdr := p.Contents
+	if len(hdr) < 20 {
+		// Serialize a copy, serialization converts the addresses in place.
+		cp := *p
+		buf := gopacket.NewSerializeBuffer()
+		if err := cp.SerializeTo(buf, gopacket.SerializeOptions{}); err != nil {
+			return false
+		}
+		hdr = buf.Bytes()
+	}
+	return gopacket.FoldChecksum(gopacket.ComputeChecksum(hdr, 0)) == 0
+}
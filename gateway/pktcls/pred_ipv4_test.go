@@ -0,0 +1,142 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls_test
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/gateway/pktcls"
+)
+
+// BenchmarkIPv4MatchSource compares the evaluation of host rules, i.e., /32
+// networks, to the evaluation of rules that match a larger network.
+func BenchmarkIPv4MatchSource(b *testing.B) {
+	pkt := &layers.IPv4{SrcIP: net.IP{10, 1, 2, 3}}
+	for _, s := range []string{"10.1.2.3/32", "10.1.2.0/24"} {
+		_, n, err := net.ParseCIDR(s)
+		require.NoError(b, err)
+		m := &pktcls.IPv4MatchSource{Net: n}
+		b.Run(s, func(b *testing.B) {
+			for b.Loop() {
+				m.Eval(pkt)
+			}
+		})
+	}
+}
+
+func TestIPv4MatchChecksumValid(t *testing.T) {
+	newPacket := func() *layers.IPv4 {
+		return &layers.IPv4{
+			Version:  4,
+			TTL:      64,
+			Protocol: layers.IPProtocolUDP,
+			SrcIP:    net.IP{10, 0, 0, 1},
+			DstIP:    net.IP{10, 0, 0, 2},
+		}
+	}
+	serialize := func(t *testing.T, ip *layers.IPv4) []byte {
+		buf := gopacket.NewSerializeBuffer()
+		require.NoError(t, ip.SerializeTo(buf,
+			gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}))
+		return buf.Bytes()
+	}
+	decode := func(t *testing.T, raw []byte) *layers.IPv4 {
+		var ip layers.IPv4
+		require.NoError(t, ip.DecodeFromBytes(raw, gopacket.NilDecodeFeedback))
+		return &ip
+	}
+
+	testCases := map[string]struct {
+		Packet func(t *testing.T) *layers.IPv4
+		Valid  bool
+	}{
+		"decoded": {
+			Packet: func(t *testing.T) *layers.IPv4 {
+				return decode(t, serialize(t, newPacket()))
+			},
+			Valid: true,
+		},
+		"decoded corrupted": {
+			Packet: func(t *testing.T) *layers.IPv4 {
+				raw := serialize(t, newPacket())
+				raw[8]--
+				return decode(t, raw)
+			},
+			Valid: false,
+		},
+		"decoded with end of options": {
+			// gopacket does not set the Checksum field if the options end
+			// with an end of options list option.
+			Packet: func(t *testing.T) *layers.IPv4 {
+				ip := newPacket()
+				ip.Options = []layers.IPv4Option{
+					{OptionType: 1, OptionLength: 1},
+					{OptionType: 1, OptionLength: 1},
+					{OptionType: 1, OptionLength: 1},
+					{OptionType: 0, OptionLength: 1},
+				}
+				return decode(t, serialize(t, ip))
+			},
+			Valid: true,
+		},
+		"constructed": {
+			Packet: func(t *testing.T) *layers.IPv4 {
+				ip := newPacket()
+				serialize(t, ip)
+				return ip
+			},
+			Valid: true,
+		},
+		"constructed wrong checksum": {
+			Packet: func(t *testing.T) *layers.IPv4 {
+				ip := newPacket()
+				serialize(t, ip)
+				ip.Checksum++
+				return ip
+			},
+			Valid: false,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			pkt := tc.Packet(t)
+			assert.Equal(t, tc.Valid, (&pktcls.IPv4MatchChecksumValid{Valid: true}).Eval(pkt))
+			assert.Equal(t, !tc.Valid, (&pktcls.IPv4MatchChecksumValid{Valid: false}).Eval(pkt))
+		})
+	}
+}
+
+func TestIPv4MatchChecksumValidJSON(t *testing.T) {
+	for _, m := range []pktcls.IPv4MatchChecksumValid{{Valid: true}, {Valid: false}} {
+		raw, err := json.Marshal(&m)
+		require.NoError(t, err)
+		var got pktcls.IPv4MatchChecksumValid
+		require.NoError(t, json.Unmarshal(raw, &got), string(raw))
+		assert.Equal(t, m, got)
+	}
+	assert.Equal(t, "checksum=valid", (&pktcls.IPv4MatchChecksumValid{Valid: true}).String())
+	assert.Equal(t, "checksum=invalid", (&pktcls.IPv4MatchChecksumValid{}).String())
+	for _, raw := range []string{`{}`, `{"Valid":"true"}`} {
+		var m pktcls.IPv4MatchChecksumValid
+		assert.Error(t, json.Unmarshal([]byte(raw), &m), raw)
+	}
+}
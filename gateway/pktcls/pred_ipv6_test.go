@@ -0,0 +1,169 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls_test
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/gateway/pktcls"
+)
+
+func TestIPv6Match(t *testing.T) {
+	mustNet := func(s string) *net.IPNet {
+		_, n, err := net.ParseCIDR(s)
+		require.NoError(t, err)
+		return n
+	}
+	pkt := &layers.IPv6{
+		SrcIP:        net.ParseIP("2001:db8:1::1"),
+		DstIP:        net.ParseIP("2001:db8:2::2"),
+		TrafficClass: 0xb8,
+		FlowLabel:    74565,
+	}
+
+	testCases := map[string]struct {
+		Packet  gopacket.Layer
+		Pred    pktcls.IPv6Predicate
+		ExpEval bool
+	}{
+		"source matches": {
+			Packet:  pkt,
+			Pred:    &pktcls.IPv6MatchSource{Net: mustNet("2001:db8:1::/48")},
+			ExpEval: true,
+		},
+		"source does not match": {
+			Packet:  pkt,
+			Pred:    &pktcls.IPv6MatchSource{Net: mustNet("2001:db8:2::/48")},
+			ExpEval: false,
+		},
+		"destination matches": {
+			Packet:  pkt,
+			Pred:    &pktcls.IPv6MatchDestination{Net: mustNet("2001:db8:2::/48")},
+			ExpEval: true,
+		},
+		"destination does not match": {
+			Packet:  pkt,
+			Pred:    &pktcls.IPv6MatchDestination{Net: mustNet("2001:db8:1::/48")},
+			ExpEval: false,
+		},
+		"traffic class matches": {
+			Packet:  pkt,
+			Pred:    &pktcls.IPv6MatchTrafficClass{TrafficClass: 0xb8},
+			ExpEval: true,
+		},
+		"traffic class differs in ECN bits": {
+			Packet:  pkt,
+			Pred:    &pktcls.IPv6MatchTrafficClass{TrafficClass: 0xb9},
+			ExpEval: false,
+		},
+		"flow label matches": {
+			Packet:  pkt,
+			Pred:    &pktcls.IPv6MatchFlowLabel{FlowLabel: 74565},
+			ExpEval: true,
+		},
+		"flow label does not match": {
+			Packet:  pkt,
+			Pred:    &pktcls.IPv6MatchFlowLabel{FlowLabel: 74566},
+			ExpEval: false,
+		},
+		"zero flow label matches any": {
+			Packet:  pkt,
+			Pred:    &pktcls.IPv6MatchFlowLabel{},
+			ExpEval: true,
+		},
+		"IPv4 packet": {
+			Packet: &layers.IPv4{
+				SrcIP: net.IP{10, 0, 0, 1},
+				DstIP: net.IP{10, 0, 0, 2},
+			},
+			Pred:    &pktcls.IPv6MatchSource{Net: mustNet("::/0")},
+			ExpEval: false,
+		},
+		"nil packet": {
+			Pred:    &pktcls.IPv6MatchSource{Net: mustNet("::/0")},
+			ExpEval: false,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cond := pktcls.NewCondIPv6(tc.Pred)
+			assert.Equal(t, tc.ExpEval, cond.Eval(tc.Packet))
+		})
+	}
+}
+
+func TestIPv6MatchJSON(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		_, src, err := net.ParseCIDR("2001:db8:1::/48")
+		require.NoError(t, err)
+		_, dst, err := net.ParseCIDR("2001:db8:2::/48")
+		require.NoError(t, err)
+		classes := pktcls.ClassMap{}
+		for _, m := range []pktcls.IPv6Predicate{
+			&pktcls.IPv6MatchSource{Net: src},
+			&pktcls.IPv6MatchDestination{Net: dst},
+			&pktcls.IPv6MatchTrafficClass{TrafficClass: 0xb8},
+			&pktcls.IPv6MatchFlowLabel{FlowLabel: 74565},
+		} {
+			classes[m.String()] = pktcls.NewClass(m.String(), pktcls.NewCondIPv6(m))
+		}
+		raw, err := json.Marshal(classes)
+		require.NoError(t, err)
+		assert.Contains(t, string(raw),
+			`{"CondIPv6":{"MatchIPv6Source":{"Net":"2001:db8:1::/48"}}}`)
+		assert.Contains(t, string(raw),
+			`{"CondIPv6":{"MatchIPv6Destination":{"Net":"2001:db8:2::/48"}}}`)
+		assert.Contains(t, string(raw),
+			`{"CondIPv6":{"MatchIPv6TrafficClass":{"TrafficClass":"0xb8"}}}`)
+		assert.Contains(t, string(raw),
+			`{"CondIPv6":{"MatchIPv6FlowLabel":{"FlowLabel":"74565"}}}`)
+		var parsed pktcls.ClassMap
+		require.NoError(t, json.Unmarshal(raw, &parsed))
+		assert.Equal(t, classes, parsed)
+	})
+	t.Run("invalid network", func(t *testing.T) {
+		var m pktcls.IPv6MatchSource
+		assert.Error(t, json.Unmarshal([]byte(`{"Net":"2001:db8::/129"}`), &m))
+	})
+	t.Run("IPv4 network", func(t *testing.T) {
+		var m pktcls.IPv6MatchDestination
+		assert.ErrorContains(t, json.Unmarshal([]byte(`{"Net":"10.0.0.0/8"}`), &m),
+			"not an IPv6 network")
+	})
+	t.Run("flow label out of range", func(t *testing.T) {
+		var m pktcls.IPv6MatchFlowLabel
+		assert.Error(t, json.Unmarshal([]byte(`{"FlowLabel":"1048576"}`), &m))
+	})
+	t.Run("traffic class out of range", func(t *testing.T) {
+		var m pktcls.IPv6MatchTrafficClass
+		assert.Error(t, json.Unmarshal([]byte(`{"TrafficClass":"0x100"}`), &m))
+	})
+	t.Run("string", func(t *testing.T) {
+		_, n, err := net.ParseCIDR("2001:db8::/32")
+		require.NoError(t, err)
+		assert.Equal(t, "src6=2001:db8::/32", (&pktcls.IPv6MatchSource{Net: n}).String())
+		assert.Equal(t, "dst6=2001:db8::/32", (&pktcls.IPv6MatchDestination{Net: n}).String())
+		assert.Equal(t, "tc6=0xb8", (&pktcls.IPv6MatchTrafficClass{TrafficClass: 0xb8}).String())
+		assert.Equal(t, "flowlabel6=74565",
+			(&pktcls.IPv6MatchFlowLabel{FlowLabel: 74565}).String())
+	})
+}
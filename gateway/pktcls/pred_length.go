@@ -0,0 +1,170 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gopacket/gopacket/layers"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+// CmpOp is the operator that compares a numeric packet field to the value of
+// a predicate.
+type CmpOp uint8
+
+const (
+	// CmpEq matches if the field is equal to the value.
+	CmpEq CmpOp = iota
+	// CmpLt matches if the field is less than the value.
+	CmpLt
+	// CmpGt matches if the field is greater than the value.
+	CmpGt
+	// CmpRange matches if the field is in the inclusive range between the
+	// value and the upper bound.
+	CmpRange
+)
+
+var cmpOpSymbols = map[CmpOp]string{
+	CmpEq:    "==",
+	CmpLt:    "<",
+	CmpGt:    ">",
+	CmpRange: "range",
+}
+
+func (o CmpOp) String() string {
+	if s, ok := cmpOpSymbols[o]; ok {
+		return s
+	}
+	return fmt.Sprintf("CmpOp(%d)", uint8(o))
+}
+
+func (o CmpOp) MarshalText() ([]byte, error) {
+	s, ok := cmpOpSymbols[o]
+	if !ok {
+		return nil, serrors.New("Unknown comparison operator", "op", uint8(o))
+	}
+	return []byte(s), nil
+}
+
+func (o *CmpOp) UnmarshalText(b []byte) error {
+	for op, s := range cmpOpSymbols {
+		if s == string(b) {
+			*o = op
+			return nil
+		}
+	}
+	return serrors.New("Unknown comparison operator", "op", string(b))
+}
+
+// eval compares v to value, and to the upper bound high for CmpRange.
+// Unknown operators never match.
+func (o CmpOp) eval(v, value, high uint64) bool {
+	switch o {
+	case CmpEq:
+		return v == value
+	case CmpLt:
+		return v < value
+	case CmpGt:
+		return v > value
+	case CmpRange:
+		return value <= v && v <= high
+	default:
+		return false
+	}
+}
+
+// format renders the comparison of the field name, e.g., "len>1500" or
+// "len=1000-1500".
+func (o CmpOp) format(name string, value, high uint64) string {
+	switch o {
+	case CmpEq:
+		return fmt.Sprintf("%s=%d", name, value)
+	case CmpRange:
+		return fmt.Sprintf("%s=%d-%d", name, value, high)
+	default:
+		return fmt.Sprintf("%s%s%d", name, o, value)
+	}
+}
+
+var _ IPv4Predicate = (*IPv4MatchLength)(nil)
+
+// IPv4MatchLength compares the total length of the IPv4 packet to Length. For
+// CmpRange, the length must be between Length and MaxLength, inclusive.
+type IPv4MatchLength struct {
+	Op        CmpOp
+	Length    uint16
+	MaxLength uint16
+}
+
+func (m *IPv4MatchLength) Type() string {
+	return TypeIPv4MatchLength
+}
+
+func (m *IPv4MatchLength) Eval(p *layers.IPv4) bool {
+	return m.Op.eval(uint64(p.Length), uint64(m.Length), uint64(m.MaxLength))
+}
+
+func (m *IPv4MatchLength) String() string {
+	return m.Op.format("len", uint64(m.Length), uint64(m.MaxLength))
+}
+
+func (m *IPv4MatchLength) MarshalJSON() ([]byte, error) {
+	c := jsonContainer{
+		"Op":     m.Op,
+		"Length": m.Length,
+	}
+	if m.Op == CmpRange {
+		c["MaxLength"] = m.MaxLength
+	}
+	return json.Marshal(c)
+}
+
+func (m *IPv4MatchLength) UnmarshalJSON(b []byte) error {
+	var v struct {
+		Op        *CmpOp
+		Length    *uint16
+		MaxLength *uint16
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return serrors.Wrap("Unable to parse "+TypeIPv4MatchLength+" operand", err)
+	}
+	if v.Op == nil {
+		return serrors.New("Field missing", "name", TypeIPv4MatchLength, "field", "Op")
+	}
+	if v.Length == nil {
+		return serrors.New("Field missing", "name", TypeIPv4MatchLength, "field", "Length")
+	}
+	var maxLength uint16
+	switch {
+	case *v.Op == CmpRange && v.MaxLength == nil:
+		return serrors.New("Field missing", "name", TypeIPv4MatchLength, "field", "MaxLength")
+	case *v.Op == CmpRange:
+		maxLength = *v.MaxLength
+		if maxLength < *v.Length {
+			return serrors.New("MaxLength is less than Length", "name", TypeIPv4MatchLength,
+				"length", *v.Length, "max_length", maxLength)
+		}
+	case v.MaxLength != nil:
+		return serrors.New("MaxLength is only allowed for range", "name", TypeIPv4MatchLength,
+			"op", *v.Op)
+	}
+	m.Op = *v.Op
+	m.Length = *v.Length
+	m.MaxLength = maxLength
+	return nil
+}
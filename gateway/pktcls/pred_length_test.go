@@ -0,0 +1,150 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gopacket/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/gateway/pktcls"
+)
+
+func TestIPv4MatchLength(t *testing.T) {
+	testCases := map[string]struct {
+		Match   pktcls.IPv4MatchLength
+		Length  uint16
+		ExpEval bool
+	}{
+		"equal": {
+			Match:   pktcls.IPv4MatchLength{Op: pktcls.CmpEq, Length: 1500},
+			Length:  1500,
+			ExpEval: true,
+		},
+		"not equal": {
+			Match:   pktcls.IPv4MatchLength{Op: pktcls.CmpEq, Length: 1500},
+			Length:  1501,
+			ExpEval: false,
+		},
+		"less": {
+			Match:   pktcls.IPv4MatchLength{Op: pktcls.CmpLt, Length: 1500},
+			Length:  1499,
+			ExpEval: true,
+		},
+		"not less": {
+			Match:   pktcls.IPv4MatchLength{Op: pktcls.CmpLt, Length: 1500},
+			Length:  1500,
+			ExpEval: false,
+		},
+		"greater": {
+			Match:   pktcls.IPv4MatchLength{Op: pktcls.CmpGt, Length: 1500},
+			Length:  9000,
+			ExpEval: true,
+		},
+		"not greater": {
+			Match:   pktcls.IPv4MatchLength{Op: pktcls.CmpGt, Length: 1500},
+			Length:  1500,
+			ExpEval: false,
+		},
+		"range lower bound": {
+			Match:   pktcls.IPv4MatchLength{Op: pktcls.CmpRange, Length: 1000, MaxLength: 1500},
+			Length:  1000,
+			ExpEval: true,
+		},
+		"range upper bound": {
+			Match:   pktcls.IPv4MatchLength{Op: pktcls.CmpRange, Length: 1000, MaxLength: 1500},
+			Length:  1500,
+			ExpEval: true,
+		},
+		"outside range": {
+			Match:   pktcls.IPv4MatchLength{Op: pktcls.CmpRange, Length: 1000, MaxLength: 1500},
+			Length:  1501,
+			ExpEval: false,
+		},
+		"unknown operator": {
+			Match:   pktcls.IPv4MatchLength{Op: pktcls.CmpOp(42), Length: 1500},
+			Length:  1500,
+			ExpEval: false,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cond := pktcls.NewCondIPv4(&tc.Match)
+			assert.Equal(t, tc.ExpEval, cond.Eval(&layers.IPv4{Length: tc.Length}))
+		})
+	}
+}
+
+func TestIPv4MatchLengthJSON(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		classes := pktcls.ClassMap{
+			"jumbo": pktcls.NewClass("jumbo", pktcls.NewCondIPv4(
+				&pktcls.IPv4MatchLength{Op: pktcls.CmpGt, Length: 1500},
+			)),
+			"medium": pktcls.NewClass("medium", pktcls.NewCondIPv4(
+				&pktcls.IPv4MatchLength{Op: pktcls.CmpRange, Length: 576, MaxLength: 1500},
+			)),
+		}
+		raw, err := json.Marshal(classes)
+		require.NoError(t, err)
+		var parsed pktcls.ClassMap
+		require.NoError(t, json.Unmarshal(raw, &parsed))
+		assert.Equal(t, classes, parsed)
+	})
+	t.Run("marshal", func(t *testing.T) {
+		raw, err := json.Marshal(&pktcls.IPv4MatchLength{Op: pktcls.CmpGt, Length: 1500})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Op":">","Length":1500}`, string(raw))
+		raw, err = json.Marshal(
+			&pktcls.IPv4MatchLength{Op: pktcls.CmpRange, Length: 576, MaxLength: 1500},
+		)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Op":"range","Length":576,"MaxLength":1500}`, string(raw))
+	})
+	t.Run("parse", func(t *testing.T) {
+		var m pktcls.IPv4MatchLength
+		require.NoError(t, json.Unmarshal([]byte(`{"Op":"==","Length":1280}`), &m))
+		assert.Equal(t, pktcls.IPv4MatchLength{Op: pktcls.CmpEq, Length: 1280}, m)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for name, raw := range map[string]string{
+			"unknown operator":  `{"Op":">=","Length":1500}`,
+			"missing operator":  `{"Length":1500}`,
+			"missing length":    `{"Op":"<"}`,
+			"length too large":  `{"Op":"<","Length":65536}`,
+			"missing max":       `{"Op":"range","Length":1000}`,
+			"inverted range":    `{"Op":"range","Length":1500,"MaxLength":1000}`,
+			"max without range": `{"Op":"<","Length":1000,"MaxLength":1500}`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				var m pktcls.IPv4MatchLength
+				assert.Error(t, json.Unmarshal([]byte(raw), &m))
+			})
+		}
+	})
+	t.Run("string", func(t *testing.T) {
+		for want, m := range map[string]pktcls.IPv4MatchLength{
+			"len=1500":      {Op: pktcls.CmpEq, Length: 1500},
+			"len<1500":      {Op: pktcls.CmpLt, Length: 1500},
+			"len>1500":      {Op: pktcls.CmpGt, Length: 1500},
+			"len=1000-1500": {Op: pktcls.CmpRange, Length: 1000, MaxLength: 1500},
+		} {
+			assert.Equal(t, want, m.String())
+		}
+	})
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+var _ IPv4Predicate = (*IPv4MatchPayload)(nil)
+
+// IPv4MatchPayload checks whether the UDP or TCP payload contains the pattern
+// at the offset. Packets with other L4 protocols and packets whose payload is
+// too short do not match.
+type IPv4MatchPayload struct {
+	Offset  uint16
+	Pattern []byte
+}
+
+func (m *IPv4MatchPayload) Type() string {
+	return TypeIPv4MatchPayload
+}
+
+func (m *IPv4MatchPayload) Eval(p *layers.IPv4) bool {
+	if len(m.Pattern) == 0 {
+		return false
+	}
+	payload, ok := l4Payload(p)
+	if !ok {
+		return false
+	}
+	end := int(m.Offset) + len(m.Pattern)
+	if len(payload) < end {
+		return false
+	}
+	return bytes.Equal(payload[m.Offset:end], m.Pattern)
+}
+
+func (m *IPv4MatchPayload) String() string {
+	return fmt.Sprintf("payload[%d]=%s", m.Offset, m.toHex())
+}
+
+func (m *IPv4MatchPayload) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		jsonContainer{
+			"Offset":  m.Offset,
+			"Pattern": m.toHex(),
+		},
+	)
+}
+
+func (m *IPv4MatchPayload) UnmarshalJSON(b []byte) error {
+	var v struct {
+		Offset  uint16
+		Pattern string
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return serrors.Wrap("Unable to parse "+TypeIPv4MatchPayload+" operand", err)
+	}
+	hexPattern, ok := strings.CutPrefix(v.Pattern, "0x")
+	if !ok {
+		return serrors.New("Pattern is not a 0x hex string",
+			"name", TypeIPv4MatchPayload, "pattern", v.Pattern)
+	}
+	pattern, err := hex.DecodeString(hexPattern)
+	if err != nil {
+		return serrors.Wrap("Unable to parse pattern", err,
+			"name", TypeIPv4MatchPayload, "pattern", v.Pattern)
+	}
+	if len(pattern) == 0 {
+		return serrors.New("Pattern is empty", "name", TypeIPv4MatchPayload)
+	}
+	m.Offset = v.Offset
+	m.Pattern = pattern
+	return nil
+}
+
+func (m *IPv4MatchPayload) toHex() string {
+	return "0x" + hex.EncodeToString(m.Pattern)
+}
+
+// l4Payload returns the payload of the UDP or TCP packet carried in the IPv4
+// packet.
+func l4Payload(p *layers.IPv4) ([]byte, bool) {
+	switch p.NextLayerType() {
+	case layers.LayerTypeUDP:
+		udp := &layers.UDP{}
+		if err := udp.DecodeFromBytes(p.LayerPayload(), gopacket.NilDecodeFeedback); err != nil {
+			return nil, false
+		}
+		return udp.LayerPayload(), true
+	case layers.LayerTypeTCP:
+		tcp := &layers.TCP{}
+		if err := tcp.DecodeFromBytes(p.LayerPayload(), gopacket.NilDecodeFeedback); err != nil {
+			return nil, false
+		}
+		return tcp.LayerPayload(), true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls_test
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/gateway/pktcls"
+)
+
+func TestIPv4MatchPayload(t *testing.T) {
+	tlsHello := []byte{0x16, 0x03, 0x01, 0x02, 0x00, 0x01}
+
+	testCases := map[string]struct {
+		Packet  gopacket.Layer
+		Offset  uint16
+		Pattern []byte
+		ExpEval bool
+	}{
+		"udp prefix matches": {
+			Packet:  createL4Packet(t, layers.IPProtocolUDP, tlsHello),
+			Pattern: []byte{0x16, 0x03, 0x01},
+			ExpEval: true,
+		},
+		"tcp prefix matches": {
+			Packet:  createL4Packet(t, layers.IPProtocolTCP, tlsHello),
+			Pattern: []byte{0x16, 0x03, 0x01},
+			ExpEval: true,
+		},
+		"offset matches": {
+			Packet:  createL4Packet(t, layers.IPProtocolUDP, tlsHello),
+			Offset:  3,
+			Pattern: []byte{0x02, 0x00, 0x01},
+			ExpEval: true,
+		},
+		"different bytes": {
+			Packet:  createL4Packet(t, layers.IPProtocolUDP, tlsHello),
+			Pattern: []byte{0x16, 0x03, 0x03},
+			ExpEval: false,
+		},
+		"payload too short": {
+			Packet:  createL4Packet(t, layers.IPProtocolUDP, tlsHello),
+			Offset:  4,
+			Pattern: []byte{0x00, 0x01, 0x00},
+			ExpEval: false,
+		},
+		"offset beyond payload": {
+			Packet:  createL4Packet(t, layers.IPProtocolTCP, tlsHello),
+			Offset:  1000,
+			Pattern: []byte{0x16},
+			ExpEval: false,
+		},
+		"empty payload": {
+			Packet:  createL4Packet(t, layers.IPProtocolUDP, nil),
+			Pattern: []byte{0x16},
+			ExpEval: false,
+		},
+		"empty pattern": {
+			Packet:  createL4Packet(t, layers.IPProtocolUDP, tlsHello),
+			ExpEval: false,
+		},
+		"other protocol": {
+			Packet:  createL4Packet(t, layers.IPProtocolICMPv4, tlsHello),
+			Pattern: []byte{0x16},
+			ExpEval: false,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cond := pktcls.NewCondIPv4(&pktcls.IPv4MatchPayload{
+				Offset:  tc.Offset,
+				Pattern: tc.Pattern,
+			})
+			assert.Equal(t, tc.ExpEval, cond.Eval(tc.Packet))
+		})
+	}
+}
+
+func TestIPv4MatchPayloadJSON(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		m := &pktcls.IPv4MatchPayload{Offset: 2, Pattern: []byte{0x16, 0x03, 0x01}}
+		classes := pktcls.ClassMap{
+			"tls": pktcls.NewClass("tls", pktcls.NewCondIPv4(m)),
+		}
+		raw, err := json.Marshal(classes)
+		require.NoError(t, err)
+		assert.Contains(t, string(raw),
+			`{"CondIPv4":{"MatchPayload":{"Offset":2,"Pattern":"0x160301"}}}`)
+		var parsed pktcls.ClassMap
+		require.NoError(t, json.Unmarshal(raw, &parsed))
+		assert.Equal(t, classes, parsed)
+	})
+	t.Run("parse", func(t *testing.T) {
+		var m pktcls.IPv4MatchPayload
+		require.NoError(t, json.Unmarshal([]byte(`{"Offset":0,"Pattern":"0x160301"}`), &m))
+		assert.Equal(t, pktcls.IPv4MatchPayload{Pattern: []byte{0x16, 0x03, 0x01}}, m)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for name, raw := range map[string]string{
+			"missing prefix":   `{"Offset":0,"Pattern":"160301"}`,
+			"invalid hex":      `{"Offset":0,"Pattern":"0x1g"}`,
+			"odd length":       `{"Offset":0,"Pattern":"0x160"}`,
+			"empty pattern":    `{"Offset":0,"Pattern":"0x"}`,
+			"missing pattern":  `{"Offset":0}`,
+			"negative offset":  `{"Offset":-1,"Pattern":"0x16"}`,
+			"offset too large": `{"Offset":65536,"Pattern":"0x16"}`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				var m pktcls.IPv4MatchPayload
+				assert.Error(t, json.Unmarshal([]byte(raw), &m))
+			})
+		}
+	})
+	t.Run("string", func(t *testing.T) {
+		m := &pktcls.IPv4MatchPayload{Offset: 2, Pattern: []byte{0x16, 0x03, 0x01}}
+		assert.Equal(t, "payload[2]=0x160301", m.String())
+	})
+}
+
+func createL4Packet(t *testing.T, proto layers.IPProtocol, payload []byte) gopacket.Layer {
+	t.Helper()
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    net.IP{192, 168, 14, 3},
+		DstIP:    net.IP{192, 168, 14, 2},
+		Protocol: proto,
+	}
+	l := []gopacket.SerializableLayer{ip}
+	switch proto {
+	case layers.IPProtocolUDP:
+		l = append(l, &layers.UDP{SrcPort: 40000, DstPort: 443})
+	case layers.IPProtocolTCP:
+		l = append(l, &layers.TCP{SrcPort: 40000, DstPort: 443, DataOffset: 5})
+	}
+	l = append(l, gopacket.Payload(payload))
+	buf := gopacket.NewSerializeBuffer()
+	err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true}, l...)
+	require.NoError(t, err)
+	pkt := &layers.IPv4{}
+	require.NoError(t, pkt.DecodeFromBytes(buf.Bytes(), gopacket.NilDecodeFeedback))
+	return pkt
+}
@@ -0,0 +1,337 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+
+	"github.com/scionproto/scion/pkg/addr"
+	"github.com/scionproto/scion/pkg/private/serrors"
+	"github.com/scionproto/scion/pkg/slayers"
+	"github.com/scionproto/scion/pkg/slayers/path"
+	"github.com/scionproto/scion/pkg/slayers/path/empty"
+	"github.com/scionproto/scion/pkg/slayers/path/epic"
+	"github.com/scionproto/scion/pkg/slayers/path/onehop"
+	"github.com/scionproto/scion/pkg/slayers/path/scion"
+)
+
+// SCIONPredicate describes a single test on various SCION header fields.
+type SCIONPredicate interface {
+	// Eval returns true if the SCION packet matched the predicate
+	Eval(*slayers.SCION) bool
+	Typer
+	fmt.Stringer
+}
+
+var _ Cond = MatchIsSCION{}
+
+// MatchIsSCION conditions return true if the packet is a SCION packet. This is
+// the case if the evaluated layer is a SCION layer, or if it is an IPv4 packet
+// that carries a decodable SCION header in UDP on a port that is registered
+// for SCION (see layers.RegisterUDPPortLayerType).
+//
+// MatchIsSCION can be used as guard in front of SCION specific conditions, to
+// separate SCION from legacy IP traffic.
+type MatchIsSCION struct{}
+
+func (c MatchIsSCION) Eval(v gopacket.Layer) bool {
+	_, ok := decodeSCION(v)
+	return ok
+}
+
+func (c MatchIsSCION) Type() string {
+	return TypeMatchIsSCION
+}
+
+func (c MatchIsSCION) String() string {
+	return "isscion"
+}
+
+var _ SCIONPredicate = (*SCIONMatchPathType)(nil)
+
+// pathTypeNames are the symbolic names of the path types that can be matched.
+var pathTypeNames = map[path.Type]string{
+	empty.PathType:  "empty",
+	scion.PathType:  "scion",
+	onehop.PathType: "onehop",
+	epic.PathType:   "epic",
+}
+
+// SCIONMatchPathType checks whether the path type of the SCION header matches.
+type SCIONMatchPathType struct {
+	PathType path.Type
+}
+
+func (m *SCIONMatchPathType) Type() string {
+	return TypeSCIONMatchPathType
+}
+
+func (m *SCIONMatchPathType) Eval(s *slayers.SCION) bool {
+	return s.PathType == m.PathType
+}
+
+func (m *SCIONMatchPathType) String() string {
+	return fmt.Sprintf("pathtype=%s", m.name())
+}
+
+func (m *SCIONMatchPathType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		jsonContainer{
+			"PathType": m.name(),
+		},
+	)
+}
+
+func (m *SCIONMatchPathType) UnmarshalJSON(b []byte) error {
+	s, err := unmarshalStringField(b, TypeSCIONMatchPathType, "PathType")
+	if err != nil {
+		return err
+	}
+	// The names are matched case-insensitively, so that the spelling of the
+	// path type descriptions, e.g., "OneHop", is accepted, too.
+	for t, name := range pathTypeNames {
+		if strings.EqualFold(name, s) {
+			m.PathType = t
+			return nil
+		}
+	}
+	// Path types without a symbolic name are marshaled as their number.
+	if n, err := strconv.ParseUint(s, 10, 8); err == nil {
+		m.PathType = path.Type(n)
+		return nil
+	}
+	return serrors.New("Unknown path type", "path_type", s)
+}
+
+func (m *SCIONMatchPathType) name() string {
+	if name, ok := pathTypeNames[m.PathType]; ok {
+		return name
+	}
+	return fmt.Sprintf("%d", m.PathType)
+}
+
+var _ SCIONPredicate = (*SCIONMatchTrafficClass)(nil)
+
+// SCIONMatchTrafficClass checks whether the traffic class field of the SCION
+// header matches.
+type SCIONMatchTrafficClass struct {
+	TrafficClass uint8
+}
+
+func (m *SCIONMatchTrafficClass) Type() string {
+	return TypeSCIONMatchTrafficClass
+}
+
+func (m *SCIONMatchTrafficClass) Eval(s *slayers.SCION) bool {
+	return m.TrafficClass == s.TrafficClass
+}
+
+func (m *SCIONMatchTrafficClass) String() string {
+	return fmt.Sprintf("sciontc=%s", m.toHex())
+}
+
+func (m *SCIONMatchTrafficClass) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		jsonContainer{
+			"TrafficClass": m.toHex(),
+		},
+	)
+}
+
+func (m *SCIONMatchTrafficClass) toHex() string {
+	return fmt.Sprintf("%#x", m.TrafficClass)
+}
+
+func (m *SCIONMatchTrafficClass) UnmarshalJSON(b []byte) error {
+	// Format is 0x hex number in quoted string
+	i, err := unmarshalUintField(b, TypeSCIONMatchTrafficClass, "TrafficClass", 8)
+	if err != nil {
+		return err
+	}
+	m.TrafficClass = uint8(i)
+	return nil
+}
+
+var _ SCIONPredicate = (*SCIONMatchDSCP)(nil)
+
+// SCIONMatchDSCP checks whether the DSCP subset, i.e., the upper six bits, of
+// the traffic class field of the SCION header matches.
+type SCIONMatchDSCP struct {
+	DSCP uint8
+}
+
+func (m *SCIONMatchDSCP) Type() string {
+	return TypeSCIONMatchDSCP
+}
+
+func (m *SCIONMatchDSCP) Eval(s *slayers.SCION) bool {
+	return m.DSCP == s.TrafficClass>>2
+}
+
+func (m *SCIONMatchDSCP) String() string {
+	return fmt.Sprintf("sciondscp=%s", m.toHex())
+}
+
+func (m *SCIONMatchDSCP) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		jsonContainer{
+			"DSCP": m.toHex(),
+		},
+	)
+}
+
+func (m *SCIONMatchDSCP) toHex() string {
+	return fmt.Sprintf("%#x", m.DSCP)
+}
+
+func (m *SCIONMatchDSCP) UnmarshalJSON(b []byte) error {
+	// Format is 0x hex number in quoted string
+	i, err := unmarshalUintField(b, TypeSCIONMatchDSCP, "DSCP", 6)
+	if err != nil {
+		return err
+	}
+	m.DSCP = uint8(i)
+	return nil
+}
+
+var _ SCIONPredicate = (*SCIONMatchSrcIA)(nil)
+
+// SCIONMatchSrcIA checks whether the source ISD-AS of the SCION header
+// matches. A zero ISD or AS in IA is a wildcard that matches any ISD or AS,
+// respectively.
+type SCIONMatchSrcIA struct {
+	IA addr.IA
+}
+
+func (m *SCIONMatchSrcIA) Type() string {
+	return TypeSCIONMatchSrcIA
+}
+
+func (m *SCIONMatchSrcIA) Eval(s *slayers.SCION) bool {
+	return matchIA(m.IA, s.SrcIA)
+}
+
+func (m *SCIONMatchSrcIA) String() string {
+	return fmt.Sprintf("srcia=%s", m.IA)
+}
+
+func (m *SCIONMatchSrcIA) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		jsonContainer{
+			"IA": m.IA.String(),
+		},
+	)
+}
+
+func (m *SCIONMatchSrcIA) UnmarshalJSON(b []byte) error {
+	ia, err := unmarshalIAField(b, TypeSCIONMatchSrcIA)
+	if err != nil {
+		return err
+	}
+	m.IA = ia
+	return nil
+}
+
+var _ SCIONPredicate = (*SCIONMatchDstIA)(nil)
+
+// SCIONMatchDstIA checks whether the destination ISD-AS of the SCION header
+// matches. A zero ISD or AS in IA is a wildcard that matches any ISD or AS,
+// respectively.
+type SCIONMatchDstIA struct {
+	IA addr.IA
+}
+
+func (m *SCIONMatchDstIA) Type() string {
+	return TypeSCIONMatchDstIA
+}
+
+func (m *SCIONMatchDstIA) Eval(s *slayers.SCION) bool {
+	return matchIA(m.IA, s.DstIA)
+}
+
+func (m *SCIONMatchDstIA) String() string {
+	return fmt.Sprintf("dstia=%s", m.IA)
+}
+
+func (m *SCIONMatchDstIA) MarshalJSON() ([]byte, error) {
+	return json.Marshal(
+		jsonContainer{
+			"IA": m.IA.String(),
+		},
+	)
+}
+
+func (m *SCIONMatchDstIA) UnmarshalJSON(b []byte) error {
+	ia, err := unmarshalIAField(b, TypeSCIONMatchDstIA)
+	if err != nil {
+		return err
+	}
+	m.IA = ia
+	return nil
+}
+
+// matchIA returns true if ia matches the pattern. A zero ISD or AS in the
+// pattern matches any ISD or AS.
+func matchIA(pattern, ia addr.IA) bool {
+	if pattern.ISD() != 0 && pattern.ISD() != ia.ISD() {
+		return false
+	}
+	return pattern.AS() == 0 || pattern.AS() == ia.AS()
+}
+
+func unmarshalIAField(b []byte, name string) (addr.IA, error) {
+	s, err := unmarshalStringField(b, name, "IA")
+	if err != nil {
+		return 0, err
+	}
+	ia, err := addr.ParseIA(s)
+	if err != nil {
+		return 0, serrors.Wrap("Unable to parse IA field", err, "name", name)
+	}
+	return ia, nil
+}
+
+// decodeSCION extracts the SCION header from the layer. The layer is either a
+// SCION layer itself, or an IPv4 layer with a SCION/UDP payload.
+func decodeSCION(v gopacket.Layer) (*slayers.SCION, bool) {
+	if v == nil {
+		return nil, false
+	}
+	if s, ok := v.(*slayers.SCION); ok {
+		return s, true
+	}
+	ipv4, ok := v.(*layers.IPv4)
+	if !ok || ipv4.NextLayerType() != layers.LayerTypeUDP {
+		return nil, false
+	}
+	udp := &layers.UDP{}
+	if err := udp.DecodeFromBytes(ipv4.LayerPayload(), gopacket.NilDecodeFeedback); err != nil {
+		return nil, false
+	}
+	if udp.NextLayerType() != slayers.LayerTypeSCION {
+		return nil, false
+	}
+	s := &slayers.SCION{}
+	if err := s.DecodeFromBytes(udp.LayerPayload(), gopacket.NilDecodeFeedback); err != nil {
+		return nil, false
+	}
+	return s, true
+}
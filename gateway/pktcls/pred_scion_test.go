@@ -0,0 +1,422 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls_test
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/gateway/pktcls"
+	"github.com/scionproto/scion/pkg/addr"
+	"github.com/scionproto/scion/pkg/slayers"
+	"github.com/scionproto/scion/pkg/slayers/path"
+	"github.com/scionproto/scion/pkg/slayers/path/empty"
+	"github.com/scionproto/scion/pkg/slayers/path/epic"
+	"github.com/scionproto/scion/pkg/slayers/path/onehop"
+	"github.com/scionproto/scion/pkg/slayers/path/scion"
+)
+
+// scionPort is the UDP port that is registered to carry SCION in the tests.
+const scionPort = 30041
+
+func init() {
+	layers.RegisterUDPPortLayerType(layers.UDPPort(scionPort), slayers.LayerTypeSCION)
+}
+
+func TestMatchIsSCION(t *testing.T) {
+	testCases := map[string]struct {
+		Packet  gopacket.Layer
+		ExpEval bool
+	}{
+		"nil": {
+			Packet:  nil,
+			ExpEval: false,
+		},
+		"SCION layer": {
+			Packet:  newSCION(t),
+			ExpEval: true,
+		},
+		"SCION over IPv4": {
+			Packet:  createSCIONPacket(t, scionPort, newSCION(t)),
+			ExpEval: true,
+		},
+		"SCION on unregistered port": {
+			Packet:  createSCIONPacket(t, 40000, newSCION(t)),
+			ExpEval: false,
+		},
+		"garbage on registered port": {
+			Packet:  createUDPPacket(40000, scionPort),
+			ExpEval: false,
+		},
+		"plain UDP": {
+			Packet:  createUDPPacket(40000, 40001),
+			ExpEval: false,
+		},
+		"plain IPv4": {
+			Packet:  &layers.IPv4{Protocol: layers.IPProtocolTCP},
+			ExpEval: false,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cond := pktcls.MatchIsSCION{}
+			assert.Equal(t, tc.ExpEval, cond.Eval(tc.Packet))
+		})
+	}
+}
+
+func TestMatchIsSCIONJSON(t *testing.T) {
+	classes := pktcls.ClassMap{
+		"scion": pktcls.NewClass("scion", pktcls.NewCondAllOf(pktcls.MatchIsSCION{})),
+	}
+	raw, err := json.Marshal(classes)
+	require.NoError(t, err)
+	var parsed pktcls.ClassMap
+	require.NoError(t, json.Unmarshal(raw, &parsed))
+	assert.Equal(t, classes, parsed)
+}
+
+func TestSCIONMatchPathType(t *testing.T) {
+	onehopSCION := newSCION(t)
+	onehopSCION.PathType = onehop.PathType
+	onehopSCION.Path = &onehop.Path{}
+
+	testCases := map[string]struct {
+		Packet   gopacket.Layer
+		PathType path.Type
+		ExpEval  bool
+	}{
+		"empty matches": {
+			Packet:   newSCION(t),
+			PathType: empty.PathType,
+			ExpEval:  true,
+		},
+		"empty over IPv4 matches": {
+			Packet:   createSCIONPacket(t, scionPort, newSCION(t)),
+			PathType: empty.PathType,
+			ExpEval:  true,
+		},
+		"onehop matches": {
+			Packet:   onehopSCION,
+			PathType: onehop.PathType,
+			ExpEval:  true,
+		},
+		"onehop does not match scion": {
+			Packet:   onehopSCION,
+			PathType: scion.PathType,
+			ExpEval:  false,
+		},
+		"plain UDP": {
+			Packet:   createUDPPacket(40000, 40001),
+			PathType: empty.PathType,
+			ExpEval:  false,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cond := pktcls.NewCondSCION(&pktcls.SCIONMatchPathType{PathType: tc.PathType})
+			assert.Equal(t, tc.ExpEval, cond.Eval(tc.Packet))
+		})
+	}
+}
+
+func TestSCIONMatchPathTypeJSON(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		classes := pktcls.ClassMap{}
+		for _, pt := range []path.Type{
+			empty.PathType, scion.PathType, onehop.PathType, epic.PathType, 7,
+		} {
+			m := &pktcls.SCIONMatchPathType{PathType: pt}
+			classes[m.String()] = pktcls.NewClass(m.String(), pktcls.NewCondSCION(m))
+		}
+		raw, err := json.Marshal(classes)
+		require.NoError(t, err)
+		assert.Contains(t, string(raw),
+			`{"CondSCION":{"MatchSCIONPathType":{"PathType":"onehop"}}}`)
+		assert.Contains(t, string(raw), `{"CondSCION":{"MatchSCIONPathType":{"PathType":"7"}}}`)
+		var parsed pktcls.ClassMap
+		require.NoError(t, json.Unmarshal(raw, &parsed))
+		assert.Equal(t, classes, parsed)
+	})
+	t.Run("case insensitive", func(t *testing.T) {
+		for name, pt := range map[string]path.Type{
+			"SCION":  scion.PathType,
+			"OneHop": onehop.PathType,
+			"EPIC":   epic.PathType,
+			"Empty":  empty.PathType,
+		} {
+			var m pktcls.SCIONMatchPathType
+			require.NoError(t, json.Unmarshal([]byte(`{"PathType":"`+name+`"}`), &m), name)
+			assert.Equal(t, pt, m.PathType, name)
+		}
+	})
+	t.Run("unknown path type", func(t *testing.T) {
+		var m pktcls.SCIONMatchPathType
+		assert.Error(t, json.Unmarshal([]byte(`{"PathType":"colibri"}`), &m))
+		assert.Error(t, json.Unmarshal([]byte(`{"PathType":"256"}`), &m))
+	})
+	t.Run("string", func(t *testing.T) {
+		m := &pktcls.SCIONMatchPathType{PathType: onehop.PathType}
+		assert.Equal(t, "pathtype=onehop", m.String())
+	})
+}
+
+func TestSCIONMatchTrafficClass(t *testing.T) {
+	// newSCION sets the traffic class to 0xb8, i.e., DSCP 0x2e (EF).
+	testCases := map[string]struct {
+		Packet  gopacket.Layer
+		Pred    pktcls.SCIONPredicate
+		ExpEval bool
+	}{
+		"traffic class matches": {
+			Packet:  newSCION(t),
+			Pred:    &pktcls.SCIONMatchTrafficClass{TrafficClass: 0xb8},
+			ExpEval: true,
+		},
+		"traffic class over IPv4 matches": {
+			Packet:  createSCIONPacket(t, scionPort, newSCION(t)),
+			Pred:    &pktcls.SCIONMatchTrafficClass{TrafficClass: 0xb8},
+			ExpEval: true,
+		},
+		"traffic class differs in ECN bits": {
+			Packet:  newSCION(t),
+			Pred:    &pktcls.SCIONMatchTrafficClass{TrafficClass: 0xb9},
+			ExpEval: false,
+		},
+		"dscp matches": {
+			Packet:  newSCION(t),
+			Pred:    &pktcls.SCIONMatchDSCP{DSCP: 0x2e},
+			ExpEval: true,
+		},
+		"dscp over IPv4 matches": {
+			Packet:  createSCIONPacket(t, scionPort, newSCION(t)),
+			Pred:    &pktcls.SCIONMatchDSCP{DSCP: 0x2e},
+			ExpEval: true,
+		},
+		"dscp is not traffic class": {
+			Packet:  newSCION(t),
+			Pred:    &pktcls.SCIONMatchDSCP{DSCP: 0xb8 & 0x3f},
+			ExpEval: false,
+		},
+		"plain UDP": {
+			Packet:  createUDPPacket(40000, 40001),
+			Pred:    &pktcls.SCIONMatchDSCP{DSCP: 0},
+			ExpEval: false,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cond := pktcls.NewCondSCION(tc.Pred)
+			assert.Equal(t, tc.ExpEval, cond.Eval(tc.Packet))
+		})
+	}
+}
+
+func TestSCIONMatchTrafficClassJSON(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		classes := pktcls.ClassMap{}
+		for _, m := range []pktcls.SCIONPredicate{
+			&pktcls.SCIONMatchTrafficClass{TrafficClass: 0xb8},
+			&pktcls.SCIONMatchDSCP{DSCP: 0x2e},
+		} {
+			classes[m.String()] = pktcls.NewClass(m.String(), pktcls.NewCondSCION(m))
+		}
+		raw, err := json.Marshal(classes)
+		require.NoError(t, err)
+		assert.Contains(t, string(raw),
+			`{"CondSCION":{"MatchSCIONTrafficClass":{"TrafficClass":"0xb8"}}}`)
+		assert.Contains(t, string(raw), `{"CondSCION":{"MatchSCIONDSCP":{"DSCP":"0x2e"}}}`)
+		var parsed pktcls.ClassMap
+		require.NoError(t, json.Unmarshal(raw, &parsed))
+		assert.Equal(t, classes, parsed)
+	})
+	t.Run("dscp out of range", func(t *testing.T) {
+		var m pktcls.SCIONMatchDSCP
+		assert.Error(t, json.Unmarshal([]byte(`{"DSCP":"0x40"}`), &m))
+	})
+	t.Run("traffic class not a string", func(t *testing.T) {
+		var m pktcls.SCIONMatchTrafficClass
+		assert.Error(t, json.Unmarshal([]byte(`{"TrafficClass":184}`), &m))
+	})
+	t.Run("string", func(t *testing.T) {
+		assert.Equal(t, "sciontc=0xb8",
+			(&pktcls.SCIONMatchTrafficClass{TrafficClass: 0xb8}).String())
+		assert.Equal(t, "sciondscp=0x2e", (&pktcls.SCIONMatchDSCP{DSCP: 0x2e}).String())
+	})
+}
+
+func TestSCIONMatchIA(t *testing.T) {
+	// newSCION sets the source to 1-ff00:0:110 and the destination to
+	// 1-ff00:0:111.
+	src := func(ia string) pktcls.SCIONPredicate {
+		return &pktcls.SCIONMatchSrcIA{IA: addr.MustParseIA(ia)}
+	}
+	dst := func(ia string) pktcls.SCIONPredicate {
+		return &pktcls.SCIONMatchDstIA{IA: addr.MustParseIA(ia)}
+	}
+	testCases := map[string]struct {
+		Packet  gopacket.Layer
+		Pred    pktcls.SCIONPredicate
+		ExpEval bool
+	}{
+		"source matches": {
+			Packet:  newSCION(t),
+			Pred:    src("1-ff00:0:110"),
+			ExpEval: true,
+		},
+		"source over IPv4 matches": {
+			Packet:  createSCIONPacket(t, scionPort, newSCION(t)),
+			Pred:    src("1-ff00:0:110"),
+			ExpEval: true,
+		},
+		"source is not destination": {
+			Packet:  newSCION(t),
+			Pred:    src("1-ff00:0:111"),
+			ExpEval: false,
+		},
+		"destination matches": {
+			Packet:  newSCION(t),
+			Pred:    dst("1-ff00:0:111"),
+			ExpEval: true,
+		},
+		"destination ISD differs": {
+			Packet:  newSCION(t),
+			Pred:    dst("2-ff00:0:111"),
+			ExpEval: false,
+		},
+		"wildcard AS matches": {
+			Packet:  newSCION(t),
+			Pred:    src("1-0"),
+			ExpEval: true,
+		},
+		"wildcard AS in other ISD": {
+			Packet:  newSCION(t),
+			Pred:    src("2-0"),
+			ExpEval: false,
+		},
+		"wildcard ISD matches": {
+			Packet:  newSCION(t),
+			Pred:    dst("0-ff00:0:111"),
+			ExpEval: true,
+		},
+		"wildcard ISD with other AS": {
+			Packet:  newSCION(t),
+			Pred:    dst("0-ff00:0:112"),
+			ExpEval: false,
+		},
+		"wildcard matches all": {
+			Packet:  newSCION(t),
+			Pred:    dst("0-0"),
+			ExpEval: true,
+		},
+		"plain UDP": {
+			Packet:  createUDPPacket(40000, 40001),
+			Pred:    src("0-0"),
+			ExpEval: false,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cond := pktcls.NewCondSCION(tc.Pred)
+			assert.Equal(t, tc.ExpEval, cond.Eval(tc.Packet))
+		})
+	}
+}
+
+func TestSCIONMatchIAJSON(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		classes := pktcls.ClassMap{}
+		for _, m := range []pktcls.SCIONPredicate{
+			&pktcls.SCIONMatchSrcIA{IA: addr.MustParseIA("1-ff00:0:110")},
+			&pktcls.SCIONMatchDstIA{IA: addr.MustParseIA("1-0")},
+		} {
+			classes[m.String()] = pktcls.NewClass(m.String(), pktcls.NewCondSCION(m))
+		}
+		raw, err := json.Marshal(classes)
+		require.NoError(t, err)
+		assert.Contains(t, string(raw), `{"CondSCION":{"MatchSCIONSrcIA":{"IA":"1-ff00:0:110"}}}`)
+		assert.Contains(t, string(raw), `{"CondSCION":{"MatchSCIONDstIA":{"IA":"1-0"}}}`)
+		var parsed pktcls.ClassMap
+		require.NoError(t, json.Unmarshal(raw, &parsed))
+		assert.Equal(t, classes, parsed)
+	})
+	t.Run("invalid IA", func(t *testing.T) {
+		var m pktcls.SCIONMatchSrcIA
+		assert.Error(t, json.Unmarshal([]byte(`{"IA":"1-ff00:0"}`), &m))
+	})
+	t.Run("IA not a string", func(t *testing.T) {
+		var m pktcls.SCIONMatchDstIA
+		assert.Error(t, json.Unmarshal([]byte(`{"IA":1}`), &m))
+	})
+	t.Run("string", func(t *testing.T) {
+		assert.Equal(t, "srcia=1-ff00:0:110",
+			(&pktcls.SCIONMatchSrcIA{IA: addr.MustParseIA("1-ff00:0:110")}).String())
+		assert.Equal(t, "dstia=0-ff00:0:111",
+			(&pktcls.SCIONMatchDstIA{IA: addr.MustParseIA("0-ff00:0:111")}).String())
+	})
+}
+
+func newSCION(t *testing.T) *slayers.SCION {
+	t.Helper()
+	s := &slayers.SCION{
+		Version:      0,
+		TrafficClass: 0xb8,
+		FlowID:       0xdead,
+		NextHdr:      slayers.L4UDP,
+		PathType:     empty.PathType,
+		Path:         &empty.Path{},
+		SrcIA:        addr.MustParseIA("1-ff00:0:110"),
+		DstIA:        addr.MustParseIA("1-ff00:0:111"),
+	}
+	require.NoError(t, s.SetSrcAddr(addr.MustParseHost("10.0.0.1")))
+	require.NoError(t, s.SetDstAddr(addr.MustParseHost("10.0.0.2")))
+	return s
+}
+
+// createSCIONPacket returns an IPv4 layer that carries the SCION header in UDP
+// with the given destination port.
+func createSCIONPacket(t *testing.T, dstPort uint16, s *slayers.SCION) gopacket.Layer {
+	t.Helper()
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    net.IP{192, 168, 14, 3},
+		DstIP:    net.IP{192, 168, 14, 2},
+		Protocol: layers.IPProtocolUDP,
+		Flags:    layers.IPv4DontFragment,
+	}
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(40000),
+		DstPort: layers.UDPPort(dstPort),
+	}
+	require.NoError(t, udp.SetNetworkLayerForChecksum(ip))
+	input := gopacket.NewSerializeBuffer()
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+	require.NoError(t, gopacket.SerializeLayers(input, options,
+		ip, udp, s, gopacket.Payload([]byte("payload"))))
+	pkt := &layers.IPv4{}
+	require.NoError(t, pkt.DecodeFromBytes(input.Bytes(), gopacket.NilDecodeFeedback))
+	return pkt
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls
+
+import (
+	"encoding/json"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+// registeredPredicates contains the factories of the IPv4 predicates that are
+// registered with RegisterPredicate, keyed by their type.
+var registeredPredicates = map[string]func() IPv4Predicate{}
+
+// RegisterPredicate registers an IPv4 predicate that is not part of this
+// package, so that it can be unmarshaled from JSON. The predicate is encoded
+// as {"CondIPv4": {typ: ...}}, like the built-in IPv4 predicates.
+//
+// factory must return a new pointer to the predicate on every call, into which
+// the JSON encoding is unmarshaled. The Type method of the predicate must
+// return typ, so that marshaling and unmarshaling round trip.
+//
+// RegisterPredicate must only be called during initialization, e.g., in an
+// init function. It panics if typ is empty, if factory is nil, or if typ is
+// already in use by a built-in or a registered type.
+func RegisterPredicate(typ string, factory func() IPv4Predicate) {
+	if typ == "" || factory == nil {
+		panic("predicate type and factory must be set")
+	}
+	if _, ok := schemas[typ]; ok {
+		panic("predicate type is a built-in type: " + typ)
+	}
+	if _, ok := registeredPredicates[typ]; ok {
+		panic("predicate type already registered: " + typ)
+	}
+	registeredPredicates[typ] = factory
+}
+
+// unmarshalRegisteredPredicate unmarshals a predicate of the registered type
+// typ. It returns false if typ is not registered.
+func unmarshalRegisteredPredicate(typ string, v *json.RawMessage) (IPv4Predicate, bool, error) {
+	factory, ok := registeredPredicates[typ]
+	if !ok {
+		return nil, false, nil
+	}
+	b := []byte("null")
+	if v != nil {
+		b = *v
+	}
+	p := factory()
+	if err := json.Unmarshal(b, p); err != nil {
+		return nil, true, serrors.Wrap("Unable to parse registered predicate", err,
+			"type", typ)
+	}
+	return p, true, nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/gopacket/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/gateway/pktcls"
+)
+
+const typeMatchTTLParity = "MatchTTLParity"
+
+func init() {
+	pktcls.RegisterPredicate(typeMatchTTLParity, func() pktcls.IPv4Predicate {
+		return &matchTTLParity{}
+	})
+}
+
+// matchTTLParity is a predicate that is not part of pktcls. It matches odd or
+// even TTL values.
+type matchTTLParity struct {
+	Odd bool
+}
+
+func (m *matchTTLParity) Eval(p *layers.IPv4) bool {
+	return (p.TTL%2 == 1) == m.Odd
+}
+
+func (m *matchTTLParity) Type() string {
+	return typeMatchTTLParity
+}
+
+func (m *matchTTLParity) String() string {
+	return fmt.Sprintf("ttlodd=%t", m.Odd)
+}
+
+func TestRegisterPredicate(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		classes := pktcls.ClassMap{
+			"odd": pktcls.NewClass("odd", pktcls.NewCondAllOf(
+				pktcls.NewCondIPv4(&matchTTLParity{Odd: true}),
+				pktcls.NewCondIPv4(&pktcls.IPv4MatchToS{TOS: 0x80}),
+			)),
+		}
+		raw, err := json.Marshal(classes)
+		require.NoError(t, err)
+		assert.Contains(t, string(raw), `{"CondIPv4":{"MatchTTLParity":{"Odd":true}}}`)
+		require.NoError(t, pktcls.Validate(raw))
+		var parsed pktcls.ClassMap
+		require.NoError(t, json.Unmarshal(raw, &parsed))
+		assert.Equal(t, classes, parsed)
+		assert.True(t, parsed["odd"].Eval(&layers.IPv4{TTL: 63, TOS: 0x80}))
+		assert.False(t, parsed["odd"].Eval(&layers.IPv4{TTL: 64, TOS: 0x80}))
+	})
+	t.Run("invalid value", func(t *testing.T) {
+		raw := []byte(`{"odd": {"CondIPv4": {"MatchTTLParity": {"Odd": "yes"}}}}`)
+		var parsed pktcls.ClassMap
+		assert.Error(t, json.Unmarshal(raw, &parsed))
+		assert.ErrorContains(t, pktcls.Validate(raw), "Invalid value")
+	})
+	t.Run("not in IPv4 condition", func(t *testing.T) {
+		raw := []byte(`{"odd": {"CondIPv6": {"MatchTTLParity": {"Odd": true}}}}`)
+		var parsed pktcls.ClassMap
+		assert.Error(t, json.Unmarshal(raw, &parsed))
+		assert.ErrorContains(t, pktcls.Validate(raw), "Predicate not allowed here")
+	})
+	t.Run("duplicate", func(t *testing.T) {
+		assert.Panics(t, func() {
+			pktcls.RegisterPredicate(typeMatchTTLParity, func() pktcls.IPv4Predicate {
+				return &matchTTLParity{}
+			})
+		})
+	})
+	t.Run("built-in type", func(t *testing.T) {
+		assert.Panics(t, func() {
+			pktcls.RegisterPredicate(pktcls.TypeIPv4MatchToS, func() pktcls.IPv4Predicate {
+				return &pktcls.IPv4MatchToS{}
+			})
+		})
+	})
+	t.Run("no factory", func(t *testing.T) {
+		assert.Panics(t, func() { pktcls.RegisterPredicate("MatchNothing", nil) })
+	})
+}
@@ -0,0 +1,217 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+// schemaKind describes the JSON value that is expected for a type.
+type schemaKind int
+
+const (
+	// kindCondList is a list of conditions, or null.
+	kindCondList schemaKind = iota
+	// kindCond is a single condition.
+	kindCond
+	// kindBool is a boolean.
+	kindBool
+	// kindPredicate is a condition that wraps a single predicate.
+	kindPredicate
+	// kindFields is an object with fields.
+	kindFields
+	// kindRegistered is a predicate registered with RegisterPredicate.
+	kindRegistered
+)
+
+// typeSchema describes the JSON encoding of a type.
+type typeSchema struct {
+	kind schemaKind
+	// parent is the condition type that wraps predicates of this type. It is
+	// empty for conditions.
+	parent string
+	// required and optional are the fields of kindFields types.
+	required, optional []string
+}
+
+// schemas contains the JSON encoding of all types that can be unmarshaled.
+var schemas = map[string]typeSchema{
+	TypeCondAllOf:                {kind: kindCondList},
+	TypeCondAnyOf:                {kind: kindCondList},
+	TypeCondNot:                  {kind: kindCond},
+	TypeCondBool:                 {kind: kindBool},
+	TypeCondIPv4:                 {kind: kindPredicate},
+	TypeIPv4MatchSource:          ipv4Fields("Net"),
+	TypeIPv4MatchDestination:     ipv4Fields("Net"),
+	TypeIPv4MatchToS:             ipv4Fields("TOS"),
+	TypeIPv4MatchDSCP:            ipv4Fields("DSCP"),
+	TypeIPv4MatchECN:             ipv4Fields("ECN"),
+	TypeIPv4MatchProtocol:        ipv4Fields("Protocol"),
+	TypeIPv4MatchSourceHost:      ipv4Fields("Host"),
+	TypeIPv4MatchDestinationHost: ipv4Fields("Host"),
+	TypeIPv4MatchPayload:         ipv4Fields("Pattern").withOptional("Offset"),
+	TypeIPv4MatchLength:          ipv4Fields("Op", "Length").withOptional("MaxLength"),
+	TypeIPv4MatchTTL:             ipv4Fields("Op", "TTL"),
+	TypeIPv4MatchHasOptions:      ipv4Fields("Option"),
+	TypeIPv4MatchChecksumValid:   ipv4Fields("Valid"),
+	TypeCondIPv6:                 {kind: kindPredicate},
+	TypeIPv6MatchSource:          fields(TypeCondIPv6, "Net"),
+	TypeIPv6MatchDestination:     fields(TypeCondIPv6, "Net"),
+	TypeIPv6MatchTrafficClass:    fields(TypeCondIPv6, "TrafficClass"),
+	TypeIPv6MatchFlowLabel:       fields(TypeCondIPv6, "FlowLabel"),
+	TypeCondPorts:                {kind: kindPredicate},
+	TypePortMatchSource:          fields(TypeCondPorts, "MinPort", "MaxPort"),
+	TypePortMatchDestination:     fields(TypeCondPorts, "MinPort", "MaxPort"),
+	TypeMatchIsSCION:             {kind: kindFields},
+	TypeCondSCION:                {kind: kindPredicate},
+	TypeSCIONMatchPathType:       fields(TypeCondSCION, "PathType"),
+	TypeSCIONMatchTrafficClass:   fields(TypeCondSCION, "TrafficClass"),
+	TypeSCIONMatchDSCP:           fields(TypeCondSCION, "DSCP"),
+	TypeSCIONMatchSrcIA:          fields(TypeCondSCION, "IA"),
+	TypeSCIONMatchDstIA:          fields(TypeCondSCION, "IA"),
+}
+
+func fields(parent string, required ...string) typeSchema {
+	return typeSchema{kind: kindFields, parent: parent, required: required}
+}
+
+func ipv4Fields(required ...string) typeSchema {
+	return fields(TypeCondIPv4, required...)
+}
+
+func (s typeSchema) withOptional(optional ...string) typeSchema {
+	s.optional = optional
+	return s
+}
+
+// Validate checks a JSON encoded ClassMap against the known types and their
+// fields. Unlike unmarshaling, it does not stop at the first problem, but
+// returns a list of all problems it found, e.g., unknown types, unknown or
+// missing fields, predicates in the wrong condition, and malformed values such
+// as invalid networks. Each problem contains the path to the offending value.
+// The fields of predicates registered with RegisterPredicate are not known,
+// they are only checked by unmarshaling.
+// If Validate returns nil, the document can be unmarshaled into a ClassMap.
+func Validate(b []byte) error {
+	var classes map[string]json.RawMessage
+	if err := json.Unmarshal(b, &classes); err != nil {
+		return serrors.Wrap("Unable to parse class map", err)
+	}
+	var errs serrors.List
+	for _, name := range slices.Sorted(maps.Keys(classes)) {
+		errs = append(errs, validateValue(name, "", classes[name])...)
+	}
+	return errs.ToError()
+}
+
+// validateValue validates the JSON encoding of a single typed value, i.e., an
+// object with the type as the only key. parent is the type of the enclosing
+// condition, or empty if the value must be a condition.
+func validateValue(path, parent string, b json.RawMessage) serrors.List {
+	var container map[string]json.RawMessage
+	if err := json.Unmarshal(b, &container); err != nil || container == nil {
+		return serrors.List{serrors.New("Expected object with a single type",
+			"path", path, "value", string(b))}
+	}
+	if len(container) != 1 {
+		return serrors.List{serrors.New("Expected exactly one type", "path", path,
+			"types", slices.Sorted(maps.Keys(container)))}
+	}
+	var typ string
+	var v json.RawMessage
+	for k, raw := range container {
+		typ, v = k, raw
+	}
+	path = path + "." + typ
+	schema, ok := schemas[typ]
+	if _, registered := registeredPredicates[typ]; !ok && registered {
+		// The fields of registered predicates are unknown, they are only
+		// checked by unmarshaling.
+		schema, ok = typeSchema{kind: kindRegistered, parent: TypeCondIPv4}, true
+	}
+	if !ok {
+		return serrors.List{serrors.New("Unknown type", "path", path, "type", typ)}
+	}
+	if schema.parent != parent {
+		if schema.parent == "" {
+			return serrors.List{serrors.New("Condition not allowed in predicate",
+				"path", path, "parent", parent)}
+		}
+		return serrors.List{serrors.New("Predicate not allowed here", "path", path,
+			"expected_parent", schema.parent)}
+	}
+
+	switch schema.kind {
+	case kindCondList:
+		var conds []json.RawMessage
+		if err := json.Unmarshal(v, &conds); err != nil {
+			return serrors.List{serrors.New("Expected list of conditions",
+				"path", path, "value", string(v))}
+		}
+		var errs serrors.List
+		for i, c := range conds {
+			errs = append(errs, validateValue(fmt.Sprintf("%s[%d]", path, i), "", c)...)
+		}
+		return errs
+	case kindCond:
+		return validateValue(path, "", v)
+	case kindPredicate:
+		return validateValue(path, typ, v)
+	case kindBool:
+		var cond bool
+		if err := json.Unmarshal(v, &cond); err != nil {
+			return serrors.List{serrors.New("Expected boolean", "path", path,
+				"value", string(v))}
+		}
+		return nil
+	}
+
+	if schema.kind == kindFields {
+		if errs := validateFields(path, schema, v); len(errs) > 0 {
+			return errs
+		}
+	}
+	// The structure is valid, the values are checked by unmarshaling.
+	if _, err := unmarshalInterface(b); err != nil {
+		return serrors.List{serrors.Wrap("Invalid value", err, "path", path)}
+	}
+	return nil
+}
+
+func validateFields(path string, schema typeSchema, v json.RawMessage) serrors.List {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(v, &fields); err != nil {
+		return serrors.List{serrors.New("Expected object with fields", "path", path,
+			"value", string(v))}
+	}
+	var errs serrors.List
+	for _, f := range slices.Sorted(maps.Keys(fields)) {
+		if !slices.Contains(schema.required, f) && !slices.Contains(schema.optional, f) {
+			errs = append(errs, serrors.New("Unknown field", "path", path, "field", f,
+				"known", slices.Concat(schema.required, schema.optional)))
+		}
+	}
+	for _, f := range schema.required {
+		if _, ok := fields[f]; !ok {
+			errs = append(errs, serrors.New("Field missing", "path", path, "field", f))
+		}
+	}
+	return errs
+}
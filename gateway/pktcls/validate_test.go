@@ -0,0 +1,156 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pktcls_test
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/gateway/pktcls"
+	"github.com/scionproto/scion/pkg/addr"
+	"github.com/scionproto/scion/pkg/private/serrors"
+	"github.com/scionproto/scion/pkg/slayers/path/onehop"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := map[string]struct {
+		Input       string
+		ErrContains []string
+	}{
+		"empty": {
+			Input: `{}`,
+		},
+		"valid": {
+			Input: `{
+				"web": {"CondAllOf": [
+					{"CondIPv4": {"MatchSource": {"Net": "10.0.0.0/8"}}},
+					{"CondNot": {"CondPorts": {"MatchDestinationPort": {
+						"MinPort": "80", "MaxPort": "443"}}}},
+					{"CondIPv4": {"MatchLength": {"Op": "range", "Length": 64,
+						"MaxLength": 1500}}},
+					{"CondIPv4": {"MatchPayload": {"Pattern": "0xdead"}}}
+				]},
+				"scion": {"CondAnyOf": [
+					{"MatchIsSCION": {}},
+					{"CondSCION": {"MatchSCIONSrcIA": {"IA": "1-ff00:0:110"}}},
+					{"CondBool": true}
+				]},
+				"none": {"CondAnyOf": null}
+			}`,
+		},
+		"not a class map": {
+			Input:       `[]`,
+			ErrContains: []string{"Unable to parse class map"},
+		},
+		"all problems": {
+			Input: `{
+				"a": {"CondAllOf": [
+					{"CondIPv4": {"MatchSrc": {"Net": "10.0.0.0/8"}}},
+					{"CondIPv4": {"MatchSource": {"Network": "10.0.0.0/8"}}},
+					{"CondIPv4": {"MatchDestination": {"Net": "10.0.0.0/33"}}}
+				]},
+				"b": {"CondPorts": {"MatchDestinationPort": {"MinPort": "80"}}}
+			}`,
+			ErrContains: []string{
+				`Unknown type {path=a.CondAllOf[0].CondIPv4.MatchSrc; type=MatchSrc}`,
+				`Unknown field {field=Network; known=[Net]; ` +
+					`path=a.CondAllOf[1].CondIPv4.MatchSource}`,
+				`Field missing {field=Net; path=a.CondAllOf[1].CondIPv4.MatchSource}`,
+				`Invalid value {path=a.CondAllOf[2].CondIPv4.MatchDestination}`,
+				`invalid CIDR address: 10.0.0.0/33`,
+				`Field missing {field=MaxPort; path=b.CondPorts.MatchDestinationPort}`,
+			},
+		},
+		"predicate in wrong condition": {
+			Input: `{"a": {"CondIPv4": {"MatchSourcePort": {"MinPort": "1",
+				"MaxPort": "2"}}}}`,
+			ErrContains: []string{"Predicate not allowed here", "expected_parent=CondPorts"},
+		},
+		"predicate without condition": {
+			Input:       `{"a": {"MatchSource": {"Net": "10.0.0.0/8"}}}`,
+			ErrContains: []string{"Predicate not allowed here", "expected_parent=CondIPv4"},
+		},
+		"condition in predicate": {
+			Input:       `{"a": {"CondIPv4": {"CondBool": true}}}`,
+			ErrContains: []string{"Condition not allowed in predicate"},
+		},
+		"several types": {
+			Input:       `{"a": {"CondBool": true, "CondNot": {"CondBool": true}}}`,
+			ErrContains: []string{"Expected exactly one type", "types=[CondBool CondNot]"},
+		},
+		"not an object": {
+			Input:       `{"a": "CondBool"}`,
+			ErrContains: []string{"Expected object with a single type", "path=a"},
+		},
+		"not a list": {
+			Input:       `{"a": {"CondAnyOf": {"CondBool": true}}}`,
+			ErrContains: []string{"Expected list of conditions", "path=a.CondAnyOf"},
+		},
+		"not a boolean": {
+			Input:       `{"a": {"CondBool": "true"}}`,
+			ErrContains: []string{"Expected boolean", "path=a.CondBool"},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := pktcls.Validate([]byte(tc.Input))
+			if len(tc.ErrContains) == 0 {
+				require.NoError(t, err)
+				var cm pktcls.ClassMap
+				assert.NoError(t, json.Unmarshal([]byte(tc.Input), &cm))
+				return
+			}
+			require.Error(t, err)
+			for _, s := range tc.ErrContains {
+				assert.ErrorContains(t, err, s)
+			}
+		})
+	}
+	t.Run("all problems are listed", func(t *testing.T) {
+		err := pktcls.Validate([]byte(testCases["all problems"].Input))
+		var errs serrors.List
+		require.ErrorAs(t, err, &errs)
+		assert.Len(t, errs, 5)
+	})
+}
+
+func TestValidateMarshaled(t *testing.T) {
+	_, network, err := net.ParseCIDR("192.168.0.0/16")
+	require.NoError(t, err)
+	classes := pktcls.ClassMap{
+		"a": pktcls.NewClass("a", pktcls.NewCondAllOf(
+			pktcls.NewCondIPv4(&pktcls.IPv4MatchDestination{Net: network}),
+			pktcls.NewCondIPv4(&pktcls.IPv4MatchECN{ECN: 0x3}),
+			pktcls.NewCondIPv4(&pktcls.IPv4MatchTTL{Op: pktcls.CmpGt, TTL: 1}),
+			pktcls.NewCondIPv4(&pktcls.IPv4MatchHasOptions{Any: true}),
+			pktcls.NewCondIPv4(&pktcls.IPv4MatchChecksumValid{Valid: true}),
+			pktcls.NewCondIPv4(&pktcls.IPv4MatchProtocol{Protocol: 17}),
+			pktcls.NewCondNot(pktcls.NewCondIPv4(&pktcls.IPv4MatchToS{TOS: 0x80})),
+		)),
+		"b": pktcls.NewClass("b", pktcls.NewCondAnyOf(
+			pktcls.NewCondSCION(&pktcls.SCIONMatchPathType{PathType: onehop.PathType}),
+			pktcls.NewCondSCION(&pktcls.SCIONMatchDstIA{IA: addr.MustParseIA("1-0")}),
+			pktcls.NewCondSCION(&pktcls.SCIONMatchDSCP{DSCP: 0x2e}),
+			pktcls.NewCondPorts(&pktcls.PortMatchSource{MinPort: 1, MaxPort: 2}),
+		)),
+	}
+	raw, err := json.Marshal(classes)
+	require.NoError(t, err)
+	assert.NoError(t, pktcls.Validate(raw))
+}
@@ -68,6 +68,9 @@ const (
 	ErrNotFound = "err_not_found"
 	// ErrUnavailable is used for errors where a resource is not available.
 	ErrUnavailable = "err_unavailable"
+	// ErrExcessiveValidity is used for requests that ask for a validity period
+	// exceeding the policy.
+	ErrExcessiveValidity = "err_excessive_validity"
 )
 
 // FIXME(roosd): remove when moving messenger to new metrics style.
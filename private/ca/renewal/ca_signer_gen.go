@@ -19,8 +19,10 @@ import (
 	"context"
 	"crypto"
 	"crypto/x509"
+	"encoding/asn1"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"sync"
@@ -38,8 +40,19 @@ import (
 var (
 	errRootCert        = serrors.New("root certificate")
 	errOutsideValidity = serrors.New("outside validity")
+
+	// ErrValidityExceedsPolicy indicates that a CSR was rejected, because it
+	// requests a validity period that exceeds the CA policy.
+	ErrValidityExceedsPolicy = serrors.New("requested validity exceeds policy")
 )
 
+// OIDExtensionRequestedValidity identifies the CSR extension that carries the
+// validity period requested for the AS certificate. The value is an ASN.1
+// INTEGER holding the validity in seconds.
+//
+// Experimental: This extension is experimental and will be subject to change.
+var OIDExtensionRequestedValidity = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 55324, 1, 4, 1}
+
 type Metrics struct {
 	// CAActive describes whether the CA signer is active and can sign
 	// certificate chains.
@@ -55,6 +68,9 @@ type Metrics struct {
 	LastGeneratedCA metrics.Gauge
 	// ExpirationCA exports the expiration time of the current CA signer.
 	ExpirationCA metrics.Gauge
+	// ExcessiveValidity tracks the number of CSRs that request a validity
+	// period that exceeds the CA policy.
+	ExcessiveValidity metrics.Counter
 }
 
 // PolicyGen generates a new CA policy.
@@ -66,9 +82,20 @@ type PolicyGen interface {
 type ChainBuilder struct {
 	PolicyGen    PolicyGen
 	SignedChains func(string) metrics.Counter
+
+	// RejectExcessiveValidity rejects CSRs that request a validity period
+	// that exceeds the CA policy. If false, the validity is clamped to the
+	// policy maximum.
+	RejectExcessiveValidity bool
+	// ExcessiveValidity is incremented for every CSR that requests a validity
+	// period that exceeds the CA policy.
+	ExcessiveValidity metrics.Counter
 }
 
 // CreateChain creates a certificate chain with the latest available CA policy.
+// If the CSR requests a validity period, see OIDExtensionRequestedValidity,
+// the issued certificate is valid for the requested period, bounded by the
+// policy.
 func (c ChainBuilder) CreateChain(ctx context.Context,
 	csr *x509.CertificateRequest) ([]*x509.Certificate, error) {
 
@@ -77,6 +104,23 @@ func (c ChainBuilder) CreateChain(ctx context.Context,
 		c.incSignedChains("err_inactive")
 		return nil, err
 	}
+	requested, ok, err := RequestedValidity(csr)
+	if err != nil {
+		c.incSignedChains("err_validity")
+		return nil, err
+	}
+	if ok && requested > policy.Validity {
+		metrics.CounterInc(c.ExcessiveValidity)
+		if c.RejectExcessiveValidity {
+			c.incSignedChains("err_validity")
+			return nil, serrors.JoinNoStack(ErrValidityExceedsPolicy, nil,
+				"requested", requested, "max", policy.Validity)
+		}
+		log.FromCtx(ctx).Debug("Clamping requested validity to policy",
+			"requested", requested, "max", policy.Validity)
+	} else if ok {
+		policy.Validity = requested
+	}
 	chain, err := policy.CreateChain(csr)
 	if err != nil {
 		c.incSignedChains("err_internal")
@@ -92,6 +136,30 @@ func (c ChainBuilder) incSignedChains(result string) {
 	}
 }
 
+// RequestedValidity returns the validity period requested in the CSR. If the
+// CSR does not contain the OIDExtensionRequestedValidity extension, false is
+// returned.
+func RequestedValidity(csr *x509.CertificateRequest) (time.Duration, bool, error) {
+	for _, ext := range csr.Extensions {
+		if !ext.Id.Equal(OIDExtensionRequestedValidity) {
+			continue
+		}
+		var secs int64
+		rest, err := asn1.Unmarshal(ext.Value, &secs)
+		if err != nil {
+			return 0, false, serrors.Wrap("parsing requested validity", err)
+		}
+		if len(rest) != 0 {
+			return 0, false, serrors.New("trailing data after requested validity")
+		}
+		if secs <= 0 || secs > int64(math.MaxInt64/time.Second) {
+			return 0, false, serrors.New("requested validity out of range", "seconds", secs)
+		}
+		return time.Duration(secs) * time.Second, true, nil
+	}
+	return 0, false, nil
+}
+
 // CachingPolicyGen is a PolicyGen that can cache the previously generated
 // CASigner for some time.
 type CachingPolicyGen struct {
@@ -22,6 +22,8 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
 	"os"
 	"path/filepath"
 	"testing"
@@ -44,6 +46,133 @@ import (
 	"github.com/scionproto/scion/scion-pki/testcrypto"
 )
 
+func TestChainBuilderCreateChain(t *testing.T) {
+	ca := xtest.LoadChain(t, "testdata/common/ISD1/ASff00_0_110/crypto/ca/ISD1-ASff00_0_110.ca.crt")
+	caKey := loadKey(t, "testdata/common/ISD1/ASff00_0_110/crypto/ca/cp-ca.key")
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	policy := cppki.CAPolicy{
+		Validity:    time.Hour,
+		Certificate: ca[0],
+		Signer:      caKey,
+		CurrentTime: ca[0].NotBefore.Add(time.Minute),
+	}
+	requestValidity := func(t *testing.T, secs int64) []pkix.Extension {
+		raw, err := asn1.Marshal(secs)
+		require.NoError(t, err)
+		return []pkix.Extension{{Id: renewal.OIDExtensionRequestedValidity, Value: raw}}
+	}
+
+	testCases := map[string]struct {
+		Extensions       func(t *testing.T) []pkix.Extension
+		Reject           bool
+		ErrAssertion     assert.ErrorAssertionFunc
+		Validity         time.Duration
+		Excessive        float64
+		SignedChainLabel string
+	}{
+		"no requested validity": {
+			Extensions:       func(t *testing.T) []pkix.Extension { return nil },
+			ErrAssertion:     assert.NoError,
+			Validity:         time.Hour,
+			SignedChainLabel: "ok_success",
+		},
+		"shorter validity": {
+			Extensions: func(t *testing.T) []pkix.Extension {
+				return requestValidity(t, 30*60)
+			},
+			ErrAssertion:     assert.NoError,
+			Validity:         30 * time.Minute,
+			SignedChainLabel: "ok_success",
+		},
+		"excessive validity clamped": {
+			Extensions: func(t *testing.T) []pkix.Extension {
+				return requestValidity(t, 2*60*60)
+			},
+			ErrAssertion:     assert.NoError,
+			Validity:         time.Hour,
+			Excessive:        1,
+			SignedChainLabel: "ok_success",
+		},
+		"excessive validity rejected": {
+			Extensions: func(t *testing.T) []pkix.Extension {
+				return requestValidity(t, 2*60*60)
+			},
+			Reject: true,
+			ErrAssertion: func(t assert.TestingT, err error, _ ...any) bool {
+				return assert.ErrorIs(t, err, renewal.ErrValidityExceedsPolicy)
+			},
+			Excessive:        1,
+			SignedChainLabel: "err_validity",
+		},
+		"negative validity": {
+			Extensions: func(t *testing.T) []pkix.Extension {
+				return requestValidity(t, -1)
+			},
+			ErrAssertion:     assert.Error,
+			SignedChainLabel: "err_validity",
+		},
+		"malformed validity": {
+			Extensions: func(t *testing.T) []pkix.Extension {
+				return []pkix.Extension{{
+					Id:    renewal.OIDExtensionRequestedValidity,
+					Value: []byte("garbage"),
+				}}
+			},
+			ErrAssertion:     assert.Error,
+			SignedChainLabel: "err_validity",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mctrl := gomock.NewController(t)
+			gen := mock_renewal.NewMockPolicyGen(mctrl)
+			gen.EXPECT().Generate(gomock.Any()).Return(policy, nil)
+
+			tmpl := &x509.CertificateRequest{
+				Subject: pkix.Name{
+					CommonName: "1-ff00:0:111 AS Certificate",
+					ExtraNames: []pkix.AttributeTypeAndValue{
+						{
+							Type:  cppki.OIDNameIA,
+							Value: "1-ff00:0:111",
+						},
+					},
+				},
+				ExtraExtensions: tc.Extensions(t),
+			}
+			raw, err := x509.CreateCertificateRequest(rand.Reader, tmpl, key)
+			require.NoError(t, err)
+			csr, err := x509.ParseCertificateRequest(raw)
+			require.NoError(t, err)
+
+			signed := map[string]*metrics.TestCounter{}
+			excessive := metrics.NewTestCounter()
+			b := renewal.ChainBuilder{
+				PolicyGen: gen,
+				SignedChains: func(result string) metrics.Counter {
+					if _, ok := signed[result]; !ok {
+						signed[result] = metrics.NewTestCounter()
+					}
+					return signed[result]
+				},
+				RejectExcessiveValidity: tc.Reject,
+				ExcessiveValidity:       excessive,
+			}
+			chain, err := b.CreateChain(context.Background(), csr)
+			tc.ErrAssertion(t, err)
+			assert.Equal(t, tc.Excessive, metrics.CounterValue(excessive))
+			require.Contains(t, signed, tc.SignedChainLabel)
+			assert.Equal(t, float64(1), metrics.CounterValue(signed[tc.SignedChainLabel]))
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.Validity, chain[0].NotAfter.Sub(chain[0].NotBefore))
+		})
+	}
+}
+
 func TestChachingPolicyGenGenerate(t *testing.T) {
 	dir := genCrypto(t)
 
@@ -0,0 +1,138 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"crypto/x509"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/scionproto/scion/pkg/metrics"
+	cppb "github.com/scionproto/scion/pkg/proto/control_plane"
+)
+
+// DefaultMaxBatchSize is the default maximum number of requests in a batch.
+const DefaultMaxBatchSize = 64
+
+// BatchResult is the result of a single request in a batch.
+type BatchResult struct {
+	// Chain is the issued certificate chain. It is nil if the request failed.
+	Chain []*x509.Certificate
+	// Err is the gRPC status error of a failed request. It is nil if the
+	// chain was issued.
+	Err error
+}
+
+// Code returns the gRPC status code of the result, codes.OK if the chain was
+// issued.
+func (r BatchResult) Code() codes.Code {
+	return status.Code(r.Err)
+}
+
+// BatchHandlerMetrics contains the counters for the BatchHandler. Each batch
+// increments exactly one of them. The individual requests are counted by the
+// metrics of the CMSRequestHandler.
+type BatchHandlerMetrics struct {
+	// Success counts the batches where all requests succeeded.
+	Success metrics.Counter
+	// PartialError counts the batches where some, but not all, requests
+	// failed.
+	PartialError metrics.Counter
+	// Error counts the batches where all requests failed or the batch was
+	// rejected as a whole.
+	Error metrics.Counter
+}
+
+// BatchHandler handles batches of CMS signed renewal requests, e.g., of a
+// gateway that renews the chains of many child ASes at once.
+type BatchHandler struct {
+	// Handler handles the individual requests.
+	Handler CMSRequestHandler
+	// MaxBatchSize is the maximum number of requests in a batch. If zero,
+	// DefaultMaxBatchSize is used.
+	MaxBatchSize int
+
+	// Metrics contains the counters. It is safe to pass nil-counters.
+	Metrics BatchHandlerMetrics
+}
+
+// HandleCMSRequests handles the requests of the batch one after the other.
+//
+// A failing request does not abort the batch. The returned results are in the
+// order of the requests, and the result of a failed request contains the
+// status error that the handler returned for it. The client can thus retry
+// exactly the requests with a result code other than codes.OK. If the context
+// is done, the remaining requests are not handled and their results contain
+// the corresponding codes.Canceled or codes.DeadlineExceeded error.
+//
+// An error is only returned if the batch is rejected as a whole, because it is
+// empty or exceeds the maximum batch size. In that case, no request is
+// handled.
+func (h BatchHandler) HandleCMSRequests(
+	ctx context.Context,
+	reqs []*cppb.ChainRenewalRequest,
+) ([]BatchResult, error) {
+
+	maxSize := h.MaxBatchSize
+	if maxSize == 0 {
+		maxSize = DefaultMaxBatchSize
+	}
+	if len(reqs) == 0 {
+		metrics.CounterInc(h.Metrics.Error)
+		return nil, status.Error(codes.InvalidArgument, "empty batch")
+	}
+	if len(reqs) > maxSize {
+		metrics.CounterInc(h.Metrics.Error)
+		return nil, status.Errorf(codes.InvalidArgument,
+			"batch too large: %d requests, maximum %d", len(reqs), maxSize)
+	}
+
+	results := make([]BatchResult, len(reqs))
+	var failed int
+	for i, req := range reqs {
+		results[i] = h.handle(ctx, req)
+		if results[i].Err != nil {
+			failed++
+		}
+	}
+	switch failed {
+	case 0:
+		metrics.CounterInc(h.Metrics.Success)
+	case len(reqs):
+		metrics.CounterInc(h.Metrics.Error)
+	default:
+		metrics.CounterInc(h.Metrics.PartialError)
+	}
+	return results, nil
+}
+
+func (h BatchHandler) handle(ctx context.Context, req *cppb.ChainRenewalRequest) BatchResult {
+	if err := ctx.Err(); err != nil {
+		return BatchResult{Err: status.FromContextError(err).Err()}
+	}
+	if req.GetCmsSignedRequest() == nil {
+		return BatchResult{Err: status.Error(codes.InvalidArgument, "signed request missing")}
+	}
+	chain, err := h.Handler.HandleCMSRequest(ctx, req)
+	if err != nil {
+		if _, ok := status.FromError(err); !ok {
+			err = status.Error(codes.Unknown, err.Error())
+		}
+		return BatchResult{Err: err}
+	}
+	return BatchResult{Chain: chain}
+}
@@ -0,0 +1,138 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_test
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/scionproto/scion/pkg/metrics"
+	cppb "github.com/scionproto/scion/pkg/proto/control_plane"
+	"github.com/scionproto/scion/private/ca/renewal/grpc"
+	"github.com/scionproto/scion/private/ca/renewal/grpc/mock_grpc"
+)
+
+func TestBatchHandlerHandleCMSRequests(t *testing.T) {
+	req := func(s string) *cppb.ChainRenewalRequest {
+		return &cppb.ChainRenewalRequest{CmsSignedRequest: []byte(s)}
+	}
+	handler := func(ctrl *gomock.Controller) grpc.CMSRequestHandler {
+		h := mock_grpc.NewMockCMSRequestHandler(ctrl)
+		h.EXPECT().HandleCMSRequest(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, r *cppb.ChainRenewalRequest) ([]*x509.Certificate, error) {
+				switch string(r.CmsSignedRequest) {
+				case "invalid":
+					return nil, status.Error(codes.InvalidArgument, "failed to verify")
+				case "plain error":
+					return nil, mockErr
+				}
+				return mockIssuedChain, nil
+			},
+		).AnyTimes()
+		return h
+	}
+
+	tests := map[string]struct {
+		Requests  []*cppb.ChainRenewalRequest
+		Context   func() context.Context
+		Codes     []codes.Code
+		Assertion assert.ErrorAssertionFunc
+		Metric    string
+	}{
+		"empty": {
+			Assertion: assert.Error,
+			Metric:    "err",
+		},
+		"too large": {
+			Requests:  []*cppb.ChainRenewalRequest{req("a"), req("b"), req("c")},
+			Assertion: assert.Error,
+			Metric:    "err",
+		},
+		"all valid": {
+			Requests:  []*cppb.ChainRenewalRequest{req("a"), req("b")},
+			Codes:     []codes.Code{codes.OK, codes.OK},
+			Assertion: assert.NoError,
+			Metric:    "ok_success",
+		},
+		"partial": {
+			Requests:  []*cppb.ChainRenewalRequest{req("invalid"), req("a")},
+			Codes:     []codes.Code{codes.InvalidArgument, codes.OK},
+			Assertion: assert.NoError,
+			Metric:    "err_partial",
+		},
+		"all failed": {
+			Requests:  []*cppb.ChainRenewalRequest{{}, req("plain error")},
+			Codes:     []codes.Code{codes.InvalidArgument, codes.Unknown},
+			Assertion: assert.NoError,
+			Metric:    "err",
+		},
+		"canceled": {
+			Requests: []*cppb.ChainRenewalRequest{req("a"), req("b")},
+			Context: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			Codes:     []codes.Code{codes.Canceled, codes.Canceled},
+			Assertion: assert.NoError,
+			Metric:    "err",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			ctr := metrics.NewTestCounter()
+			h := grpc.BatchHandler{
+				Handler:      handler(ctrl),
+				MaxBatchSize: 2,
+				Metrics: grpc.BatchHandlerMetrics{
+					Success:      ctr.With("result", "ok_success"),
+					PartialError: ctr.With("result", "err_partial"),
+					Error:        ctr.With("result", "err"),
+				},
+			}
+			ctx := context.Background()
+			if tc.Context != nil {
+				ctx = tc.Context()
+			}
+			results, err := h.HandleCMSRequests(ctx, tc.Requests)
+			tc.Assertion(t, err)
+			require.Len(t, results, len(tc.Codes))
+			for i, r := range results {
+				assert.Equal(t, tc.Codes[i], r.Code(), i)
+				if r.Code() == codes.OK {
+					assert.Equal(t, mockIssuedChain, r.Chain, i)
+				} else {
+					assert.Nil(t, r.Chain, i)
+				}
+			}
+			for _, res := range []string{"ok_success", "err_partial", "err"} {
+				expected := float64(0)
+				if res == tc.Metric {
+					expected = 1
+				}
+				assert.Equal(t, expected, metrics.CounterValue(ctr.With("result", res)), res)
+			}
+		})
+	}
+}
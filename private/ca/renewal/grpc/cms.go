@@ -17,7 +17,10 @@ package grpc
 import (
 	"context"
 	"crypto/x509"
+	"errors"
+	"strconv"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -26,6 +29,7 @@ import (
 	"github.com/scionproto/scion/pkg/metrics"
 	cppb "github.com/scionproto/scion/pkg/proto/control_plane"
 	"github.com/scionproto/scion/pkg/scrypto/cppki"
+	"github.com/scionproto/scion/private/ca/renewal"
 )
 
 // ChainBuilder creates a chain for the given CSR.
@@ -38,15 +42,82 @@ type RenewalRequestVerifier interface {
 	VerifyCMSSignedRenewalRequest(context.Context, []byte) (*x509.CertificateRequest, error)
 }
 
-// CMSHandlerMetrics contains the counters for the CMSHandler
+// RateLimiter limits the rate of renewal requests per client AS.
+type RateLimiter interface {
+	// Allow reports whether a renewal request of the AS is allowed. It is
+	// called once for every verified request.
+	Allow(addr.IA) bool
+}
+
+// ErrorDomain is the domain of the errdetails.ErrorInfo attached to the status
+// errors returned by the CMS handler.
+const ErrorDomain = "renewal.scion.org"
+
+// The reasons of the errdetails.ErrorInfo attached to the status errors
+// returned by the CMS handler. The metadata of the error info contains the
+// ISD-AS of the requesting AS under the key "isd_as", if it is known.
+const (
+	ReasonContextDone       = "CONTEXT_DONE"
+	ReasonRequestTooLarge   = "REQUEST_TOO_LARGE"
+	ReasonMalformedRequest  = "MALFORMED_REQUEST"
+	ReasonNotClient         = "NOT_CLIENT"
+	ReasonRateLimited       = "RATE_LIMITED"
+	ReasonVerifyFailed      = "VERIFY_FAILED"
+	ReasonInvalidCSR        = "INVALID_CSR"
+	ReasonReplayed          = "REPLAYED"
+	ReasonChainCreateFailed = "CHAIN_CREATE_FAILED"
+	ReasonExcessiveValidity = "EXCESSIVE_VALIDITY"
+)
+
+// DefaultMaxRequestSize is the default maximum size of a CMS signed request in
+// bytes.
+const DefaultMaxRequestSize = 64 * 1024
+
+// CMSHandlerMetrics contains the metrics for the CMSHandler
 type CMSHandlerMetrics struct {
 	Success metrics.Counter
+	// IssuedValidity observes the validity period in hours of the AS
+	// certificate of each issued chain.
+	IssuedValidity metrics.Histogram
 
+	// ContextDone counts the requests that were dropped because the context
+	// was done before the request was handled, e.g., because the deadline of
+	// the client expired.
+	ContextDone   metrics.Counter
 	DatabaseError metrics.Counter
-	InternalError metrics.Counter
-	NotFoundError metrics.Counter
-	ParseError    metrics.Counter
-	VerifyError   metrics.Counter
+	// ExcessiveValidity counts the requests that were rejected, because they
+	// request a validity period that exceeds the CA policy.
+	ExcessiveValidity metrics.Counter
+	InternalError     metrics.Counter
+	InvalidCSR        metrics.Counter
+	NotFoundError     metrics.Counter
+	ParseError        metrics.Counter
+	RateLimited       metrics.Counter
+	Replayed          metrics.Counter
+	RequestTooLarge   metrics.Counter
+	VerifyError       metrics.Counter
+
+	// ISDASLabel adds the label "isd_as" with the ISD-AS of the requester to
+	// the counters. The label is only set to the ISD-AS once the request is
+	// verified and is empty otherwise, such that unverified requests cannot
+	// create arbitrary time series. The number of time series grows with the
+	// number of requesting ASes. Operators that cannot afford this leave it
+	// unset.
+	ISDASLabel bool
+}
+
+// inc increments the counter. If the ISD-AS label is enabled, the counter is
+// labeled with the verified ISD-AS of the requester, or with an empty value
+// if the requester is not verified.
+func (m CMSHandlerMetrics) inc(c metrics.Counter, verified addr.IA) {
+	if m.ISDASLabel {
+		var label string
+		if !verified.IsZero() {
+			label = verified.String()
+		}
+		c = metrics.CounterWith(c, "isd_as", label)
+	}
+	metrics.CounterInc(c)
 }
 
 // CMS handles CMS requests.
@@ -54,12 +125,31 @@ type CMS struct {
 	Verifier     RenewalRequestVerifier
 	ChainBuilder ChainBuilder
 	IA           addr.IA
+	// MaxRequestSize is the maximum size of the CMS signed request in bytes.
+	// Larger requests are rejected before they are parsed. If zero,
+	// DefaultMaxRequestSize is used.
+	MaxRequestSize int
+	// RateLimiter limits the requests per client AS. It is only consulted
+	// once the request is verified, such that requests that carry the public
+	// chain of another AS cannot consume the budget of that AS. If nil,
+	// requests are not limited.
+	RateLimiter RateLimiter
+	// CSRValidator validates the CSR of verified requests before the chain is
+	// created. If nil, the CSR is not validated further.
+	CSRValidator CSRValidator
+	// ReplayCache records the verified requests and rejects a request that is
+	// already recorded. Requests are only recorded after verification, such
+	// that unverified requests cannot evict recorded ones. If nil, replays are
+	// not detected.
+	ReplayCache *ReplayCache
 
-	// Metrics contains the counters. It is safe to pass nil-counters.
+	// Metrics contains the counters and the histogram. It is safe to pass
+	// nil-metrics.
 	Metrics CMSHandlerMetrics
 }
 
-// HandleCMSRequest handles a request with CMS signature.
+// HandleCMSRequest handles a request with CMS signature. The returned errors
+// are status errors with an errdetails.ErrorInfo that carries the reason.
 func (s CMS) HandleCMSRequest(
 	ctx context.Context,
 	req *cppb.ChainRenewalRequest,
@@ -67,47 +157,136 @@ func (s CMS) HandleCMSRequest(
 
 	logger := log.FromCtx(ctx)
 
-	issuerIA, err := extractIssuerIA(req.CmsSignedRequest, logger)
+	// Do not spend any effort on requests that are already abandoned.
+	if err := ctx.Err(); err != nil {
+		logger.Debug("Renewal request context done", "err", err)
+		s.Metrics.inc(s.Metrics.ContextDone, 0)
+		return nil, statusError(status.FromContextError(err).Code(), err.Error(),
+			ReasonContextDone)
+	}
+
+	maxSize := s.MaxRequestSize
+	if maxSize == 0 {
+		maxSize = DefaultMaxRequestSize
+	}
+	if len(req.CmsSignedRequest) > maxSize {
+		logger.Debug("Renewal request too large",
+			"size", len(req.CmsSignedRequest), "max_size", maxSize)
+		s.Metrics.inc(s.Metrics.RequestTooLarge, 0)
+		return nil, statusError(codes.InvalidArgument, "request too large",
+			ReasonRequestTooLarge, "max_size", strconv.Itoa(maxSize))
+	}
+
+	clientIA, issuerIA, err := extractIAs(req.CmsSignedRequest, logger)
 	if err != nil {
-		metrics.CounterInc(s.Metrics.ParseError)
+		s.Metrics.inc(s.Metrics.ParseError, 0)
 		return nil, err
 	}
 	if issuerIA.ISD() != s.IA.ISD() {
 		logger.Debug("Renewal requester is not part of the ISD", "issuer_isd_as", issuerIA)
-		metrics.CounterInc(s.Metrics.NotFoundError)
-		return nil, status.Error(codes.PermissionDenied, "not a client")
+		s.Metrics.inc(s.Metrics.NotFoundError, 0)
+		return nil, statusError(codes.PermissionDenied, "not a client",
+			ReasonNotClient, "isd_as", clientIA.String(), "issuer_isd_as", issuerIA.String())
 	}
-
 	csr, err := s.Verifier.VerifyCMSSignedRenewalRequest(ctx, req.CmsSignedRequest)
 	if err != nil {
 		logger.Info("Failed to verify certificate chain renewal request", "err", err)
-		metrics.CounterInc(s.Metrics.VerifyError)
-		return nil, status.Error(codes.InvalidArgument, "failed to verify")
+		s.Metrics.inc(s.Metrics.VerifyError, 0)
+		return nil, statusError(codes.InvalidArgument, "failed to verify",
+			ReasonVerifyFailed, "isd_as", clientIA.String())
+	}
+	if s.RateLimiter != nil && !s.RateLimiter.Allow(clientIA) {
+		logger.Debug("Renewal request rate limited", "isd_as", clientIA)
+		s.Metrics.inc(s.Metrics.RateLimited, clientIA)
+		return nil, statusError(codes.ResourceExhausted, "rate limited",
+			ReasonRateLimited, "isd_as", clientIA.String())
+	}
+	if s.CSRValidator != nil {
+		if err := s.CSRValidator.ValidateCSR(csr, clientIA); err != nil {
+			logger.Info("Rejected certificate signing request", "err", err)
+			s.Metrics.inc(s.Metrics.InvalidCSR, clientIA)
+			return nil, statusError(codes.InvalidArgument, "invalid CSR",
+				ReasonInvalidCSR, "isd_as", clientIA.String())
+		}
+	}
+	if s.ReplayCache != nil && !s.ReplayCache.Add(req.CmsSignedRequest) {
+		logger.Info("Rejected replayed renewal request", "isd_as", clientIA)
+		s.Metrics.inc(s.Metrics.Replayed, clientIA)
+		return nil, statusError(codes.AlreadyExists, "replayed request",
+			ReasonReplayed, "isd_as", clientIA.String())
 	}
 
 	newClientChain, err := s.ChainBuilder.CreateChain(ctx, csr)
+	if errors.Is(err, renewal.ErrValidityExceedsPolicy) {
+		// The request can never succeed, it is not removed from the replay
+		// cache and the client must not retry it.
+		logger.Info("Rejected renewal request with excessive validity", "err", err)
+		s.Metrics.inc(s.Metrics.ExcessiveValidity, clientIA)
+		return nil, statusError(codes.InvalidArgument, "requested validity exceeds policy",
+			ReasonExcessiveValidity, "isd_as", clientIA.String())
+	}
 	if err != nil {
 		logger.Info("Failed to create renewed certificate chain", "err", err)
-		metrics.CounterInc(s.Metrics.InternalError)
-		return nil, status.Error(codes.Unavailable, "failed to create chain")
+		// The request was not handled, allow the client to retry it.
+		if s.ReplayCache != nil {
+			s.ReplayCache.Remove(req.CmsSignedRequest)
+		}
+		s.Metrics.inc(s.Metrics.InternalError, clientIA)
+		return nil, statusError(codes.Unavailable, "failed to create chain",
+			ReasonChainCreateFailed, "isd_as", clientIA.String())
 	}
 
-	metrics.CounterInc(s.Metrics.Success)
+	s.Metrics.inc(s.Metrics.Success, clientIA)
+	if len(newClientChain) > 0 {
+		asCert := newClientChain[0]
+		metrics.HistogramObserve(s.Metrics.IssuedValidity,
+			asCert.NotAfter.Sub(asCert.NotBefore).Hours())
+	}
 	return newClientChain, nil
 }
 
-func extractIssuerIA(raw []byte, logger log.Logger) (addr.IA, error) {
+// extractIAs returns the IAs of the AS certificate and of the issuer
+// certificate of the unverified chain in the request.
+func extractIAs(raw []byte, logger log.Logger) (addr.IA, addr.IA, error) {
 	chain, err := extractChain(raw)
 	if err != nil {
 		logger.Debug("Failed to extract client certificate", "err", err)
-		return 0, status.Error(codes.InvalidArgument,
-			"request malformed: cannot extract client chain")
+		return 0, 0, statusError(codes.InvalidArgument,
+			"request malformed: cannot extract client chain", ReasonMalformedRequest)
+	}
+	clientIA, err := cppki.ExtractIA(chain[0].Subject)
+	if err != nil {
+		logger.Debug("Failed to extract IA from client certificate", "err", err)
+		return 0, 0, statusError(codes.InvalidArgument,
+			"request malformed: cannot extract client subject", ReasonMalformedRequest)
 	}
 	issuerIA, err := cppki.ExtractIA(chain[1].Subject)
 	if err != nil {
 		logger.Debug("Failed to extract IA from issuer certificate", "err", err)
-		return 0, status.Error(codes.InvalidArgument,
-			"request malformed: cannot extract issuer subject")
+		return 0, 0, statusError(codes.InvalidArgument,
+			"request malformed: cannot extract issuer subject", ReasonMalformedRequest,
+			"isd_as", clientIA.String())
+	}
+	return clientIA, issuerIA, nil
+}
+
+// statusError returns a status error with an errdetails.ErrorInfo with the
+// reason and the metadata given as key-value pairs.
+func statusError(c codes.Code, msg, reason string, metadata ...string) error {
+	info := &errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: ErrorDomain,
+	}
+	if len(metadata) > 0 {
+		info.Metadata = make(map[string]string, len(metadata)/2)
+		for i := 0; i+1 < len(metadata); i += 2 {
+			info.Metadata[metadata[i]] = metadata[i+1]
+		}
+	}
+	st, err := status.New(c, msg).WithDetails(info)
+	if err != nil {
+		// Only fails if the details cannot be marshaled.
+		return status.Error(c, msg)
 	}
-	return issuerIA, nil
+	return st.Err()
 }
@@ -24,6 +24,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -84,8 +85,12 @@ func TestCMSHandleCMSRequest(t *testing.T) {
 		Verifier     func(ctrl *gomock.Controller) grpc.RenewalRequestVerifier
 		ChainBuilder func(ctrl *gomock.Controller) grpc.ChainBuilder
 		CMSSigner    func(ctrl *gomock.Controller) grpc.CMSSigner
+		RateLimiter  func(ctrl *gomock.Controller) grpc.RateLimiter
+		CSRValidator func(ctrl *gomock.Controller) grpc.CSRValidator
 		IA           addr.IA
 		Metric       string
+		Reason       string
+		ISDAS        string
 		Assertion    assert.ErrorAssertionFunc
 		Code         codes.Code
 	}{
@@ -108,6 +113,28 @@ func TestCMSHandleCMSRequest(t *testing.T) {
 			Assertion: assert.Error,
 			Code:      codes.InvalidArgument,
 			Metric:    "err_parse",
+			Reason:    grpc.ReasonMalformedRequest,
+		},
+		"oversized request": {
+			Request: func(t *testing.T) *cppb.ChainRenewalRequest {
+				return &cppb.ChainRenewalRequest{
+					CmsSignedRequest: make([]byte, grpc.DefaultMaxRequestSize+1),
+				}
+			},
+			Verifier: func(ctrl *gomock.Controller) grpc.RenewalRequestVerifier {
+				return mock_grpc.NewMockRenewalRequestVerifier(ctrl)
+			},
+			ChainBuilder: func(ctrl *gomock.Controller) grpc.ChainBuilder {
+				return mock_grpc.NewMockChainBuilder(ctrl)
+			},
+			CMSSigner: func(ctrl *gomock.Controller) grpc.CMSSigner {
+				return mock_grpc.NewMockCMSSigner(ctrl)
+			},
+			IA:        addr.MustParseIA("1-ff00:0:110"),
+			Assertion: assert.Error,
+			Code:      codes.InvalidArgument,
+			Metric:    "err_invalid_request",
+			Reason:    grpc.ReasonRequestTooLarge,
 		},
 		"not client": {
 			Request: func(t *testing.T) *cppb.ChainRenewalRequest {
@@ -126,6 +153,36 @@ func TestCMSHandleCMSRequest(t *testing.T) {
 			Assertion: assert.Error,
 			Code:      codes.PermissionDenied,
 			Metric:    "err_notfound",
+			Reason:    grpc.ReasonNotClient,
+			ISDAS:     "1-ff00:0:111",
+		},
+		"rate limited": {
+			Request: func(t *testing.T) *cppb.ChainRenewalRequest {
+				return signedReq
+			},
+			Verifier: func(ctrl *gomock.Controller) grpc.RenewalRequestVerifier {
+				v := mock_grpc.NewMockRenewalRequestVerifier(ctrl)
+				v.EXPECT().VerifyCMSSignedRenewalRequest(context.Background(),
+					signedReq.CmsSignedRequest).Return(mockCSR, nil)
+				return v
+			},
+			ChainBuilder: func(ctrl *gomock.Controller) grpc.ChainBuilder {
+				return mock_grpc.NewMockChainBuilder(ctrl)
+			},
+			CMSSigner: func(ctrl *gomock.Controller) grpc.CMSSigner {
+				return mock_grpc.NewMockCMSSigner(ctrl)
+			},
+			RateLimiter: func(ctrl *gomock.Controller) grpc.RateLimiter {
+				l := mock_grpc.NewMockRateLimiter(ctrl)
+				l.EXPECT().Allow(addr.MustParseIA("1-ff00:0:111")).Return(false)
+				return l
+			},
+			IA:        addr.MustParseIA("1-ff00:0:110"),
+			Assertion: assert.Error,
+			Code:      codes.ResourceExhausted,
+			Metric:    "err_rate_limited",
+			Reason:    grpc.ReasonRateLimited,
+			ISDAS:     "1-ff00:0:111",
 		},
 		"invalid signature": {
 			Request: func(t *testing.T) *cppb.ChainRenewalRequest {
@@ -149,6 +206,65 @@ func TestCMSHandleCMSRequest(t *testing.T) {
 			Assertion: assert.Error,
 			Code:      codes.InvalidArgument,
 			Metric:    "err_verify",
+			Reason:    grpc.ReasonVerifyFailed,
+			ISDAS:     "1-ff00:0:111",
+		},
+		"invalid signature does not consume rate limit": {
+			Request: func(t *testing.T) *cppb.ChainRenewalRequest {
+				return signedReq
+			},
+			Verifier: func(ctrl *gomock.Controller) grpc.RenewalRequestVerifier {
+				v := mock_grpc.NewMockRenewalRequestVerifier(ctrl)
+				v.EXPECT().VerifyCMSSignedRenewalRequest(
+					context.Background(),
+					signedReq.CmsSignedRequest,
+				).Return(nil, mockErr)
+				return v
+			},
+			ChainBuilder: func(ctrl *gomock.Controller) grpc.ChainBuilder {
+				return mock_grpc.NewMockChainBuilder(ctrl)
+			},
+			CMSSigner: func(ctrl *gomock.Controller) grpc.CMSSigner {
+				return mock_grpc.NewMockCMSSigner(ctrl)
+			},
+			// The limiter fails the test if it is consulted.
+			RateLimiter: func(ctrl *gomock.Controller) grpc.RateLimiter {
+				return mock_grpc.NewMockRateLimiter(ctrl)
+			},
+			IA:        addr.MustParseIA("1-ff00:0:110"),
+			Assertion: assert.Error,
+			Code:      codes.InvalidArgument,
+			Metric:    "err_verify",
+			Reason:    grpc.ReasonVerifyFailed,
+			ISDAS:     "1-ff00:0:111",
+		},
+		"excessive validity": {
+			Request: func(t *testing.T) *cppb.ChainRenewalRequest {
+				return signedReq
+			},
+			Verifier: func(ctrl *gomock.Controller) grpc.RenewalRequestVerifier {
+				v := mock_grpc.NewMockRenewalRequestVerifier(ctrl)
+				v.EXPECT().VerifyCMSSignedRenewalRequest(
+					context.Background(),
+					signedReq.CmsSignedRequest,
+				).Return(mockCSR, nil)
+				return v
+			},
+			ChainBuilder: func(ctrl *gomock.Controller) grpc.ChainBuilder {
+				cb := mock_grpc.NewMockChainBuilder(ctrl)
+				cb.EXPECT().CreateChain(gomock.Any(), gomock.Any()).Return(nil,
+					serrors.JoinNoStack(renewal.ErrValidityExceedsPolicy, nil, "max", "1h"))
+				return cb
+			},
+			CMSSigner: func(ctrl *gomock.Controller) grpc.CMSSigner {
+				return mock_grpc.NewMockCMSSigner(ctrl)
+			},
+			IA:        addr.MustParseIA("1-ff00:0:110"),
+			Assertion: assert.Error,
+			Code:      codes.InvalidArgument,
+			Metric:    "err_excessive_validity",
+			Reason:    grpc.ReasonExcessiveValidity,
+			ISDAS:     "1-ff00:0:111",
 		},
 		"failed to build chain": {
 			Request: func(t *testing.T) *cppb.ChainRenewalRequest {
@@ -174,6 +290,8 @@ func TestCMSHandleCMSRequest(t *testing.T) {
 			Assertion: assert.Error,
 			Code:      codes.Unavailable,
 			Metric:    "err_internal",
+			Reason:    grpc.ReasonChainCreateFailed,
+			ISDAS:     "1-ff00:0:111",
 		},
 		"valid": {
 			Request: func(t *testing.T) *cppb.ChainRenewalRequest {
@@ -200,6 +318,94 @@ func TestCMSHandleCMSRequest(t *testing.T) {
 			Code:      codes.OK,
 			Metric:    "ok_success",
 		},
+		"invalid CSR": {
+			Request: func(t *testing.T) *cppb.ChainRenewalRequest {
+				return signedReq
+			},
+			Verifier: func(ctrl *gomock.Controller) grpc.RenewalRequestVerifier {
+				v := mock_grpc.NewMockRenewalRequestVerifier(ctrl)
+				v.EXPECT().VerifyCMSSignedRenewalRequest(context.Background(),
+					signedReq.CmsSignedRequest).Return(mockCSR, nil)
+				return v
+			},
+			ChainBuilder: func(ctrl *gomock.Controller) grpc.ChainBuilder {
+				return mock_grpc.NewMockChainBuilder(ctrl)
+			},
+			CMSSigner: func(ctrl *gomock.Controller) grpc.CMSSigner {
+				return mock_grpc.NewMockCMSSigner(ctrl)
+			},
+			CSRValidator: func(ctrl *gomock.Controller) grpc.CSRValidator {
+				v := mock_grpc.NewMockCSRValidator(ctrl)
+				v.EXPECT().ValidateCSR(mockCSR, addr.MustParseIA("1-ff00:0:111")).Return(mockErr)
+				return v
+			},
+			IA:        addr.MustParseIA("1-ff00:0:110"),
+			Assertion: assert.Error,
+			Code:      codes.InvalidArgument,
+			Metric:    "err_invalid_csr",
+			Reason:    grpc.ReasonInvalidCSR,
+			ISDAS:     "1-ff00:0:111",
+		},
+		"valid CSR": {
+			Request: func(t *testing.T) *cppb.ChainRenewalRequest {
+				return signedReq
+			},
+			Verifier: func(ctrl *gomock.Controller) grpc.RenewalRequestVerifier {
+				v := mock_grpc.NewMockRenewalRequestVerifier(ctrl)
+				v.EXPECT().VerifyCMSSignedRenewalRequest(context.Background(),
+					signedReq.CmsSignedRequest).Return(mockCSR, nil)
+				return v
+			},
+			ChainBuilder: func(ctrl *gomock.Controller) grpc.ChainBuilder {
+				cb := mock_grpc.NewMockChainBuilder(ctrl)
+				cb.EXPECT().CreateChain(gomock.Any(), gomock.Any()).Return(mockIssuedChain, nil)
+				return cb
+			},
+			CMSSigner: func(ctrl *gomock.Controller) grpc.CMSSigner {
+				signer := mock_grpc.NewMockCMSSigner(ctrl)
+				signer.EXPECT().SignCMS(gomock.Any(), gomock.Any())
+				return signer
+			},
+			CSRValidator: func(ctrl *gomock.Controller) grpc.CSRValidator {
+				v := mock_grpc.NewMockCSRValidator(ctrl)
+				v.EXPECT().ValidateCSR(mockCSR, addr.MustParseIA("1-ff00:0:111")).Return(nil)
+				return v
+			},
+			IA:        addr.MustParseIA("1-ff00:0:110"),
+			Assertion: assert.NoError,
+			Code:      codes.OK,
+			Metric:    "ok_success",
+		},
+		"valid rate limiter allows": {
+			Request: func(t *testing.T) *cppb.ChainRenewalRequest {
+				return signedReq
+			},
+			Verifier: func(ctrl *gomock.Controller) grpc.RenewalRequestVerifier {
+				v := mock_grpc.NewMockRenewalRequestVerifier(ctrl)
+				v.EXPECT().VerifyCMSSignedRenewalRequest(context.Background(),
+					signedReq.CmsSignedRequest).Return(mockCSR, nil)
+				return v
+			},
+			ChainBuilder: func(ctrl *gomock.Controller) grpc.ChainBuilder {
+				cb := mock_grpc.NewMockChainBuilder(ctrl)
+				cb.EXPECT().CreateChain(gomock.Any(), gomock.Any()).Return(mockIssuedChain, nil)
+				return cb
+			},
+			CMSSigner: func(ctrl *gomock.Controller) grpc.CMSSigner {
+				signer := mock_grpc.NewMockCMSSigner(ctrl)
+				signer.EXPECT().SignCMS(gomock.Any(), gomock.Any())
+				return signer
+			},
+			RateLimiter: func(ctrl *gomock.Controller) grpc.RateLimiter {
+				l := mock_grpc.NewMockRateLimiter(ctrl)
+				l.EXPECT().Allow(addr.MustParseIA("1-ff00:0:111")).Return(true)
+				return l
+			},
+			IA:        addr.MustParseIA("1-ff00:0:110"),
+			Assertion: assert.NoError,
+			Code:      codes.OK,
+			Metric:    "ok_success",
+		},
 	}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -211,23 +417,47 @@ func TestCMSHandleCMSRequest(t *testing.T) {
 				ChainBuilder: tc.ChainBuilder(ctrl),
 				IA:           tc.IA,
 				Metrics: grpc.CMSHandlerMetrics{
-					DatabaseError: ctr.With("result", "err_database"),
-					InternalError: ctr.With("result", "err_internal"),
-					NotFoundError: ctr.With("result", "err_notfound"),
-					ParseError:    ctr.With("result", "err_parse"),
-					VerifyError:   ctr.With("result", "err_verify"),
-					Success:       ctr.With("result", "ok_success"),
+					ContextDone:       ctr.With("result", "err_context_done"),
+					DatabaseError:     ctr.With("result", "err_database"),
+					ExcessiveValidity: ctr.With("result", "err_excessive_validity"),
+					InternalError:     ctr.With("result", "err_internal"),
+					InvalidCSR:        ctr.With("result", "err_invalid_csr"),
+					NotFoundError:     ctr.With("result", "err_notfound"),
+					ParseError:        ctr.With("result", "err_parse"),
+					RateLimited:       ctr.With("result", "err_rate_limited"),
+					Replayed:          ctr.With("result", "err_replayed"),
+					RequestTooLarge:   ctr.With("result", "err_invalid_request"),
+					VerifyError:       ctr.With("result", "err_verify"),
+					Success:           ctr.With("result", "ok_success"),
 				},
 			}
+			if tc.RateLimiter != nil {
+				s.RateLimiter = tc.RateLimiter(ctrl)
+			}
+			if tc.CSRValidator != nil {
+				s.CSRValidator = tc.CSRValidator(ctrl)
+			}
 			_, err := s.HandleCMSRequest(context.Background(), tc.Request(t))
 			tc.Assertion(t, err)
 			assert.Equal(t, tc.Code, status.Code(err))
+			if tc.Reason != "" {
+				info := errorInfo(t, err)
+				assert.Equal(t, grpc.ErrorDomain, info.Domain)
+				assert.Equal(t, tc.Reason, info.Reason)
+				assert.Equal(t, tc.ISDAS, info.Metadata["isd_as"])
+			}
 			for _, res := range []string{
+				"err_context_done",
 				"err_database",
+				"err_excessive_validity",
 				"err_internal",
+				"err_invalid_csr",
 				"err_unavailable",
 				"err_notfound",
 				"err_parse",
+				"err_rate_limited",
+				"err_replayed",
+				"err_invalid_request",
 				"err_verify",
 				"ok_success",
 			} {
@@ -240,3 +470,269 @@ func TestCMSHandleCMSRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestCMSHandleCMSRequestContextDone(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	tests := map[string]struct {
+		Ctx  context.Context
+		Code codes.Code
+	}{
+		"canceled": {
+			Ctx:  canceled,
+			Code: codes.Canceled,
+		},
+		"deadline exceeded": {
+			Ctx:  expired,
+			Code: codes.DeadlineExceeded,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			ctr := metrics.NewTestCounter()
+			// The mocks fail the test if the request is verified or a chain
+			// is created.
+			s := &grpc.CMS{
+				Verifier:     mock_grpc.NewMockRenewalRequestVerifier(ctrl),
+				ChainBuilder: mock_grpc.NewMockChainBuilder(ctrl),
+				IA:           addr.MustParseIA("1-ff00:0:110"),
+				Metrics: grpc.CMSHandlerMetrics{
+					ContextDone: ctr,
+				},
+			}
+			_, err := s.HandleCMSRequest(tc.Ctx, &cppb.ChainRenewalRequest{
+				CmsSignedRequest: []byte("dummy request"),
+			})
+			assert.Equal(t, tc.Code, status.Code(err))
+			assert.Equal(t, grpc.ReasonContextDone, errorInfo(t, err).Reason)
+			assert.Equal(t, float64(1), metrics.CounterValue(ctr))
+		})
+	}
+}
+
+func TestCMSHandleCMSRequestReplay(t *testing.T) {
+	clientKey, chain := genChain(t)
+	signedReq, err := renewal.NewChainRenewalRequest(context.Background(), mockCSR.Raw,
+		trust.Signer{
+			PrivateKey: clientKey,
+			Algorithm:  signed.ECDSAWithSHA256,
+			ChainValidity: cppki.Validity{
+				NotBefore: time.Now(),
+				NotAfter:  time.Now().Add(time.Hour),
+			},
+			Expiration:   time.Now().Add(time.Hour - time.Minute),
+			IA:           addr.MustParseIA("1-ff00:0:111"),
+			SubjectKeyID: chain[0].SubjectKeyId,
+			Chain:        chain,
+		},
+	)
+	require.NoError(t, err)
+
+	newHandler := func(t *testing.T, cb grpc.ChainBuilder) (*grpc.CMS, metrics.Counter) {
+		ctrl := gomock.NewController(t)
+		v := mock_grpc.NewMockRenewalRequestVerifier(ctrl)
+		v.EXPECT().VerifyCMSSignedRenewalRequest(context.Background(),
+			signedReq.CmsSignedRequest).Return(mockCSR, nil).Times(2)
+		cache, err := grpc.NewReplayCache(10, time.Hour)
+		require.NoError(t, err)
+		replayed := metrics.NewTestCounter()
+		return &grpc.CMS{
+			Verifier:     v,
+			ChainBuilder: cb,
+			IA:           addr.MustParseIA("1-ff00:0:110"),
+			ReplayCache:  cache,
+			Metrics: grpc.CMSHandlerMetrics{
+				Replayed: replayed,
+			},
+		}, replayed
+	}
+
+	t.Run("second request rejected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cb := mock_grpc.NewMockChainBuilder(ctrl)
+		cb.EXPECT().CreateChain(gomock.Any(), gomock.Any()).Return(mockIssuedChain, nil)
+		s, replayed := newHandler(t, cb)
+
+		_, err := s.HandleCMSRequest(context.Background(), signedReq)
+		require.NoError(t, err)
+		_, err = s.HandleCMSRequest(context.Background(), signedReq)
+		assert.Error(t, err)
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+		info := errorInfo(t, err)
+		assert.Equal(t, grpc.ReasonReplayed, info.Reason)
+		assert.Equal(t, "1-ff00:0:111", info.Metadata["isd_as"])
+		assert.Equal(t, float64(1), metrics.CounterValue(replayed))
+	})
+	t.Run("retry after failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cb := mock_grpc.NewMockChainBuilder(ctrl)
+		gomock.InOrder(
+			cb.EXPECT().CreateChain(gomock.Any(), gomock.Any()).Return(nil, mockErr),
+			cb.EXPECT().CreateChain(gomock.Any(), gomock.Any()).Return(mockIssuedChain, nil),
+		)
+		s, replayed := newHandler(t, cb)
+
+		_, err := s.HandleCMSRequest(context.Background(), signedReq)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		_, err = s.HandleCMSRequest(context.Background(), signedReq)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(0), metrics.CounterValue(replayed))
+	})
+	t.Run("no retry after policy rejection", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		cb := mock_grpc.NewMockChainBuilder(ctrl)
+		cb.EXPECT().CreateChain(gomock.Any(), gomock.Any()).
+			Return(nil, renewal.ErrValidityExceedsPolicy)
+		s, replayed := newHandler(t, cb)
+
+		_, err := s.HandleCMSRequest(context.Background(), signedReq)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = s.HandleCMSRequest(context.Background(), signedReq)
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+		assert.Equal(t, float64(1), metrics.CounterValue(replayed))
+	})
+}
+
+func TestCMSHandleCMSRequestIssuedValidity(t *testing.T) {
+	clientKey, chain := genChain(t)
+	signedReq, err := renewal.NewChainRenewalRequest(context.Background(), mockCSR.Raw,
+		trust.Signer{
+			PrivateKey: clientKey,
+			Algorithm:  signed.ECDSAWithSHA256,
+			ChainValidity: cppki.Validity{
+				NotBefore: time.Now(),
+				NotAfter:  time.Now().Add(time.Hour),
+			},
+			Expiration:   time.Now().Add(time.Hour - time.Minute),
+			IA:           addr.MustParseIA("1-ff00:0:111"),
+			SubjectKeyID: chain[0].SubjectKeyId,
+			Chain:        chain,
+		},
+	)
+	require.NoError(t, err)
+	notBefore := time.Now()
+	issued := []*x509.Certificate{
+		{
+			Raw:       []byte("mock issued AS cert"),
+			NotBefore: notBefore,
+			NotAfter:  notBefore.Add(72 * time.Hour),
+		},
+		{Raw: []byte("mock CA cert")},
+	}
+
+	tests := map[string]struct {
+		Chain        []*x509.Certificate
+		Err          error
+		Observations []float64
+	}{
+		"issued": {
+			Chain:        issued,
+			Observations: []float64{72},
+		},
+		"create chain fails": {
+			Err: mockErr,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			v := mock_grpc.NewMockRenewalRequestVerifier(ctrl)
+			v.EXPECT().VerifyCMSSignedRenewalRequest(context.Background(),
+				signedReq.CmsSignedRequest).Return(mockCSR, nil)
+			cb := mock_grpc.NewMockChainBuilder(ctrl)
+			cb.EXPECT().CreateChain(gomock.Any(), gomock.Any()).Return(tc.Chain, tc.Err)
+			validity := &testHistogram{}
+			s := &grpc.CMS{
+				Verifier:     v,
+				ChainBuilder: cb,
+				IA:           addr.MustParseIA("1-ff00:0:110"),
+				Metrics: grpc.CMSHandlerMetrics{
+					IssuedValidity: validity,
+				},
+			}
+			_, err := s.HandleCMSRequest(context.Background(), signedReq)
+			assert.Equal(t, tc.Err == nil, err == nil, err)
+			assert.Equal(t, tc.Observations, validity.observations)
+		})
+	}
+}
+
+func TestCMSHandleCMSRequestISDASLabel(t *testing.T) {
+	clientKey, chain := genChain(t)
+	signedReq, err := renewal.NewChainRenewalRequest(context.Background(), mockCSR.Raw,
+		trust.Signer{
+			PrivateKey: clientKey,
+			Algorithm:  signed.ECDSAWithSHA256,
+			ChainValidity: cppki.Validity{
+				NotBefore: time.Now(),
+				NotAfter:  time.Now().Add(time.Hour),
+			},
+			Expiration:   time.Now().Add(time.Hour - time.Minute),
+			IA:           addr.MustParseIA("1-ff00:0:111"),
+			SubjectKeyID: chain[0].SubjectKeyId,
+			Chain:        chain,
+		},
+	)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		VerifyErr error
+		Enabled   bool
+		Labels    []string
+	}{
+		"verified": {
+			Enabled: true,
+			Labels:  []string{"result", "ok_success", "isd_as", "1-ff00:0:111"},
+		},
+		"not verified": {
+			VerifyErr: mockErr,
+			Enabled:   true,
+			Labels:    []string{"result", "err_verify", "isd_as", ""},
+		},
+		"disabled": {
+			Labels: []string{"result", "ok_success"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			v := mock_grpc.NewMockRenewalRequestVerifier(ctrl)
+			v.EXPECT().VerifyCMSSignedRenewalRequest(context.Background(),
+				signedReq.CmsSignedRequest).Return(mockCSR, tc.VerifyErr)
+			cb := mock_grpc.NewMockChainBuilder(ctrl)
+			cb.EXPECT().CreateChain(gomock.Any(), gomock.Any()).
+				Return(mockIssuedChain, nil).AnyTimes()
+			ctr := metrics.NewTestCounter()
+			s := &grpc.CMS{
+				Verifier:     v,
+				ChainBuilder: cb,
+				IA:           addr.MustParseIA("1-ff00:0:110"),
+				Metrics: grpc.CMSHandlerMetrics{
+					Success:     ctr.With("result", "ok_success"),
+					VerifyError: ctr.With("result", "err_verify"),
+					ISDASLabel:  tc.Enabled,
+				},
+			}
+			_, err := s.HandleCMSRequest(context.Background(), signedReq)
+			assert.Equal(t, tc.VerifyErr == nil, err == nil, err)
+			assert.Equal(t, float64(1), metrics.CounterValue(ctr.With(tc.Labels...)))
+		})
+	}
+}
+
+func errorInfo(t *testing.T, err error) *errdetails.ErrorInfo {
+	t.Helper()
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok, "%T", st.Details()[0])
+	return info
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"time"
+
+	"github.com/scionproto/scion/pkg/addr"
+	"github.com/scionproto/scion/pkg/private/serrors"
+	"github.com/scionproto/scion/pkg/scrypto/cppki"
+	"github.com/scionproto/scion/private/ca/renewal"
+)
+
+// CSRValidator validates the certificate signing request of a renewal
+// request after the request has been verified.
+type CSRValidator interface {
+	// ValidateCSR validates the CSR of the requesting AS.
+	ValidateCSR(csr *x509.CertificateRequest, ia addr.IA) error
+}
+
+// ASProfileValidator is a CSRValidator that rejects CSRs that ask for
+// properties that are not allowed by the SCION AS certificate profile.
+//
+// The CSR must be signed with one of the SCION signature algorithms and the
+// subject must be the requesting AS. If the CSR requests the key usage,
+// extended key usage or basic constraints extensions, they must be compatible
+// with an AS certificate, i.e., only the digital signature key usage, only the
+// server authentication, client authentication and time stamping extended key
+// usages, and no CA.
+type ASProfileValidator struct {
+	// MaxValidity is the maximum validity the CSR may request with the
+	// renewal.OIDExtensionRequestedValidity extension. If zero, the requested
+	// validity is not restricted.
+	MaxValidity time.Duration
+}
+
+// ValidateCSR validates the CSR against the AS certificate profile.
+func (v ASProfileValidator) ValidateCSR(csr *x509.CertificateRequest, ia addr.IA) error {
+	var errs serrors.List
+
+	if !validSignatureAlgorithm(csr.SignatureAlgorithm) {
+		errs = append(errs, serrors.New("invalid signature algorithm",
+			"csr_alg", csr.SignatureAlgorithm, "valid_algs", cppki.ValidSCIONSignatureAlgs))
+	}
+	subjectIA, err := cppki.ExtractIA(csr.Subject)
+	switch {
+	case err != nil:
+		errs = append(errs, serrors.Wrap("extracting ISD-AS from subject", err))
+	case !subjectIA.Equal(ia):
+		errs = append(errs, serrors.New("subject is not the requesting AS",
+			"subject_isd_as", subjectIA, "isd_as", ia))
+	}
+	for _, ext := range csr.Extensions {
+		if err := validateExtension(ext.Id, ext.Value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	requested, ok, err := renewal.RequestedValidity(csr)
+	switch {
+	case err != nil:
+		errs = append(errs, err)
+	case ok && v.MaxValidity > 0 && requested > v.MaxValidity:
+		errs = append(errs, serrors.New("requested validity exceeds maximum",
+			"requested", requested, "max", v.MaxValidity))
+	}
+	return errs.ToError()
+}
+
+func validSignatureAlgorithm(alg x509.SignatureAlgorithm) bool {
+	for _, valid := range cppki.ValidSCIONSignatureAlgs {
+		if alg == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// validateExtension validates the requested key usage, extended key usage and
+// basic constraints extensions. Other extensions are ignored.
+func validateExtension(id asn1.ObjectIdentifier, value []byte) error {
+	switch {
+	case id.Equal(cppki.OIDExtensionKeyUsage):
+		var usage asn1.BitString
+		if rest, err := asn1.Unmarshal(value, &usage); err != nil || len(rest) != 0 {
+			return serrors.New("malformed key usage extension")
+		}
+		for i := 0; i < usage.BitLength; i++ {
+			// Bit 0 is digitalSignature.
+			if i != 0 && usage.At(i) != 0 {
+				return serrors.New("key usage not allowed", "bit", i)
+			}
+		}
+	case id.Equal(cppki.OIDExtensionExtendedKeyUsage):
+		var usages []asn1.ObjectIdentifier
+		if rest, err := asn1.Unmarshal(value, &usages); err != nil || len(rest) != 0 {
+			return serrors.New("malformed extended key usage extension")
+		}
+		for _, usage := range usages {
+			if !usage.Equal(cppki.OIDExtKeyUsageServerAuth) &&
+				!usage.Equal(cppki.OIDExtKeyUsageClientAuth) &&
+				!usage.Equal(cppki.OIDExtKeyUsageTimeStamping) {
+
+				return serrors.New("extended key usage not allowed", "usage", usage)
+			}
+		}
+	case id.Equal(cppki.OIDExtensionBasicConstraints):
+		var constraints struct {
+			IsCA       bool `asn1:"optional"`
+			MaxPathLen int  `asn1:"optional,default:-1"`
+		}
+		if rest, err := asn1.Unmarshal(value, &constraints); err != nil || len(rest) != 0 {
+			return serrors.New("malformed basic constraints extension")
+		}
+		if constraints.IsCA {
+			return serrors.New("basic constraints extension has CA set")
+		}
+	}
+	return nil
+}
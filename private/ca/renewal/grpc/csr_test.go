@@ -0,0 +1,143 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/pkg/addr"
+	"github.com/scionproto/scion/pkg/scrypto/cppki"
+	"github.com/scionproto/scion/private/ca/renewal"
+	"github.com/scionproto/scion/private/ca/renewal/grpc"
+)
+
+func TestASProfileValidator(t *testing.T) {
+	ext := func(id asn1.ObjectIdentifier, v any) pkix.Extension {
+		raw, err := asn1.Marshal(v)
+		require.NoError(t, err)
+		return pkix.Extension{Id: id, Value: raw}
+	}
+	keyUsage := func(bytes byte, length int) pkix.Extension {
+		return ext(cppki.OIDExtensionKeyUsage, asn1.BitString{
+			Bytes:     []byte{bytes},
+			BitLength: length,
+		})
+	}
+	validity := func(d time.Duration) pkix.Extension {
+		return ext(renewal.OIDExtensionRequestedValidity, int64(d/time.Second))
+	}
+	basicConstraints := func(ca bool) pkix.Extension {
+		return ext(cppki.OIDExtensionBasicConstraints, struct {
+			IsCA bool `asn1:"optional"`
+		}{IsCA: ca})
+	}
+	ia := addr.MustParseIA("1-ff00:0:111")
+
+	testCases := map[string]struct {
+		Modify    func(csr *x509.CertificateRequest)
+		Assertion assert.ErrorAssertionFunc
+	}{
+		"valid": {
+			Modify:    func(csr *x509.CertificateRequest) {},
+			Assertion: assert.NoError,
+		},
+		"valid extensions": {
+			Modify: func(csr *x509.CertificateRequest) {
+				csr.Extensions = []pkix.Extension{
+					keyUsage(0x80, 1),
+					ext(cppki.OIDExtensionExtendedKeyUsage, []asn1.ObjectIdentifier{
+						cppki.OIDExtKeyUsageServerAuth,
+						cppki.OIDExtKeyUsageClientAuth,
+						cppki.OIDExtKeyUsageTimeStamping,
+					}),
+					basicConstraints(false),
+					validity(time.Hour),
+				}
+			},
+			Assertion: assert.NoError,
+		},
+		"invalid signature algorithm": {
+			Modify: func(csr *x509.CertificateRequest) {
+				csr.SignatureAlgorithm = x509.SHA256WithRSA
+			},
+			Assertion: assert.Error,
+		},
+		"other subject": {
+			Modify: func(csr *x509.CertificateRequest) {
+				csr.Subject.Names[0].Value = "1-ff00:0:112"
+			},
+			Assertion: assert.Error,
+		},
+		"cert sign key usage": {
+			Modify: func(csr *x509.CertificateRequest) {
+				csr.Extensions = []pkix.Extension{keyUsage(0x84, 6)}
+			},
+			Assertion: assert.Error,
+		},
+		"sensitive voting extended key usage": {
+			Modify: func(csr *x509.CertificateRequest) {
+				csr.Extensions = []pkix.Extension{
+					ext(cppki.OIDExtensionExtendedKeyUsage, []asn1.ObjectIdentifier{
+						cppki.OIDExtKeyUsageTimeStamping,
+						cppki.OIDExtKeyUsageSensitive,
+					}),
+				}
+			},
+			Assertion: assert.Error,
+		},
+		"CA basic constraints": {
+			Modify: func(csr *x509.CertificateRequest) {
+				csr.Extensions = []pkix.Extension{basicConstraints(true)}
+			},
+			Assertion: assert.Error,
+		},
+		"malformed key usage": {
+			Modify: func(csr *x509.CertificateRequest) {
+				csr.Extensions = []pkix.Extension{
+					{Id: cppki.OIDExtensionKeyUsage, Value: []byte("garbage")},
+				}
+			},
+			Assertion: assert.Error,
+		},
+		"excessive validity": {
+			Modify: func(csr *x509.CertificateRequest) {
+				csr.Extensions = []pkix.Extension{validity(3 * 24 * time.Hour)}
+			},
+			Assertion: assert.Error,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			csr := &x509.CertificateRequest{
+				SignatureAlgorithm: x509.ECDSAWithSHA256,
+				Subject: pkix.Name{Names: []pkix.AttributeTypeAndValue{{
+					Type:  cppki.OIDNameIA,
+					Value: "1-ff00:0:111",
+				}}},
+			}
+			tc.Modify(csr)
+			v := grpc.ASProfileValidator{MaxValidity: 2 * 24 * time.Hour}
+			tc.Assertion(t, v.ValidateCSR(csr, ia))
+		})
+	}
+}
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/scionproto/scion/private/ca/renewal/grpc (interfaces: ChainBuilder,RenewalRequestVerifier,CMSSigner,CMSRequestHandler,CAServiceClient)
+// Source: github.com/scionproto/scion/private/ca/renewal/grpc (interfaces: ChainBuilder,RenewalRequestVerifier,CMSSigner,CMSRequestHandler,CAServiceClient,RateLimiter,CSRValidator,AuditLogger)
 
 // Package mock_grpc is a generated GoMock package.
 package mock_grpc
@@ -11,8 +11,10 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	addr "github.com/scionproto/scion/pkg/addr"
 	control_plane "github.com/scionproto/scion/pkg/proto/control_plane"
 	api "github.com/scionproto/scion/private/ca/api"
+	grpc "github.com/scionproto/scion/private/ca/renewal/grpc"
 )
 
 // MockChainBuilder is a mock of ChainBuilder interface.
@@ -209,3 +211,114 @@ func (mr *MockCAServiceClientMockRecorder) PostCertificateRenewal(arg0, arg1, ar
 	varargs := append([]interface{}{arg0, arg1, arg2, arg3}, arg4...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostCertificateRenewal", reflect.TypeOf((*MockCAServiceClient)(nil).PostCertificateRenewal), varargs...)
 }
+
+// MockRateLimiter is a mock of RateLimiter interface.
+type MockRateLimiter struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateLimiterMockRecorder
+}
+
+// MockRateLimiterMockRecorder is the mock recorder for MockRateLimiter.
+type MockRateLimiterMockRecorder struct {
+	mock *MockRateLimiter
+}
+
+// NewMockRateLimiter creates a new mock instance.
+func NewMockRateLimiter(ctrl *gomock.Controller) *MockRateLimiter {
+	mock := &MockRateLimiter{ctrl: ctrl}
+	mock.recorder = &MockRateLimiterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRateLimiter) EXPECT() *MockRateLimiterMockRecorder {
+	return m.recorder
+}
+
+// Allow mocks base method.
+func (m *MockRateLimiter) Allow(arg0 addr.IA) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Allow", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Allow indicates an expected call of Allow.
+func (mr *MockRateLimiterMockRecorder) Allow(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Allow", reflect.TypeOf((*MockRateLimiter)(nil).Allow), arg0)
+}
+
+// MockCSRValidator is a mock of CSRValidator interface.
+type MockCSRValidator struct {
+	ctrl     *gomock.Controller
+	recorder *MockCSRValidatorMockRecorder
+}
+
+// MockCSRValidatorMockRecorder is the mock recorder for MockCSRValidator.
+type MockCSRValidatorMockRecorder struct {
+	mock *MockCSRValidator
+}
+
+// NewMockCSRValidator creates a new mock instance.
+func NewMockCSRValidator(ctrl *gomock.Controller) *MockCSRValidator {
+	mock := &MockCSRValidator{ctrl: ctrl}
+	mock.recorder = &MockCSRValidatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCSRValidator) EXPECT() *MockCSRValidatorMockRecorder {
+	return m.recorder
+}
+
+// ValidateCSR mocks base method.
+func (m *MockCSRValidator) ValidateCSR(arg0 *x509.CertificateRequest, arg1 addr.IA) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateCSR", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateCSR indicates an expected call of ValidateCSR.
+func (mr *MockCSRValidatorMockRecorder) ValidateCSR(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateCSR", reflect.TypeOf((*MockCSRValidator)(nil).ValidateCSR), arg0, arg1)
+}
+
+// MockAuditLogger is a mock of AuditLogger interface.
+type MockAuditLogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditLoggerMockRecorder
+}
+
+// MockAuditLoggerMockRecorder is the mock recorder for MockAuditLogger.
+type MockAuditLoggerMockRecorder struct {
+	mock *MockAuditLogger
+}
+
+// NewMockAuditLogger creates a new mock instance.
+func NewMockAuditLogger(ctrl *gomock.Controller) *MockAuditLogger {
+	mock := &MockAuditLogger{ctrl: ctrl}
+	mock.recorder = &MockAuditLoggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditLogger) EXPECT() *MockAuditLoggerMockRecorder {
+	return m.recorder
+}
+
+// LogIssuance mocks base method.
+func (m *MockAuditLogger) LogIssuance(arg0 context.Context, arg1 grpc.AuditRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogIssuance", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogIssuance indicates an expected call of LogIssuance.
+func (mr *MockAuditLoggerMockRecorder) LogIssuance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogIssuance", reflect.TypeOf((*MockAuditLogger)(nil).LogIssuance), arg0, arg1)
+}
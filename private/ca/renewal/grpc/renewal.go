@@ -17,6 +17,9 @@ package grpc
 import (
 	"context"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/peer"
@@ -42,10 +45,48 @@ type CMSSigner interface {
 	SignCMS(ctx context.Context, msg []byte) ([]byte, error)
 }
 
+// AuditRecord describes a certificate chain that was issued by the renewal
+// server.
+type AuditRecord struct {
+	// Requester is the AS that signed the renewal request.
+	Requester addr.IA
+	// Subject is the subject of the CSR, which is the subject of the issued
+	// AS certificate.
+	Subject pkix.Name
+	// SerialNumbers are the serial numbers of the issued chain, starting with
+	// the AS certificate.
+	SerialNumbers []*big.Int
+	// Validity is the validity period of the issued AS certificate.
+	Validity cppki.Validity
+}
+
+// auditTimeout bounds the time the audit logger may take to record an issued
+// chain. The audit is not bound to the request context, such that the record
+// is not lost if the client goes away after the chain was signed.
+const auditTimeout = 10 * time.Second
+
+// AuditLogger records the issued certificate chains, e.g., for compliance
+// reporting.
+type AuditLogger interface {
+	LogIssuance(ctx context.Context, record AuditRecord) error
+}
+
 // RenewalServerMetrics contains counters for RenewalServerMetrics.
 type RenewalServerMetrics struct {
+	AuditErrors   metrics.Counter
 	BackendErrors metrics.Counter
 	Success       metrics.Counter
+	// ContextDone counts the requests that were dropped before signing the
+	// response because the context was done, e.g., because the deadline of
+	// the client expired.
+	ContextDone metrics.Counter
+
+	// HandleDuration observes the time in seconds spent in the CMS handler,
+	// i.e., verifying the request and building the certificate chain.
+	HandleDuration metrics.Histogram
+	// SignDuration observes the time in seconds spent signing the response
+	// with the CMS signer, which may be backed by a remote signer or an HSM.
+	SignDuration metrics.Histogram
 }
 
 // RenewalServer servers trust material for gRPC requests.
@@ -53,6 +94,11 @@ type RenewalServer struct {
 	IA         addr.IA
 	CMSHandler CMSRequestHandler
 	CMSSigner  CMSSigner
+	// AuditLogger is informed about every issued chain after the response has
+	// been signed. The audit runs in the background and does not delay the
+	// response. Errors of the audit logger are logged, but do not fail the
+	// request. If nil, issued chains are not audited.
+	AuditLogger AuditLogger
 
 	// Metrics contains the counters. Different error are different counters.
 	Metrics RenewalServerMetrics
@@ -70,14 +116,24 @@ func (s RenewalServer) ChainRenewal(ctx context.Context,
 		return nil, status.Error(codes.InvalidArgument, "signed request missing supported")
 	}
 
+	start := time.Now()
 	resp, err := s.CMSHandler.HandleCMSRequest(ctx, req)
+	metrics.HistogramObserve(s.Metrics.HandleDuration, time.Since(start).Seconds())
 	if err != nil {
 		metrics.CounterInc(s.Metrics.BackendErrors)
 		return nil, err
 	}
+	// Signing may be expensive, e.g., if it is backed by a remote signer.
+	if err := ctx.Err(); err != nil {
+		logger.Debug("Renewal request context done before signing", "err", err)
+		metrics.CounterInc(s.Metrics.ContextDone)
+		return nil, status.FromContextError(err).Err()
+	}
 	// Create response body.
 	rawBody := append(resp[0].Raw, resp[1].Raw...)
+	start = time.Now()
 	signedCMS, err := s.CMSSigner.SignCMS(ctx, rawBody)
+	metrics.HistogramObserve(s.Metrics.SignDuration, time.Since(start).Seconds())
 	if err != nil {
 		logger.Info("Failed to sign reply", "err", err)
 		metrics.CounterInc(s.Metrics.BackendErrors)
@@ -94,6 +150,15 @@ func (s RenewalServer) ChainRenewal(ctx context.Context,
 		},
 		"request_type", "cms",
 	)
+	if s.AuditLogger != nil {
+		// The audit must not delay the response, thus it runs in the background.
+		go func() {
+			defer log.HandlePanic()
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditTimeout)
+			defer cancel()
+			s.audit(ctx, req, resp)
+		}()
+	}
 
 	metrics.CounterInc(s.Metrics.Success)
 	return &cppb.ChainRenewalResponse{
@@ -101,6 +166,37 @@ func (s RenewalServer) ChainRenewal(ctx context.Context,
 	}, nil
 }
 
+func (s RenewalServer) audit(ctx context.Context, req *cppb.ChainRenewalRequest,
+	chain []*x509.Certificate) {
+
+	logger := log.FromCtx(ctx)
+	record := AuditRecord{
+		Subject: chain[0].Subject,
+		Validity: cppki.Validity{
+			NotBefore: chain[0].NotBefore,
+			NotAfter:  chain[0].NotAfter,
+		},
+	}
+	for _, c := range chain {
+		record.SerialNumbers = append(record.SerialNumbers, c.SerialNumber)
+	}
+	// The request has already been verified by the CMS handler, thus the
+	// requester is expected to be known. If it is not, the chain is audited
+	// anyway, but the failure is reported.
+	reqChain, err := extractChain(req.CmsSignedRequest)
+	if err == nil {
+		record.Requester, err = cppki.ExtractIA(reqChain[0].Subject)
+	}
+	if err != nil {
+		logger.Info("Failed to determine requester of issued certificate chain", "err", err)
+		metrics.CounterInc(s.Metrics.AuditErrors)
+	}
+	if err := s.AuditLogger.LogIssuance(ctx, record); err != nil {
+		logger.Info("Failed to audit issued certificate chain", "err", err)
+		metrics.CounterInc(s.Metrics.AuditErrors)
+	}
+}
+
 func extractChain(raw []byte) ([]*x509.Certificate, error) {
 	ci, err := protocol.ParseContentInfo(raw)
 	if err != nil {
@@ -30,6 +30,8 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/scionproto/scion/pkg/addr"
 	"github.com/scionproto/scion/pkg/metrics"
@@ -172,6 +174,290 @@ func TestRenewalServerChainRenewal(t *testing.T) {
 	}
 }
 
+func TestRenewalServerChainRenewalDuration(t *testing.T) {
+	const signDelay = 50 * time.Millisecond
+
+	ctrl := gomock.NewController(t)
+	handler := mock_grpc.NewMockCMSRequestHandler(ctrl)
+	handler.EXPECT().HandleCMSRequest(gomock.Any(), gomock.Any()).Return(mockChain, nil)
+	signer := mock_grpc.NewMockCMSSigner(ctrl)
+	signer.EXPECT().SignCMS(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(context.Context, []byte) ([]byte, error) {
+			time.Sleep(signDelay)
+			return []byte("signed"), nil
+		},
+	)
+
+	handle, sign := &testHistogram{}, &testHistogram{}
+	s := &grpc.RenewalServer{
+		CMSHandler: handler,
+		CMSSigner:  signer,
+		Metrics: grpc.RenewalServerMetrics{
+			HandleDuration: handle,
+			SignDuration:   sign,
+		},
+	}
+	_, err := s.ChainRenewal(context.Background(), &cppb.ChainRenewalRequest{
+		CmsSignedRequest: []byte("dummy request"),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, sign.observations, 1)
+	assert.GreaterOrEqual(t, sign.observations[0], signDelay.Seconds())
+	assert.Less(t, sign.observations[0], (10 * signDelay).Seconds())
+	require.Len(t, handle.observations, 1)
+	assert.Less(t, handle.observations[0], signDelay.Seconds())
+}
+
+func TestRenewalServerChainRenewalContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl := gomock.NewController(t)
+	handler := mock_grpc.NewMockCMSRequestHandler(ctrl)
+	handler.EXPECT().HandleCMSRequest(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(context.Context, *cppb.ChainRenewalRequest) ([]*x509.Certificate, error) {
+			// The client gives up while the chain is created.
+			cancel()
+			return mockChain, nil
+		},
+	)
+	ctr := metrics.NewTestCounter()
+	s := &grpc.RenewalServer{
+		CMSHandler: handler,
+		// The signer fails the test if the response is signed.
+		CMSSigner: mock_grpc.NewMockCMSSigner(ctrl),
+		Metrics: grpc.RenewalServerMetrics{
+			ContextDone: ctr,
+		},
+	}
+	_, err := s.ChainRenewal(ctx, &cppb.ChainRenewalRequest{
+		CmsSignedRequest: []byte("dummy request"),
+	})
+	assert.Equal(t, codes.Canceled, status.Code(err))
+	assert.Equal(t, float64(1), metrics.CounterValue(ctr))
+}
+
+func TestRenewalServerChainRenewalAudit(t *testing.T) {
+	clientKey, chain := genChain(t)
+	signedReq, err := renewal.NewChainRenewalRequest(context.Background(), mockCSR.Raw,
+		trust.Signer{
+			PrivateKey: clientKey,
+			Algorithm:  signed.ECDSAWithSHA256,
+			ChainValidity: cppki.Validity{
+				NotBefore: time.Now(),
+				NotAfter:  time.Now().Add(time.Hour),
+			},
+			Expiration:   time.Now().Add(time.Hour - time.Minute),
+			IA:           addr.MustParseIA("1-ff00:0:111"),
+			SubjectKeyID: chain[0].SubjectKeyId,
+			Chain:        chain,
+		},
+	)
+	require.NoError(t, err)
+	handler := func(ctrl *gomock.Controller) grpc.CMSRequestHandler {
+		h := mock_grpc.NewMockCMSRequestHandler(ctrl)
+		h.EXPECT().HandleCMSRequest(gomock.Any(), gomock.Any()).Return(chain, nil)
+		return h
+	}
+
+	tests := map[string]struct {
+		req         *cppb.ChainRenewalRequest
+		signErr     error
+		auditErr    error
+		expectAudit bool
+		requester   addr.IA
+		assertion   assert.ErrorAssertionFunc
+		auditErrors float64
+	}{
+		"audited": {
+			expectAudit: true,
+			requester:   addr.MustParseIA("1-ff00:0:111"),
+			assertion:   assert.NoError,
+		},
+		"audit error": {
+			auditErr:    mockErr,
+			expectAudit: true,
+			requester:   addr.MustParseIA("1-ff00:0:111"),
+			assertion:   assert.NoError,
+			auditErrors: 1,
+		},
+		"unknown requester": {
+			req:         &cppb.ChainRenewalRequest{CmsSignedRequest: []byte("garbage")},
+			expectAudit: true,
+			assertion:   assert.NoError,
+			auditErrors: 1,
+		},
+		"sign error": {
+			signErr:   mockErr,
+			assertion: assert.Error,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			signer := mock_grpc.NewMockCMSSigner(ctrl)
+			signer.EXPECT().SignCMS(gomock.Any(), gomock.Any()).Return(nil, tc.signErr)
+			auditor := mock_grpc.NewMockAuditLogger(ctrl)
+			audited := make(chan struct{})
+			if tc.expectAudit {
+				auditor.EXPECT().LogIssuance(gomock.Any(), grpc.AuditRecord{
+					Requester: tc.requester,
+					Subject:   chain[0].Subject,
+					SerialNumbers: []*big.Int{
+						chain[0].SerialNumber,
+						chain[1].SerialNumber,
+					},
+					Validity: cppki.Validity{
+						NotBefore: chain[0].NotBefore,
+						NotAfter:  chain[0].NotAfter,
+					},
+				}).Do(func(context.Context, grpc.AuditRecord) {
+					close(audited)
+				}).Return(tc.auditErr)
+			}
+			ctr := metrics.NewTestCounter()
+			s := &grpc.RenewalServer{
+				CMSHandler:  handler(ctrl),
+				CMSSigner:   signer,
+				AuditLogger: auditor,
+				Metrics: grpc.RenewalServerMetrics{
+					AuditErrors: ctr.With("test_tag", "err_audit"),
+				},
+			}
+			req := tc.req
+			if req == nil {
+				req = signedReq
+			}
+			_, err := s.ChainRenewal(context.Background(), req)
+			tc.assertion(t, err)
+			if tc.expectAudit {
+				<-audited
+			}
+			// The audit runs in the background, the counter is updated
+			// after the audit logger returned.
+			assert.Eventually(t, func() bool {
+				return metrics.CounterValue(ctr.With("test_tag", "err_audit")) == tc.auditErrors
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+	t.Run("client gone after signing", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		signer := mock_grpc.NewMockCMSSigner(ctrl)
+		signer.EXPECT().SignCMS(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(context.Context, []byte) ([]byte, error) {
+				cancel()
+				return nil, nil
+			},
+		)
+		auditor := mock_grpc.NewMockAuditLogger(ctrl)
+		audited := make(chan struct{})
+		auditor.EXPECT().LogIssuance(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, _ grpc.AuditRecord) error {
+				defer close(audited)
+				_, ok := ctx.Deadline()
+				assert.True(t, ok)
+				return ctx.Err()
+			},
+		)
+		ctr := metrics.NewTestCounter()
+		s := &grpc.RenewalServer{
+			CMSHandler:  handler(ctrl),
+			CMSSigner:   signer,
+			AuditLogger: auditor,
+			Metrics: grpc.RenewalServerMetrics{
+				AuditErrors: ctr.With("test_tag", "err_audit"),
+			},
+		}
+		_, err := s.ChainRenewal(ctx, signedReq)
+		assert.NoError(t, err)
+		<-audited
+		assert.Never(t, func() bool {
+			return metrics.CounterValue(ctr.With("test_tag", "err_audit")) != 0
+		}, 100*time.Millisecond, 10*time.Millisecond)
+	})
+	t.Run("blocking audit logger", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		signer := mock_grpc.NewMockCMSSigner(ctrl)
+		signer.EXPECT().SignCMS(gomock.Any(), gomock.Any()).Return(nil, nil)
+		returned := make(chan struct{})
+		audited := make(chan struct{})
+		auditor := mock_grpc.NewMockAuditLogger(ctrl)
+		auditor.EXPECT().LogIssuance(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, _ grpc.AuditRecord) error {
+				defer close(audited)
+				select {
+				case <-returned:
+				case <-ctx.Done():
+					t.Error("ChainRenewal waited for the audit logger")
+				}
+				return nil
+			},
+		)
+		s := &grpc.RenewalServer{
+			CMSHandler:  handler(ctrl),
+			CMSSigner:   signer,
+			AuditLogger: auditor,
+		}
+		_, err := s.ChainRenewal(context.Background(), signedReq)
+		assert.NoError(t, err)
+		close(returned)
+		<-audited
+	})
+}
+
+func TestRenewalServerChainRenewalIntermediate(t *testing.T) {
+	_, chain, root := genChainWithIntermediate(t)
+
+	ctrl := gomock.NewController(t)
+	handler := mock_grpc.NewMockCMSRequestHandler(ctrl)
+	handler.EXPECT().HandleCMSRequest(gomock.Any(), gomock.Any()).Return(chain, nil)
+	var body []byte
+	signer := mock_grpc.NewMockCMSSigner(ctrl)
+	signer.EXPECT().SignCMS(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, b []byte) ([]byte, error) {
+			body = b
+			return []byte("signed"), nil
+		},
+	)
+	s := &grpc.RenewalServer{
+		CMSHandler: handler,
+		CMSSigner:  signer,
+	}
+	_, err := s.ChainRenewal(context.Background(), &cppb.ChainRenewalRequest{
+		CmsSignedRequest: []byte("dummy request"),
+	})
+	require.NoError(t, err)
+
+	// The response contains the AS and the CA certificate. The root
+	// certificate is not part of the chain, it is distributed in the TRC.
+	certs, err := x509.ParseCertificates(body)
+	require.NoError(t, err)
+	require.Len(t, certs, 2)
+	assert.True(t, chain[0].Equal(certs[0]))
+	assert.True(t, chain[1].Equal(certs[1]))
+	assert.NoError(t, cppki.ValidateChain(certs))
+	assert.NoError(t, certs[1].CheckSignatureFrom(root))
+}
+
+// testHistogram records all observations.
+type testHistogram struct {
+	observations []float64
+}
+
+func (h *testHistogram) With(...string) metrics.Histogram {
+	return h
+}
+
+func (h *testHistogram) Observe(v float64) {
+	h.observations = append(h.observations, v)
+}
+
 func genChain(t *testing.T) (*ecdsa.PrivateKey, []*x509.Certificate) {
 	t.Helper()
 
@@ -196,15 +482,68 @@ func genChain(t *testing.T) (*ecdsa.PrivateKey, []*x509.Certificate) {
 	return clientKey, chain
 }
 
+// genChainWithIntermediate creates a chain like genChain, but the CA
+// certificate is issued by a root certificate instead of being self-signed.
+// This corresponds to the CP-PKI hierarchy, where the CA certificate is the
+// intermediate between the root certificate in the TRC and the AS
+// certificate. The root certificate is returned separately, since it is not
+// part of the chain.
+func genChainWithIntermediate(
+	t *testing.T,
+) (*ecdsa.PrivateKey, []*x509.Certificate, *x509.Certificate) {
+	t.Helper()
+
+	rootKey, rootCert := genCertRoot(t, "1-ff00:0:110")
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTmpl := caTemplate(t, "1-ff00:0:110", caKey.Public())
+	caTmpl.AuthorityKeyId = rootCert.SubjectKeyId
+	caCert := signCert(t, caTmpl, rootCert, caKey.Public(), rootKey)
+	ca := cppki.CAPolicy{
+		Validity:    time.Hour,
+		Certificate: caCert,
+		Signer:      caKey,
+	}
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	chain, err := ca.CreateChain(&x509.CertificateRequest{
+		Subject: pkix.Name{Names: []pkix.AttributeTypeAndValue{{
+			Type:  cppki.OIDNameIA,
+			Value: "1-ff00:0:111",
+		}}},
+		PublicKey: clientKey.Public(),
+	})
+	require.NoError(t, err)
+	return clientKey, chain, rootCert
+}
+
 func genCertCA(t *testing.T, ia string) (*ecdsa.PrivateKey, *x509.Certificate) {
-	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	require.NoError(t, err)
+	tmpl := caTemplate(t, ia, key.Public())
+	return key, signCert(t, tmpl, tmpl, key.Public(), key)
+}
+
+func genCertRoot(t *testing.T, ia string) (*ecdsa.PrivateKey, *x509.Certificate) {
 	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 	require.NoError(t, err)
-	skid, err := cppki.SubjectKeyID(key.Public())
+	tmpl := caTemplate(t, ia, key.Public())
+	tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	tmpl.MaxPathLen = 1
+	tmpl.MaxPathLenZero = false
+	return key, signCert(t, tmpl, tmpl, key.Public(), key)
+}
+
+// caTemplate returns the template of a self-signed CA certificate.
+func caTemplate(t *testing.T, ia string, pub crypto.PublicKey) *x509.Certificate {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	require.NoError(t, err)
+	skid, err := cppki.SubjectKeyID(pub)
 	require.NoError(t, err)
 
-	tmpl := &x509.Certificate{
+	return &x509.Certificate{
 		Subject: pkix.Name{ExtraNames: []pkix.AttributeTypeAndValue{{
 			Type:  cppki.OIDNameIA,
 			Value: ia,
@@ -220,7 +559,6 @@ func genCertCA(t *testing.T, ia string) (*ecdsa.PrivateKey, *x509.Certificate) {
 		MaxPathLen:            0,
 		MaxPathLenZero:        true,
 	}
-	return key, signCert(t, tmpl, tmpl, key.Public(), key)
 }
 
 func signCert(
@@ -0,0 +1,118 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+// ReplayCache records the renewal requests that were handled recently, such
+// that a replayed request can be rejected. Requests are identified by the
+// SHA-256 hash of the CMS signed request. Entries expire after the TTL. If the
+// cache is full, the oldest entry is evicted. It is safe for concurrent use.
+//
+// A replay is only detected within the TTL of the first request. The TTL
+// should be at least as long as the time a request is accepted by the
+// verifier.
+type ReplayCache struct {
+	ttl  time.Duration
+	size int
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]*list.Element
+	// order contains the entries in insertion order, which is also the order
+	// of expiry.
+	order *list.List
+}
+
+type replayEntry struct {
+	key     [sha256.Size]byte
+	expires time.Time
+}
+
+// NewReplayCache creates a cache that holds at most size requests for the
+// duration of ttl.
+func NewReplayCache(size int, ttl time.Duration) (*ReplayCache, error) {
+	if size <= 0 {
+		return nil, serrors.New("size must be positive", "size", size)
+	}
+	if ttl <= 0 {
+		return nil, serrors.New("ttl must be positive", "ttl", ttl)
+	}
+	return &ReplayCache{
+		ttl:     ttl,
+		size:    size,
+		entries: make(map[[sha256.Size]byte]*list.Element),
+		order:   list.New(),
+	}, nil
+}
+
+// Add records the request. It reports false if the request is already
+// recorded and has not expired yet, i.e., if the request is a replay.
+func (c *ReplayCache) Add(req []byte) bool {
+	key := sha256.Sum256(req)
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire(now)
+	if _, ok := c.entries[key]; ok {
+		return false
+	}
+	if c.order.Len() >= c.size {
+		c.remove(c.order.Front())
+	}
+	c.entries[key] = c.order.PushBack(replayEntry{key: key, expires: now.Add(c.ttl)})
+	return true
+}
+
+// Remove removes the request from the cache, e.g., if it could not be handled
+// and the client should be able to retry it.
+func (c *ReplayCache) Remove(req []byte) {
+	key := sha256.Sum256(req)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+}
+
+// Len returns the number of recorded requests, including expired requests
+// that have not been removed yet.
+func (c *ReplayCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *ReplayCache) expire(now time.Time) {
+	for e := c.order.Front(); e != nil; e = c.order.Front() {
+		if now.Before(e.Value.(replayEntry).expires) {
+			return
+		}
+		c.remove(e)
+	}
+}
+
+func (c *ReplayCache) remove(e *list.Element) {
+	delete(c.entries, e.Value.(replayEntry).key)
+	c.order.Remove(e)
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc_test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/private/ca/renewal/grpc"
+)
+
+func TestNewReplayCache(t *testing.T) {
+	_, err := grpc.NewReplayCache(0, time.Hour)
+	assert.Error(t, err)
+	_, err = grpc.NewReplayCache(1, 0)
+	assert.Error(t, err)
+}
+
+func TestReplayCache(t *testing.T) {
+	t.Run("replay", func(t *testing.T) {
+		c, err := grpc.NewReplayCache(10, time.Hour)
+		require.NoError(t, err)
+		assert.True(t, c.Add([]byte("request")))
+		assert.False(t, c.Add([]byte("request")))
+		assert.True(t, c.Add([]byte("other request")))
+	})
+	t.Run("remove", func(t *testing.T) {
+		c, err := grpc.NewReplayCache(10, time.Hour)
+		require.NoError(t, err)
+		assert.True(t, c.Add([]byte("request")))
+		c.Remove([]byte("request"))
+		assert.True(t, c.Add([]byte("request")))
+	})
+	t.Run("expiry", func(t *testing.T) {
+		c, err := grpc.NewReplayCache(10, time.Millisecond)
+		require.NoError(t, err)
+		assert.True(t, c.Add([]byte("request")))
+		time.Sleep(2 * time.Millisecond)
+		assert.True(t, c.Add([]byte("request")))
+		assert.Equal(t, 1, c.Len())
+	})
+	t.Run("bounded", func(t *testing.T) {
+		c, err := grpc.NewReplayCache(2, time.Hour)
+		require.NoError(t, err)
+		for i := range 3 {
+			assert.True(t, c.Add(fmt.Appendf(nil, "request %d", i)))
+		}
+		assert.Equal(t, 2, c.Len())
+		// The oldest request was evicted.
+		assert.True(t, c.Add([]byte("request 0")))
+		assert.False(t, c.Add([]byte("request 2")))
+	})
+	t.Run("concurrent", func(t *testing.T) {
+		c, err := grpc.NewReplayCache(10, time.Hour)
+		require.NoError(t, err)
+		var added atomic.Int32
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if c.Add([]byte("request")) {
+					added.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), added.Load())
+	})
+}
@@ -0,0 +1,46 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyconf
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+// Derive derives a key of length bytes from Key0 with HKDF-Expand (RFC 5869)
+// and SHA-256. The label is used as the info parameter and should be unique
+// for each purpose, such that the derived keys are independent of each other
+// and of the master key. The same master key and label always result in the
+// same key.
+//
+// The length must be positive and at most 255*32 bytes.
+func (m Master) Derive(label string, length int) ([]byte, error) {
+	if len(m.Key0) == 0 {
+		return nil, serrors.New("master key not set")
+	}
+	if label == "" {
+		return nil, serrors.New("label must not be empty")
+	}
+	if length <= 0 {
+		return nil, serrors.New("length must be positive", "length", length)
+	}
+	key, err := hkdf.Expand(sha256.New, m.Key0, label, length)
+	if err != nil {
+		return nil, serrors.Wrap("deriving key", err, "label", label, "length", length)
+	}
+	return key, nil
+}
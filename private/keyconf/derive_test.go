@@ -0,0 +1,116 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyconf
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMasterDerive(t *testing.T) {
+	fixed := Master{
+		Key0: []byte{0x0, 0x1, 0x2, 0x3, 0x4, 0x5, 0x6, 0x7,
+			0x8, 0x9, 0xa, 0xb, 0xc, 0xd, 0xe, 0xf},
+		Key1: mstr1,
+	}
+	// Test case 1 of RFC 5869, starting from the pseudorandom key.
+	rfc := Master{
+		Key0: mustDecodeHex(t,
+			"077709362c2e32df0ddc3f0dc47bba6390b6c73bb50f9c3122ec844ad7c2b3e5"),
+	}
+
+	tests := map[string]struct {
+		Master    Master
+		Label     string
+		Length    int
+		Expected  string
+		Assertion assert.ErrorAssertionFunc
+	}{
+		"hop field key": {
+			Master:    fixed,
+			Label:     "scion-hfmac",
+			Length:    16,
+			Expected:  "db15a2d389e3020fbb409d12512e16ec",
+			Assertion: assert.NoError,
+		},
+		"other label": {
+			Master: fixed,
+			Label:  "drkey",
+			Length: 32,
+			Expected: "95089b8674999173c90616b5d99a06e1" +
+				"340dd5b2b5a5f5b05c965a3e6c893710",
+			Assertion: assert.NoError,
+		},
+		"longer than hash": {
+			Master: fixed,
+			Label:  "drkey",
+			Length: 48,
+			Expected: "95089b8674999173c90616b5d99a06e1" +
+				"340dd5b2b5a5f5b05c965a3e6c893710" +
+				"4fe966490cff5bee1a69433c00db7a01",
+			Assertion: assert.NoError,
+		},
+		"RFC 5869": {
+			Master: rfc,
+			Label:  string(mustDecodeHex(t, "f0f1f2f3f4f5f6f7f8f9")),
+			Length: 42,
+			Expected: "3cb25f25faacd57a90434f64d0362f2a" +
+				"2d2d0a90cf1a5a4c5db02d56ecc4c5bf" +
+				"34007208d5b887185865",
+			Assertion: assert.NoError,
+		},
+		"empty label": {
+			Master:    fixed,
+			Length:    16,
+			Assertion: assert.Error,
+		},
+		"zero length": {
+			Master:    fixed,
+			Label:     "scion-hfmac",
+			Assertion: assert.Error,
+		},
+		"too long": {
+			Master:    fixed,
+			Label:     "scion-hfmac",
+			Length:    255*32 + 1,
+			Assertion: assert.Error,
+		},
+		"no master key": {
+			Label:     "scion-hfmac",
+			Length:    16,
+			Assertion: assert.Error,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			key, err := tc.Master.Derive(tc.Label, tc.Length)
+			tc.Assertion(t, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.Expected, hex.EncodeToString(key))
+		})
+	}
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyconf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+// masterKeyFile matches the names of the master key files, masterN.key.
+var masterKeyFile = regexp.MustCompile(`^master(\d+)\.key$`)
+
+// KeyFileInfo describes a master key file. It does not contain the key
+// material.
+type KeyFileInfo struct {
+	// Index is the N in masterN.key.
+	Index int
+	// File is the path of the key file.
+	File string
+	// Length is the length of the decoded key in bytes.
+	Length int
+	// Mode is the file mode of the key file.
+	Mode os.FileMode
+	// ModTime is the modification time of the key file.
+	ModTime time.Time
+}
+
+func (i KeyFileInfo) String() string {
+	return fmt.Sprintf("Index:%d File:%s Length:%d Mode:%#o ModTime:%s Key:%s",
+		i.Index, i.File, i.Length, i.Mode.Perm(), i.ModTime.Format(time.RFC3339), "<redacted>")
+}
+
+// Describe returns the information about the master key files in the
+// directory path, ordered by index. The key files are decoded to determine the
+// key length, but the keys themselves are not returned. Unlike LoadMaster,
+// Describe does not check the file permissions; they are part of the returned
+// information instead.
+func Describe(path string) ([]KeyFileInfo, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, serrors.JoinNoStack(ErrOpen, err, "dir", path)
+	}
+	var infos []KeyFileInfo
+	for _, e := range entries {
+		m := masterKeyFile.FindStringSubmatch(e.Name())
+		if m == nil || e.IsDir() {
+			continue
+		}
+		index, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, serrors.New("invalid key file index", "file", e.Name())
+		}
+		file := filepath.Join(path, e.Name())
+		fi, err := os.Stat(file)
+		if err != nil {
+			return nil, serrors.JoinNoStack(ErrOpen, err, "file", file)
+		}
+		key, err := loadKey(file, RawKey, options{permissions: PermissionIgnore})
+		if err != nil {
+			return nil, serrors.Wrap("describing key file", err, "file", file)
+		}
+		infos = append(infos, KeyFileInfo{
+			Index:   index,
+			File:    file,
+			Length:  len(key),
+			Mode:    fi.Mode(),
+			ModTime: fi.ModTime(),
+		})
+		clear(key)
+	}
+	slices.SortFunc(infos, func(a, b KeyFileInfo) int { return a.Index - b.Index })
+	return infos, nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyconf
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribe(t *testing.T) {
+	t.Run("key files", func(t *testing.T) {
+		dir := t.TempDir()
+		for file, mode := range map[string]os.FileMode{
+			MasterKey0:    0o600,
+			MasterKey1:    0o644,
+			"master2.key": 0o400,
+		} {
+			raw, err := os.ReadFile(filepath.Join("testdata", MasterKey0))
+			require.NoError(t, err)
+			if file == "master2.key" {
+				raw = []byte(base64.StdEncoding.EncodeToString(make([]byte, 32)))
+			}
+			path := filepath.Join(dir, file)
+			require.NoError(t, os.WriteFile(path, raw, 0o600))
+			require.NoError(t, os.Chmod(path, mode))
+		}
+		for _, file := range []string{"master.key", "master1.key.bak", "other.key"} {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte("x"), 0o600))
+		}
+
+		infos, err := Describe(dir)
+		require.NoError(t, err)
+		require.Len(t, infos, 3)
+		for i, info := range infos {
+			assert.Equal(t, i, info.Index)
+			assert.False(t, info.ModTime.IsZero())
+		}
+		assert.Equal(t, filepath.Join(dir, MasterKey0), infos[0].File)
+		assert.Equal(t, []int{16, 16, 32},
+			[]int{infos[0].Length, infos[1].Length, infos[2].Length})
+		assert.Equal(t, []os.FileMode{0o600, 0o644, 0o400},
+			[]os.FileMode{infos[0].Mode.Perm(), infos[1].Mode.Perm(), infos[2].Mode.Perm()})
+	})
+	t.Run("redacted", func(t *testing.T) {
+		infos, err := Describe("testdata")
+		require.NoError(t, err)
+		require.Len(t, infos, 2)
+		for _, info := range infos {
+			s := info.String()
+			assert.Contains(t, s, "Key:<redacted>")
+			assert.NotContains(t, s, "rJMIe7UcHTQxm9l13TuI3A==")
+			assert.NotContains(t, s, "WIn/OaISXyOCLehKNHcMKg==")
+		}
+	})
+	t.Run("invalid key file", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, MasterKey0), []byte("!!"), 0o600))
+		_, err := Describe(dir)
+		assert.ErrorIs(t, err, ErrParse)
+	})
+	t.Run("missing dir", func(t *testing.T) {
+		_, err := Describe(filepath.Join(t.TempDir(), "missing"))
+		assert.ErrorIs(t, err, ErrOpen)
+	})
+}
@@ -0,0 +1,178 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyconf
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+// GenerateMaster generates master keys of length bytes from the random source
+// r, e.g., crypto/rand.Reader. Only Key0 and Key1 are set. Lengths below
+// DefaultMinKeyLength are rejected with ErrKeyTooShort, since LoadMaster
+// rejects such keys by default.
+func GenerateMaster(r io.Reader, length int) (Master, error) {
+	if length < DefaultMinKeyLength {
+		return Master{}, serrors.JoinNoStack(ErrKeyTooShort, nil,
+			"length", length, "min_length", DefaultMinKeyLength)
+	}
+	m := Master{Key0: make([]byte, length), Key1: make([]byte, length)}
+	if _, err := io.ReadFull(r, m.Key0); err != nil {
+		return Master{}, serrors.Wrap("generating key", err, "key", MasterKey0)
+	}
+	if _, err := io.ReadFull(r, m.Key1); err != nil {
+		return Master{}, serrors.Wrap("generating key", err, "key", MasterKey1)
+	}
+	return m, nil
+}
+
+// WriteMaster writes the master keys as base64 encoded key files with
+// permissions 0600 to the directory path, such that they can be loaded with
+// LoadMaster. The master2.key file is only written if Key2 is set.
+//
+// Existing key files are not overwritten, and ErrExists is returned instead,
+// unless WithForce is set. In that case, a master2.key file is removed if Key2
+// is not set, such that it is not loaded together with the new keys.
+//
+// Each file is written to a temporary file in the same directory first and
+// then moved in place, such that a key file is never partially written. The
+// files are only moved once all of them are written, and the directory is
+// synced afterwards. Without WithForce, the temporary files are hard linked
+// to the key files, which fails if a key file was created concurrently. In
+// that case, the key files written so far are removed again. With WithForce,
+// the temporary files are renamed. If a rename fails, the key files that are
+// already renamed are not restored, and the directory contains a mix of new
+// and old keys.
+func WriteMaster(path string, m Master, opts ...Option) error {
+	o := applyOptions(opts)
+	if len(m.Key0) == 0 || len(m.Key1) == 0 {
+		return serrors.New("master keys not set")
+	}
+	if !o.force {
+		for _, name := range []string{MasterKey0, MasterKey1, MasterKey2} {
+			file := filepath.Join(path, name)
+			_, err := os.Lstat(file)
+			if err == nil {
+				return serrors.JoinNoStack(ErrExists, nil, "file", file)
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return serrors.Wrap("checking key file", err, "file", file)
+			}
+		}
+	}
+	names, keys := []string{MasterKey0, MasterKey1}, [][]byte{m.Key0, m.Key1}
+	if m.Key2 != nil {
+		names, keys = append(names, MasterKey2), append(keys, m.Key2)
+	}
+	var tmps []string
+	defer func() {
+		for _, tmp := range tmps {
+			os.Remove(tmp)
+		}
+	}()
+	for _, key := range keys {
+		raw := base64.StdEncoding.AppendEncode(nil, key)
+		tmp, err := writeTemp(path, append(raw, '\n'))
+		if err != nil {
+			return err
+		}
+		tmps = append(tmps, tmp)
+	}
+	if !o.force {
+		if err := linkAll(path, names, tmps); err != nil {
+			return err
+		}
+		return syncDir(path)
+	}
+	for i, tmp := range tmps {
+		file := filepath.Join(path, names[i])
+		if err := os.Rename(tmp, file); err != nil {
+			return serrors.Wrap("renaming key file", err, "file", file)
+		}
+	}
+	tmps = nil
+	if m.Key2 == nil {
+		file := filepath.Join(path, MasterKey2)
+		if err := os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return serrors.Wrap("removing key file", err, "file", file)
+		}
+	}
+	return syncDir(path)
+}
+
+// linkAll hard links the temporary files to the key files with the names in
+// the directory. If a key file exists, ErrExists is returned. If linking
+// fails, the key files that are already linked are removed.
+func linkAll(dir string, names, tmps []string) error {
+	var linked []string
+	for i, tmp := range tmps {
+		file := filepath.Join(dir, names[i])
+		if err := os.Link(tmp, file); err != nil {
+			for _, l := range linked {
+				os.Remove(l)
+			}
+			if errors.Is(err, fs.ErrExist) {
+				return serrors.JoinNoStack(ErrExists, nil, "file", file)
+			}
+			return serrors.Wrap("linking key file", err, "file", file)
+		}
+		linked = append(linked, file)
+	}
+	return nil
+}
+
+// syncDir syncs the directory, such that the renamed and linked files are
+// persisted.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return serrors.Wrap("opening directory", err, "dir", dir)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return serrors.Wrap("syncing directory", err, "dir", dir)
+	}
+	return nil
+}
+
+// writeTemp writes the data to a new temporary file with permissions 0600 in
+// the directory and returns the name of the file.
+func writeTemp(dir string, data []byte) (string, error) {
+	f, err := os.CreateTemp(dir, ".master*.key")
+	if err != nil {
+		return "", serrors.Wrap("creating temporary key file", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", serrors.Wrap("writing key file", err, "file", f.Name())
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", serrors.Wrap("syncing key file", err, "file", f.Name())
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", serrors.Wrap("closing key file", err, "file", f.Name())
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyconf
+
+import (
+	"bytes"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateMaster(t *testing.T) {
+	t.Run("random source", func(t *testing.T) {
+		r := bytes.NewReader([]byte("0123456789abcdefghijklmnopqrstuv"))
+		m, err := GenerateMaster(r, 16)
+		require.NoError(t, err)
+		assert.Equal(t, Master{
+			Key0: []byte("0123456789abcdef"),
+			Key1: []byte("ghijklmnopqrstuv"),
+		}, m)
+	})
+	t.Run("too short", func(t *testing.T) {
+		_, err := GenerateMaster(rand.Reader, DefaultMinKeyLength-1)
+		assert.ErrorIs(t, err, ErrKeyTooShort)
+	})
+	t.Run("exhausted source", func(t *testing.T) {
+		_, err := GenerateMaster(bytes.NewReader(make([]byte, 20)), 16)
+		assert.Error(t, err)
+	})
+}
+
+func TestWriteMaster(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		dir := t.TempDir()
+		m, err := GenerateMaster(rand.Reader, 32)
+		require.NoError(t, err)
+		require.NoError(t, WriteMaster(dir, m))
+
+		loaded, err := LoadMaster(dir, WithPermissionCheck(PermissionStrict))
+		require.NoError(t, err)
+		assert.Equal(t, m, loaded)
+		_, err = os.Stat(filepath.Join(dir, MasterKey2))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+	t.Run("round trip with key2", func(t *testing.T) {
+		dir := t.TempDir()
+		m := Master{Key0: mstr0, Key1: mstr1, Key2: []byte("staged master key")}
+		require.NoError(t, WriteMaster(dir, m))
+
+		loaded, err := LoadMaster(dir, WithPermissionCheck(PermissionStrict))
+		require.NoError(t, err)
+		assert.Equal(t, m, loaded)
+	})
+	t.Run("existing keys", func(t *testing.T) {
+		dir := t.TempDir()
+		old := Master{Key0: mstr0, Key1: mstr1}
+		require.NoError(t, WriteMaster(dir, old))
+		m, err := GenerateMaster(rand.Reader, 16)
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, WriteMaster(dir, m), ErrExists)
+		loaded, err := LoadMaster(dir)
+		require.NoError(t, err)
+		assert.Equal(t, old, loaded)
+	})
+	t.Run("existing key2", func(t *testing.T) {
+		dir := t.TempDir()
+		file := filepath.Join(dir, MasterKey2)
+		require.NoError(t, os.WriteFile(file, []byte("c3RhZ2VkIG1hc3RlciBrZXk="), 0o600))
+		m := Master{Key0: mstr0, Key1: mstr1}
+		assert.ErrorIs(t, WriteMaster(dir, m), ErrExists)
+	})
+	t.Run("force", func(t *testing.T) {
+		dir := t.TempDir()
+		old := Master{Key0: mstr0, Key1: mstr1, Key2: []byte("staged master key")}
+		require.NoError(t, WriteMaster(dir, old))
+		m, err := GenerateMaster(rand.Reader, 16)
+		require.NoError(t, err)
+
+		require.NoError(t, WriteMaster(dir, m, WithForce(true)))
+		loaded, err := LoadMaster(dir, WithPermissionCheck(PermissionStrict))
+		require.NoError(t, err)
+		// The stale master2.key is removed.
+		assert.Equal(t, m, loaded)
+	})
+	t.Run("no temporary files", func(t *testing.T) {
+		dir := t.TempDir()
+		m := Master{Key0: mstr0, Key1: mstr1}
+		require.NoError(t, WriteMaster(dir, m))
+		require.NoError(t, WriteMaster(dir, m, WithForce(true)))
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		assert.ElementsMatch(t, []string{MasterKey0, MasterKey1}, names)
+	})
+	t.Run("concurrently created key", func(t *testing.T) {
+		// A key file that is created after the check is not overwritten, and
+		// the key files that are already linked are removed.
+		dir := t.TempDir()
+		tmp0, err := writeTemp(dir, []byte("bmV3IGtleTA=\n"))
+		require.NoError(t, err)
+		tmp1, err := writeTemp(dir, []byte("bmV3IGtleTE=\n"))
+		require.NoError(t, err)
+		file := filepath.Join(dir, MasterKey1)
+		require.NoError(t, os.WriteFile(file, []byte("b2xkIGtleTE=\n"), 0o600))
+
+		err = linkAll(dir, []string{MasterKey0, MasterKey1}, []string{tmp0, tmp1})
+		assert.ErrorIs(t, err, ErrExists)
+		_, err = os.Stat(filepath.Join(dir, MasterKey0))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		raw, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, "b2xkIGtleTE=\n", string(raw))
+	})
+	t.Run("keys not set", func(t *testing.T) {
+		assert.Error(t, WriteMaster(t.TempDir(), Master{Key0: mstr0}))
+	})
+	t.Run("missing directory", func(t *testing.T) {
+		m := Master{Key0: mstr0, Key1: mstr1}
+		assert.Error(t, WriteMaster(filepath.Join(t.TempDir(), "missing"), m))
+	})
+}
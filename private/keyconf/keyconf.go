@@ -16,69 +16,270 @@ package keyconf
 
 import (
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/scionproto/scion/pkg/log"
 	"github.com/scionproto/scion/pkg/private/serrors"
 )
 
 const (
 	MasterKey0 = "master0.key"
 	MasterKey1 = "master1.key"
+	// MasterKey2 is the optional third master key, used during staged key
+	// rotation.
+	MasterKey2 = "master2.key"
 
+	// RawKey is the algorithm of base64 encoded keys.
 	RawKey = "raw"
+	// HexKey is the algorithm of hex encoded keys.
+	HexKey = "hex"
 )
 
 // Errors
 var (
-	ErrOpen    = errors.New("unable to load key")
-	ErrParse   = errors.New("unable to parse key file")
-	ErrUnknown = errors.New("unknown algorithm")
+	ErrOpen        = errors.New("unable to load key")
+	ErrParse       = errors.New("unable to parse key file")
+	ErrUnknown     = errors.New("unknown algorithm")
+	ErrPermissions = errors.New("key file permissions too broad")
+	ErrKeyTooShort = errors.New("key too short")
+	ErrExists      = errors.New("key file already exists")
 )
 
-// loadKey decodes a base64 encoded key stored in file and returns the raw bytes.
-func loadKey(file string, algo string) ([]byte, error) {
+// DefaultMinKeyLength is the default minimum length of a decoded master key in
+// bytes.
+const DefaultMinKeyLength = 16
+
+// PermissionCheck defines how key files with permissions broader than 0600
+// are handled.
+type PermissionCheck int
+
+const (
+	// PermissionWarn logs an error for key files with broad permissions, but
+	// still loads them. This is the default.
+	PermissionWarn PermissionCheck = iota
+	// PermissionStrict rejects key files with broad permissions.
+	PermissionStrict
+	// PermissionIgnore disables the permission check.
+	PermissionIgnore
+)
+
+type options struct {
+	permissions  PermissionCheck
+	algo         string
+	minKeyLength int
+	force        bool
+}
+
+func applyOptions(opts []Option) options {
+	o := options{algo: RawKey, minKeyLength: DefaultMinKeyLength}
+	for _, option := range opts {
+		option(&o)
+	}
+	return o
+}
+
+// Option is a function that sets an option.
+type Option func(o *options)
+
+// WithPermissionCheck sets how key files that are accessible by group or
+// others are handled.
+func WithPermissionCheck(check PermissionCheck) Option {
+	return func(o *options) {
+		o.permissions = check
+	}
+}
+
+// WithAlgorithm sets the algorithm that the key files are encoded with, i.e.,
+// RawKey or HexKey. The default is RawKey.
+func WithAlgorithm(algo string) Option {
+	return func(o *options) {
+		o.algo = algo
+	}
+}
+
+// WithMinKeyLength sets the minimum length of the decoded keys in bytes.
+// Shorter keys are rejected with ErrKeyTooShort. The default is
+// DefaultMinKeyLength.
+func WithMinKeyLength(n int) Option {
+	return func(o *options) {
+		o.minKeyLength = n
+	}
+}
+
+// WithForce sets whether WriteMaster overwrites existing key files. By
+// default, it fails with ErrExists.
+func WithForce(force bool) Option {
+	return func(o *options) {
+		o.force = force
+	}
+}
+
+// loadKey decodes a key stored in file according to algo and returns the raw
+// bytes.
+func loadKey(file string, algo string, o options) ([]byte, error) {
+	if err := checkPermissions(file, o.permissions); err != nil {
+		return nil, err
+	}
 	b, err := os.ReadFile(file)
 	if err != nil {
 		return nil, serrors.JoinNoStack(ErrOpen, err)
 	}
-	dbuf := make([]byte, base64.StdEncoding.DecodedLen(len(b)))
-	n, err := base64.StdEncoding.Decode(dbuf, b)
+	key, err := decodeKey(b, algo)
 	if err != nil {
-		return nil, serrors.JoinNoStack(ErrParse, err)
+		return nil, err
+	}
+	if err := checkKeyLength(key, o.minKeyLength, "file", file); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// checkKeyLength verifies that the key has at least minLength bytes. The
+// context describes the source of the key.
+func checkKeyLength(key []byte, minLength int, ctx ...any) error {
+	if len(key) >= minLength {
+		return nil
 	}
-	dbuf = dbuf[:n]
-	if strings.ToLower(algo) != RawKey {
+	return serrors.JoinNoStack(ErrKeyTooShort, nil,
+		append(ctx, "length", len(key), "min_length", minLength)...)
+}
+
+func decodeKey(b []byte, algo string) ([]byte, error) {
+	switch strings.ToLower(algo) {
+	case RawKey:
+		dbuf := make([]byte, base64.StdEncoding.DecodedLen(len(b)))
+		n, err := base64.StdEncoding.Decode(dbuf, b)
+		if err != nil {
+			return nil, serrors.JoinNoStack(ErrParse, err)
+		}
+		return dbuf[:n], nil
+	case HexKey:
+		dbuf, err := hex.DecodeString(strings.TrimSpace(string(b)))
+		if err != nil {
+			return nil, serrors.JoinNoStack(ErrParse, err)
+		}
+		return dbuf, nil
+	default:
 		return nil, serrors.JoinNoStack(ErrUnknown, nil, "algo", algo)
 	}
-	return dbuf, nil
+}
+
+// checkPermissions verifies that the key file is not accessible by anyone
+// other than the owner.
+func checkPermissions(file string, check PermissionCheck) error {
+	if check == PermissionIgnore {
+		return nil
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return serrors.JoinNoStack(ErrOpen, err)
+	}
+	perm := info.Mode().Perm()
+	if perm&^0o600 == 0 {
+		return nil
+	}
+	if check == PermissionStrict {
+		return serrors.JoinNoStack(ErrPermissions, nil,
+			"file", file, "mode", fmt.Sprintf("%#o", perm))
+	}
+	log.Error("Key file permissions are broader than 0600",
+		"file", file, "mode", fmt.Sprintf("%#o", perm))
+	return nil
 }
 
 type Master struct {
 	Key0 []byte
 	Key1 []byte
+	// Key2 is the optional third master key. It is nil if master2.key does not
+	// exist.
+	Key2 []byte
 }
 
-func LoadMaster(path string) (Master, error) {
+// LoadMaster loads the master keys from the directory path. By default, key
+// files with permissions broader than 0600 are loaded, but reported in the
+// log; use WithPermissionCheck to change this behavior. The key files are
+// base64 encoded, unless a different algorithm is set with WithAlgorithm.
+// Keys shorter than DefaultMinKeyLength bytes are rejected; use
+// WithMinKeyLength to change the minimum.
+//
+// The master2.key file is optional. If it exists, it is loaded as Key2, so
+// that an incoming key can be distributed before the outgoing key is retired.
+func LoadMaster(path string, opts ...Option) (Master, error) {
+	o := applyOptions(opts)
 	var err error
 	m := Master{}
-	if m.Key0, err = loadKey(filepath.Join(path, MasterKey0), RawKey); err != nil {
+	if m.Key0, err = loadKey(filepath.Join(path, MasterKey0), o.algo, o); err != nil {
 		return m, err
 	}
-	if m.Key1, err = loadKey(filepath.Join(path, MasterKey1), RawKey); err != nil {
+	if m.Key1, err = loadKey(filepath.Join(path, MasterKey1), o.algo, o); err != nil {
+		return m, err
+	}
+	file2 := filepath.Join(path, MasterKey2)
+	if _, err := os.Stat(file2); errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if m.Key2, err = loadKey(file2, o.algo, o); err != nil {
 		return m, err
 	}
 	return m, nil
 }
 
+// LoadMasterFromBytes decodes the master keys from the contents of the key
+// files. The keys are base64 encoded, unless a different algorithm is set with
+// WithAlgorithm.
+func LoadMasterFromBytes(key0, key1 []byte, opts ...Option) (Master, error) {
+	o := applyOptions(opts)
+	var err error
+	m := Master{}
+	if m.Key0, err = decodeKey(key0, o.algo); err != nil {
+		return m, serrors.Wrap("decoding key", err, "key", MasterKey0)
+	}
+	if m.Key1, err = decodeKey(key1, o.algo); err != nil {
+		return m, serrors.Wrap("decoding key", err, "key", MasterKey1)
+	}
+	if err := checkKeyLength(m.Key0, o.minKeyLength, "key", MasterKey0); err != nil {
+		return Master{}, err
+	}
+	if err := checkKeyLength(m.Key1, o.minKeyLength, "key", MasterKey1); err != nil {
+		return Master{}, err
+	}
+	return m, nil
+}
+
+// LoadMasterFromEnv loads the master keys from the environment variables
+// <prefix>_KEY0 and <prefix>_KEY1. The keys are base64 encoded, unless a
+// different algorithm is set with WithAlgorithm.
+func LoadMasterFromEnv(prefix string, opts ...Option) (Master, error) {
+	var keys [2][]byte
+	for i := range keys {
+		name := fmt.Sprintf("%s_KEY%d", prefix, i)
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return Master{}, serrors.JoinNoStack(ErrOpen, nil, "env", name)
+		}
+		keys[i] = []byte(v)
+	}
+	return LoadMasterFromBytes(keys[0], keys[1], opts...)
+}
+
 func (m Master) MarshalJSON() ([]byte, error) {
+	if m.Key2 != nil {
+		return []byte(`{"key0":"redacted","key1":"redacted","key2":"redacted"}`), nil
+	}
 	return []byte(`{"key0":"redacted","key1":"redacted"}`), nil
 }
 
 func (m Master) String() string {
+	if m.Key2 != nil {
+		return fmt.Sprintf("Key0:%s Key1:%s Key2:%s",
+			"<redacted>", "<redacted>", "<redacted>")
+	}
 	return fmt.Sprintf("Key0:%s Key1:%s",
 		//XXX(roosd): Uncomment for debugging.
 		//m.Key0, m.Key1
@@ -16,7 +16,11 @@ package keyconf
 
 import (
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -33,6 +37,237 @@ func TestLoadMaster(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, mstr0, m.Key0)
 	assert.Equal(t, mstr1, m.Key1)
+	assert.Nil(t, m.Key2)
+}
+
+func TestLoadMasterKey2(t *testing.T) {
+	dir := t.TempDir()
+	for file, key := range map[string]string{
+		MasterKey0: "rJMIe7UcHTQxm9l13TuI3A==",
+		MasterKey1: "WIn/OaISXyOCLehKNHcMKg==",
+		MasterKey2: base64.StdEncoding.EncodeToString(mstr0),
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(key), 0o600))
+	}
+	m, err := LoadMaster(dir)
+	require.NoError(t, err)
+	assert.Equal(t, Master{Key0: mstr0, Key1: mstr1, Key2: mstr0}, m)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, MasterKey2), []byte("!!"), 0o600))
+	_, err = LoadMaster(dir)
+	assert.ErrorIs(t, err, ErrParse)
+}
+
+func TestLoadMasterPermissions(t *testing.T) {
+	testCases := map[string]struct {
+		Mode      os.FileMode
+		Check     PermissionCheck
+		Assertion assert.ErrorAssertionFunc
+	}{
+		"owner only, strict": {
+			Mode:      0o600,
+			Check:     PermissionStrict,
+			Assertion: assert.NoError,
+		},
+		"owner read only, strict": {
+			Mode:      0o400,
+			Check:     PermissionStrict,
+			Assertion: assert.NoError,
+		},
+		"group readable, strict": {
+			Mode:      0o640,
+			Check:     PermissionStrict,
+			Assertion: assert.Error,
+		},
+		"world readable, strict": {
+			Mode:      0o604,
+			Check:     PermissionStrict,
+			Assertion: assert.Error,
+		},
+		"owner executable, strict": {
+			Mode:      0o700,
+			Check:     PermissionStrict,
+			Assertion: assert.Error,
+		},
+		"world readable, warn": {
+			Mode:      0o644,
+			Check:     PermissionWarn,
+			Assertion: assert.NoError,
+		},
+		"world readable, ignore": {
+			Mode:      0o644,
+			Check:     PermissionIgnore,
+			Assertion: assert.NoError,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, file := range []string{MasterKey0, MasterKey1} {
+				raw, err := os.ReadFile(filepath.Join("testdata", file))
+				require.NoError(t, err)
+				path := filepath.Join(dir, file)
+				require.NoError(t, os.WriteFile(path, raw, 0o600))
+				require.NoError(t, os.Chmod(path, tc.Mode))
+			}
+			m, err := LoadMaster(dir, WithPermissionCheck(tc.Check))
+			tc.Assertion(t, err)
+			if err != nil {
+				assert.ErrorIs(t, err, ErrPermissions)
+				return
+			}
+			assert.Equal(t, mstr0, m.Key0)
+			assert.Equal(t, mstr1, m.Key1)
+		})
+	}
+}
+
+func TestLoadMasterAlgorithm(t *testing.T) {
+	testCases := map[string]struct {
+		Algo      string
+		Key0      string
+		Key1      string
+		Assertion assert.ErrorAssertionFunc
+		Err       error
+	}{
+		"raw": {
+			Algo:      RawKey,
+			Key0:      "rJMIe7UcHTQxm9l13TuI3A==",
+			Key1:      "WIn/OaISXyOCLehKNHcMKg==",
+			Assertion: assert.NoError,
+		},
+		"hex": {
+			Algo:      HexKey,
+			Key0:      hex.EncodeToString(mstr0) + "\n",
+			Key1:      hex.EncodeToString(mstr1),
+			Assertion: assert.NoError,
+		},
+		"hex upper case": {
+			Algo:      "HEX",
+			Key0:      strings.ToUpper(hex.EncodeToString(mstr0)),
+			Key1:      hex.EncodeToString(mstr1),
+			Assertion: assert.NoError,
+		},
+		"invalid hex": {
+			Algo:      HexKey,
+			Key0:      "rJMIe7UcHTQxm9l13TuI3A==",
+			Key1:      hex.EncodeToString(mstr1),
+			Assertion: assert.Error,
+			Err:       ErrParse,
+		},
+		"unknown": {
+			Algo:      "pem",
+			Key0:      "rJMIe7UcHTQxm9l13TuI3A==",
+			Key1:      "WIn/OaISXyOCLehKNHcMKg==",
+			Assertion: assert.Error,
+			Err:       ErrUnknown,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, MasterKey0), []byte(tc.Key0), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, MasterKey1), []byte(tc.Key1), 0o600))
+			m, err := LoadMaster(dir, WithAlgorithm(tc.Algo))
+			tc.Assertion(t, err)
+			if err != nil {
+				assert.ErrorIs(t, err, tc.Err)
+				return
+			}
+			assert.Equal(t, mstr0, m.Key0)
+			assert.Equal(t, mstr1, m.Key1)
+		})
+	}
+}
+
+func TestLoadMasterKeyLength(t *testing.T) {
+	short := base64.StdEncoding.EncodeToString([]byte("abcd"))
+	testCases := map[string]struct {
+		Key0      string
+		Opts      []Option
+		Assertion assert.ErrorAssertionFunc
+	}{
+		"default minimum": {
+			Key0:      "rJMIe7UcHTQxm9l13TuI3A==",
+			Assertion: assert.NoError,
+		},
+		"too short": {
+			Key0:      short,
+			Assertion: assert.Error,
+		},
+		"empty": {
+			Key0:      "",
+			Assertion: assert.Error,
+		},
+		"lower minimum": {
+			Key0:      short,
+			Opts:      []Option{WithMinKeyLength(4)},
+			Assertion: assert.NoError,
+		},
+		"higher minimum": {
+			Key0:      "rJMIe7UcHTQxm9l13TuI3A==",
+			Opts:      []Option{WithMinKeyLength(32)},
+			Assertion: assert.Error,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, MasterKey0), []byte(tc.Key0), 0o600))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, MasterKey1),
+				[]byte("WIn/OaISXyOCLehKNHcMKg=="), 0o600))
+			_, err := LoadMaster(dir, tc.Opts...)
+			tc.Assertion(t, err)
+			if err != nil {
+				assert.ErrorIs(t, err, ErrKeyTooShort)
+				assert.Contains(t, err.Error(), MasterKey0)
+			}
+
+			_, err = LoadMasterFromBytes([]byte(tc.Key0), []byte("WIn/OaISXyOCLehKNHcMKg=="),
+				tc.Opts...)
+			tc.Assertion(t, err)
+			if err != nil {
+				assert.ErrorIs(t, err, ErrKeyTooShort)
+			}
+		})
+	}
+}
+
+func TestLoadMasterFromBytes(t *testing.T) {
+	m, err := LoadMasterFromBytes(
+		[]byte("rJMIe7UcHTQxm9l13TuI3A=="),
+		[]byte("WIn/OaISXyOCLehKNHcMKg=="),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, Master{Key0: mstr0, Key1: mstr1}, m)
+
+	m, err = LoadMasterFromBytes(
+		[]byte(hex.EncodeToString(mstr0)),
+		[]byte(hex.EncodeToString(mstr1)),
+		WithAlgorithm(HexKey),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, Master{Key0: mstr0, Key1: mstr1}, m)
+
+	_, err = LoadMasterFromBytes([]byte("rJMIe7UcHTQxm9l13TuI3A=="), []byte("!!"))
+	assert.ErrorIs(t, err, ErrParse)
+}
+
+func TestLoadMasterFromEnv(t *testing.T) {
+	t.Setenv("TEST_MASTER_KEY0", "rJMIe7UcHTQxm9l13TuI3A==")
+	t.Setenv("TEST_MASTER_KEY1", "WIn/OaISXyOCLehKNHcMKg==")
+	m, err := LoadMasterFromEnv("TEST_MASTER")
+	require.NoError(t, err)
+	assert.Equal(t, Master{Key0: mstr0, Key1: mstr1}, m)
+
+	t.Setenv("TEST_INVALID_KEY0", "rJMIe7UcHTQxm9l13TuI3A==")
+	t.Setenv("TEST_INVALID_KEY1", "not base64")
+	_, err = LoadMasterFromEnv("TEST_INVALID")
+	assert.ErrorIs(t, err, ErrParse)
+
+	t.Setenv("TEST_MISSING_KEY0", "rJMIe7UcHTQxm9l13TuI3A==")
+	_, err = LoadMasterFromEnv("TEST_MISSING")
+	assert.ErrorIs(t, err, ErrOpen)
 }
 
 func TestMasterRedacted(t *testing.T) {
@@ -58,4 +293,10 @@ func TestMasterRedacted(t *testing.T) {
 	})
 	require.NoError(t, err)
 	assert.Equal(t, `{"keys":{"key0":"redacted","key1":"redacted"}}`, string(raw))
+
+	m.Key2 = []byte("staged")
+	assert.Equal(t, "Key0:<redacted> Key1:<redacted> Key2:<redacted>", m.String())
+	raw, err = json.Marshal(m)
+	require.NoError(t, err)
+	assert.Equal(t, `{"key0":"redacted","key1":"redacted","key2":"redacted"}`, string(raw))
 }
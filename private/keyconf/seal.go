@@ -0,0 +1,132 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyconf
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/nacl/box"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+// sealVersion is the version of the encoding of the sealed master keys with
+// two keys. sealVersionKey2 is used if the optional third key is set.
+const (
+	sealVersion     = 1
+	sealVersionKey2 = 2
+)
+
+var (
+	// ErrSealed indicates that a sealed bundle could not be opened, because it
+	// is malformed, was tampered with, or was sealed for a different recipient.
+	ErrSealed = errors.New("unable to open sealed master keys")
+)
+
+// SealMaster encrypts the master keys for the owner of the X25519 public key
+// recipient. The result is a NaCl sealed box (X25519, XSalsa20 and Poly1305)
+// that can be transported over an untrusted channel and is opened with
+// OpenMaster.
+func SealMaster(m Master, recipient *[32]byte) ([]byte, error) {
+	if recipient == nil {
+		return nil, serrors.New("recipient public key not set")
+	}
+	msg, err := encodeMaster(m)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := box.SealAnonymous(nil, msg, recipient, rand.Reader)
+	if err != nil {
+		return nil, serrors.Wrap("sealing master keys", err)
+	}
+	return sealed, nil
+}
+
+// OpenMaster decrypts master keys that were sealed with SealMaster for the
+// public key that corresponds to the X25519 private key.
+func OpenMaster(sealed []byte, privateKey *[32]byte) (Master, error) {
+	if privateKey == nil {
+		return Master{}, serrors.New("private key not set")
+	}
+	pub, err := curve25519.X25519(privateKey[:], curve25519.Basepoint)
+	if err != nil {
+		return Master{}, serrors.Wrap("deriving public key", err)
+	}
+	msg, ok := box.OpenAnonymous(nil, sealed, (*[32]byte)(pub), privateKey)
+	if !ok {
+		return Master{}, ErrSealed
+	}
+	return decodeMaster(msg)
+}
+
+// encodeMaster encodes the master keys as version followed by the length
+// prefixed keys.
+func encodeMaster(m Master) ([]byte, error) {
+	version, keys := byte(sealVersion), [][]byte{m.Key0, m.Key1}
+	if m.Key2 != nil {
+		version, keys = sealVersionKey2, append(keys, m.Key2)
+	}
+	size := 1
+	for _, k := range keys {
+		if len(k) > math.MaxUint16 {
+			return nil, serrors.New("master key too long")
+		}
+		size += 2 + len(k)
+	}
+	b := make([]byte, 0, size)
+	b = append(b, version)
+	for _, k := range keys {
+		b = binary.BigEndian.AppendUint16(b, uint16(len(k)))
+		b = append(b, k...)
+	}
+	return b, nil
+}
+
+func decodeMaster(b []byte) (Master, error) {
+	var keys [][]byte
+	switch {
+	case len(b) >= 1 && b[0] == sealVersion:
+		keys = make([][]byte, 2)
+	case len(b) >= 1 && b[0] == sealVersionKey2:
+		keys = make([][]byte, 3)
+	default:
+		return Master{}, serrors.JoinNoStack(ErrSealed, nil, "reason", "unsupported version")
+	}
+	b = b[1:]
+	for i := range keys {
+		if len(b) < 2 {
+			return Master{}, serrors.JoinNoStack(ErrSealed, nil, "reason", "truncated")
+		}
+		l := int(binary.BigEndian.Uint16(b))
+		b = b[2:]
+		if len(b) < l {
+			return Master{}, serrors.JoinNoStack(ErrSealed, nil, "reason", "truncated")
+		}
+		keys[i] = append([]byte(nil), b[:l]...)
+		b = b[l:]
+	}
+	if len(b) != 0 {
+		return Master{}, serrors.JoinNoStack(ErrSealed, nil, "reason", "trailing bytes")
+	}
+	m := Master{Key0: keys[0], Key1: keys[1]}
+	if len(keys) > 2 {
+		m.Key2 = keys[2]
+	}
+	return m, nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyconf
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/nacl/box"
+)
+
+func TestSealMaster(t *testing.T) {
+	pub, priv, err := box.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	m := Master{Key0: mstr0, Key1: mstr1}
+
+	t.Run("round trip", func(t *testing.T) {
+		sealed, err := SealMaster(m, pub)
+		require.NoError(t, err)
+		assert.NotContains(t, string(sealed), string(mstr0))
+		opened, err := OpenMaster(sealed, priv)
+		require.NoError(t, err)
+		assert.Equal(t, m, opened)
+	})
+	t.Run("round trip with key2", func(t *testing.T) {
+		m := Master{Key0: mstr0, Key1: mstr1, Key2: []byte("staged master key")}
+		sealed, err := SealMaster(m, pub)
+		require.NoError(t, err)
+		opened, err := OpenMaster(sealed, priv)
+		require.NoError(t, err)
+		assert.Equal(t, m, opened)
+	})
+	t.Run("tampered", func(t *testing.T) {
+		sealed, err := SealMaster(m, pub)
+		require.NoError(t, err)
+		for _, i := range []int{0, len(sealed) / 2, len(sealed) - 1} {
+			tampered := append([]byte(nil), sealed...)
+			tampered[i] ^= 0x01
+			_, err := OpenMaster(tampered, priv)
+			assert.ErrorIs(t, err, ErrSealed, "byte %d", i)
+		}
+		_, err = OpenMaster(sealed[:len(sealed)-1], priv)
+		assert.ErrorIs(t, err, ErrSealed)
+	})
+	t.Run("wrong recipient", func(t *testing.T) {
+		_, otherPriv, err := box.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		sealed, err := SealMaster(m, pub)
+		require.NoError(t, err)
+		_, err = OpenMaster(sealed, otherPriv)
+		assert.ErrorIs(t, err, ErrSealed)
+	})
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyconf
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/scionproto/scion/pkg/log"
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+// DefaultWatchDebounce is the default time the Watcher waits after a change
+// in the key directory before it reloads the keys.
+const DefaultWatchDebounce = 500 * time.Millisecond
+
+// Watcher reloads the master keys when the key files change. This allows
+// rotating the master keys without restarting the process.
+type Watcher struct {
+	// OnChange is invoked with the new master keys after they changed. It must
+	// be set before Run is called.
+	OnChange func(Master)
+	// Debounce is the time to wait after the last change in the key directory
+	// before the keys are reloaded. Several changes in quick succession, e.g.,
+	// of both key files, result in a single reload. If zero,
+	// DefaultWatchDebounce is used.
+	Debounce time.Duration
+
+	dir  string
+	opts []Option
+
+	mtx    sync.Mutex
+	master Master
+}
+
+// NewWatcher loads the master keys from the directory path with LoadMaster
+// and returns a watcher for the directory. The options are used for every
+// reload.
+func NewWatcher(path string, opts ...Option) (*Watcher, error) {
+	m, err := LoadMaster(path, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateMaster(m); err != nil {
+		return nil, err
+	}
+	return &Watcher{
+		dir:    path,
+		opts:   opts,
+		master: m,
+	}, nil
+}
+
+// Master returns the current master keys.
+func (w *Watcher) Master() Master {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.master
+}
+
+// Run watches the key directory until the context is canceled. If a reload
+// fails, because the key files are missing or malformed, the error is logged
+// and the current keys are kept.
+func (w *Watcher) Run(ctx context.Context) error {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return serrors.Wrap("creating file watcher", err)
+	}
+	defer fw.Close()
+	// The directory is watched instead of the files, so that files that are
+	// replaced, e.g., by renaming a new file over them, are still watched.
+	if err := fw.Add(w.dir); err != nil {
+		return serrors.Wrap("watching key directory", err, "dir", w.dir)
+	}
+	debounce := w.Debounce
+	if debounce == 0 {
+		debounce = DefaultWatchDebounce
+	}
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-fw.Events:
+			if !ok {
+				return nil
+			}
+			timer.Reset(debounce)
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return nil
+			}
+			log.FromCtx(ctx).Error("Watching master keys", "dir", w.dir, "err", err)
+		case <-timer.C:
+			w.reload(ctx)
+		}
+	}
+}
+
+// reload loads the master keys and swaps them if they are valid and differ
+// from the current ones.
+func (w *Watcher) reload(ctx context.Context) {
+	m, err := LoadMaster(w.dir, w.opts...)
+	if err == nil {
+		err = validateMaster(m)
+	}
+	if err != nil {
+		log.FromCtx(ctx).Error("Reloading master keys failed, keeping current keys",
+			"dir", w.dir, "err", err)
+		return
+	}
+	w.mtx.Lock()
+	changed := !bytes.Equal(m.Key0, w.master.Key0) || !bytes.Equal(m.Key1, w.master.Key1) ||
+		!bytes.Equal(m.Key2, w.master.Key2) || (m.Key2 == nil) != (w.master.Key2 == nil)
+	if changed {
+		w.master = m
+	}
+	w.mtx.Unlock()
+	if !changed {
+		return
+	}
+	log.FromCtx(ctx).Info("Master keys reloaded", "dir", w.dir)
+	if w.OnChange != nil {
+		w.OnChange(m)
+	}
+}
+
+// validateMaster checks that both master keys are set.
+func validateMaster(m Master) error {
+	if len(m.Key0) == 0 || len(m.Key1) == 0 {
+		return serrors.New("master key empty")
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyconf
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeMaster(t *testing.T, dir string, key0, key1 []byte) {
+	t.Helper()
+	for file, key := range map[string][]byte{MasterKey0: key0, MasterKey1: key1} {
+		enc := base64.StdEncoding.EncodeToString(key)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(enc), 0o600))
+	}
+}
+
+func TestNewWatcher(t *testing.T) {
+	w, err := NewWatcher("testdata")
+	require.NoError(t, err)
+	assert.Equal(t, Master{Key0: mstr0, Key1: mstr1}, w.Master())
+
+	_, err = NewWatcher(t.TempDir())
+	assert.ErrorIs(t, err, ErrOpen)
+
+	dir := t.TempDir()
+	writeMaster(t, dir, mstr0, nil)
+	_, err = NewWatcher(dir)
+	assert.Error(t, err)
+}
+
+func TestWatcherReload(t *testing.T) {
+	dir := t.TempDir()
+	writeMaster(t, dir, mstr0, mstr1)
+	w, err := NewWatcher(dir)
+	require.NoError(t, err)
+	var updates []Master
+	w.OnChange = func(m Master) { updates = append(updates, m) }
+	ctx := context.Background()
+
+	// Unchanged keys are not reported.
+	w.reload(ctx)
+	assert.Empty(t, updates)
+
+	// Keys that fail to load are not swapped in.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, MasterKey0), []byte("!!"), 0o600))
+	w.reload(ctx)
+	assert.Empty(t, updates)
+	assert.Equal(t, Master{Key0: mstr0, Key1: mstr1}, w.Master())
+
+	// Empty keys are not swapped in.
+	writeMaster(t, dir, mstr0, nil)
+	w.reload(ctx)
+	assert.Empty(t, updates)
+	assert.Equal(t, Master{Key0: mstr0, Key1: mstr1}, w.Master())
+
+	// New keys are swapped in and reported.
+	writeMaster(t, dir, mstr1, mstr0)
+	w.reload(ctx)
+	assert.Equal(t, []Master{{Key0: mstr1, Key1: mstr0}}, updates)
+	assert.Equal(t, Master{Key0: mstr1, Key1: mstr0}, w.Master())
+
+	// An added third key is reported.
+	enc := base64.StdEncoding.EncodeToString(mstr0)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, MasterKey2), []byte(enc), 0o600))
+	w.reload(ctx)
+	require.Len(t, updates, 2)
+	assert.Equal(t, Master{Key0: mstr1, Key1: mstr0, Key2: mstr0}, updates[1])
+}
+
+func TestWatcherRun(t *testing.T) {
+	dir := t.TempDir()
+	writeMaster(t, dir, mstr0, mstr1)
+	w, err := NewWatcher(dir)
+	require.NoError(t, err)
+	updates := make(chan Master, 1)
+	w.OnChange = func(m Master) { updates <- m }
+	w.Debounce = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+
+	// The watch is set up asynchronously, so keep writing until the change is
+	// picked up.
+	want := Master{Key0: mstr1, Key1: mstr0}
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(5 * time.Second)
+loop:
+	for {
+		writeMaster(t, dir, want.Key0, want.Key1)
+		select {
+		case m := <-updates:
+			assert.Equal(t, want, m)
+			break loop
+		case <-ticker.C:
+		case <-timeout:
+			t.Fatal("keys were not reloaded")
+		}
+	}
+	assert.Equal(t, want, w.Master())
+
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/scionproto/scion/pkg/log"
 	"github.com/scionproto/scion/pkg/private/serrors"
 	"github.com/scionproto/scion/pkg/private/util"
+	"github.com/scionproto/scion/pkg/slayers"
 	"github.com/scionproto/scion/private/config"
 	"github.com/scionproto/scion/private/env"
 	api "github.com/scionproto/scion/private/mgmtapi"
@@ -32,6 +33,15 @@ import (
 
 const idSample = "router-1"
 
+// suppressibleSCMPTypes are the SCMP types that the router generates.
+var suppressibleSCMPTypes = map[int]bool{
+	int(slayers.SCMPTypeDestinationUnreachable):   true,
+	int(slayers.SCMPTypeParameterProblem):         true,
+	int(slayers.SCMPTypeExternalInterfaceDown):    true,
+	int(slayers.SCMPTypeInternalConnectivityDown): true,
+	int(slayers.SCMPTypeTracerouteReply):          true,
+}
+
 type Config struct {
 	General  env.General  `toml:"general,omitempty"`
 	Features env.Features `toml:"features,omitempty"`
@@ -48,6 +58,10 @@ type RouterConfig struct {
 	NumSlowPathProcessors int `toml:"num_slow_processors,omitempty"`
 	BatchSize             int `toml:"batch_size,omitempty"`
 	BFD                   BFD `toml:"bfd,omitempty"`
+	// SuppressedSCMPTypes lists the SCMP types that the router does not
+	// generate. Packets that would trigger one of these messages are
+	// silently dropped instead.
+	SuppressedSCMPTypes []int `toml:"suppressed_scmp_types,omitempty"`
 	// TODO: These two values were introduced to override the port range for
 	// configured router in the context of acceptance tests. However, this
 	// introduces two sources for the port configuration. We should remove this
@@ -85,6 +99,12 @@ func (cfg *RouterConfig) Validate() error {
 	if cfg.NumSlowPathProcessors < 1 {
 		return serrors.New("Provided router config is invalid. NumSlowPathProcessors < 1")
 	}
+	for _, t := range cfg.SuppressedSCMPTypes {
+		if !suppressibleSCMPTypes[t] {
+			return serrors.New("provided router config is invalid. "+
+				"SCMP type cannot be suppressed", "type", t)
+		}
+	}
 	if cfg.DispatchedPortStart != nil {
 		if cfg.DispatchedPortEnd == nil {
 			return serrors.New("provided router config is invalid. " +
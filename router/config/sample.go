@@ -35,4 +35,11 @@ num_slow_processors = 1
 # read or write from / to the network socket.
 # (default 256)
 batch_size = 256
+
+# The SCMP types that the router does not generate. A packet that would
+# trigger one of these SCMP messages is silently dropped. Supported are the
+# types 1 (destination unreachable), 4 (parameter problem), 5 (external
+# interface down), 6 (internal connectivity down) and 131 (traceroute reply).
+# (default [])
+suppressed_scmp_types = []
 `
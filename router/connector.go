@@ -22,6 +22,7 @@ import (
 	"github.com/scionproto/scion/pkg/log"
 	"github.com/scionproto/scion/pkg/private/serrors"
 	"github.com/scionproto/scion/pkg/segment/iface"
+	"github.com/scionproto/scion/pkg/slayers"
 	"github.com/scionproto/scion/private/env"
 	"github.com/scionproto/scion/private/underlay/conn"
 	"github.com/scionproto/scion/router/config"
@@ -51,12 +52,17 @@ var errMultiIA = serrors.New("different IA not allowed")
 // NewConnector returns a new connector: a data plane decorated with
 // a configuration interface.
 func NewConnector(config config.RouterConfig, features env.Features) *Connector {
+	suppressed := make([]slayers.SCMPType, 0, len(config.SuppressedSCMPTypes))
+	for _, t := range config.SuppressedSCMPTypes {
+		suppressed = append(suppressed, slayers.SCMPType(t))
+	}
 	return &Connector{
 		DataPlane: makeDataPlane(
 			RunConfig{
 				NumProcessors:         config.NumProcessors,
 				NumSlowPathProcessors: config.NumSlowPathProcessors,
 				BatchSize:             config.BatchSize,
+				SuppressedSCMPTypes:   suppressed,
 			},
 			features.ExperimentalSCMPAuthentication,
 		),
@@ -25,6 +25,7 @@ import (
 	"hash"
 	"net"
 	"net/netip"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -227,6 +228,8 @@ var (
 	ingressInterfaceInvalid       = errors.New("ingress interface invalid")
 	macVerificationFailed         = errors.New("MAC verification failed")
 	badPacketSize                 = errors.New("bad packet size")
+	badHeaderLength               = errors.New("bad header length")
+	scmpSuppressed                = errors.New("SCMP type suppressed")
 
 	// zeroBuffer will be used to reset the Authenticator option in the
 	// scionPacketProcessor.OptAuth
@@ -575,6 +578,9 @@ type RunConfig struct {
 	NumProcessors         int
 	NumSlowPathProcessors int
 	BatchSize             int
+	// SuppressedSCMPTypes are the SCMP types that are never generated. The
+	// packets that would trigger them are dropped.
+	SuppressedSCMPTypes []slayers.SCMPType
 }
 
 func (d *dataPlane) Run(ctx context.Context) error {
@@ -677,7 +683,14 @@ func (d *dataPlane) runProcessor(id int, q <-chan *Packet, slowQ chan<- *Packet)
 		case pForward:
 			// Normal processing proceeds.
 		case pSlowPath:
-			// Not an error, processing continues on the slow path.
+			// Not an error, processing continues on the slow path. If the SCMP
+			// message it would generate is suppressed, the packet is dropped
+			// right away, such that it does not occupy the slow path.
+			if p.slowPathRequest.spType.suppressed(d.RunConfig.SuppressedSCMPTypes) {
+				metrics.DroppedPacketsSCMPSuppressed.Inc()
+				d.returnPacketToPool(p)
+				continue
+			}
 			select {
 			case slowQ <- p:
 			default:
@@ -1029,6 +1042,16 @@ const (
 	slowPathRouterAlertEgress               = -2
 )
 
+// suppressed returns whether the SCMP message generated on the slow path is of
+// one of the given types. Router alerts result in traceroute replies.
+func (t slowPathType) suppressed(types []slayers.SCMPType) bool {
+	typ := slayers.SCMPTypeTracerouteReply
+	if t >= 0 {
+		typ = slayers.SCMPType(t)
+	}
+	return slices.Contains(types, typ)
+}
+
 func (p *slowPathPacketProcessor) packSCMP(
 	typ slayers.SCMPType,
 	code slayers.SCMPCode,
@@ -1036,6 +1059,10 @@ func (p *slowPathPacketProcessor) packSCMP(
 	isError bool,
 ) error {
 
+	if slices.Contains(p.d.RunConfig.SuppressedSCMPTypes, typ) {
+		return serrors.JoinNoStack(scmpSuppressed, nil, "type", typ)
+	}
+
 	// check invoking packet was an SCMP error:
 	if p.lastLayer.NextLayerType() == slayers.LayerTypeSCMP {
 		var scmpLayer slayers.SCMP
@@ -1558,6 +1585,26 @@ func (p *slowPathPacketProcessor) handleSCMPTraceRouteRequest(ifID uint16) error
 	return p.packSCMP(slayers.SCMPTypeTracerouteReply, 0, &scmpP, false)
 }
 
+// validateHdrLen checks that the header length in the common header matches
+// the length of the address and path headers. The decoding only rejects header
+// lengths that are too short for the path, a header length that is too long
+// is caught here.
+func (p *scionPacketProcessor) validateHdrLen() disposition {
+	hdrLen := slayers.CmnHdrLen + p.scionLayer.AddrHdrLen() + p.scionLayer.Path.Len()
+	if int(p.scionLayer.HdrLen)*slayers.LineLen == hdrLen {
+		return pForward
+	}
+	log.Debug("SCMP response", "cause", badHeaderLength, "header", p.scionLayer.HdrLen,
+		"actual", hdrLen)
+	p.pkt.slowPathRequest = slowPathRequest{
+		spType: slowPathType(slayers.SCMPTypeParameterProblem),
+		code:   slayers.SCMPCodeInvalidCommonHeader,
+		// Offset of the HdrLen field in the common header.
+		pointer: 5,
+	}
+	return pSlowPath
+}
+
 func (p *scionPacketProcessor) validatePktLen() disposition {
 	if int(p.scionLayer.PayloadLen) == len(p.scionLayer.Payload) {
 		return pForward
@@ -1606,6 +1653,9 @@ func (p *scionPacketProcessor) process() disposition {
 	if disp := p.validateIngressID(); disp != pForward {
 		return disp
 	}
+	if disp := p.validateHdrLen(); disp != pForward {
+		return disp
+	}
 	if disp := p.validatePktLen(); disp != pForward {
 		return disp
 	}
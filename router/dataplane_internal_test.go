@@ -448,6 +448,31 @@ func TestSlowPathProcessing(t *testing.T) {
 			},
 			expectedLayerType: slayers.LayerTypeSCMPParameterProblem,
 		},
+		"invalid hdr len": {
+			prepareDP: func(ctrl *gomock.Controller) *dataPlane {
+				return newDP(
+					mockExternalInterfaces,
+					nil,
+					mock_router.NewMockBatchConn(ctrl),
+					mockInternalNextHops,
+					mockServices,
+					addr.MustParseIA("1-ff00:0:110"), nil, testKey)
+			},
+			mockMsg: func() []byte {
+				spkt := prepBaseMsg(t, payload, 0)
+				ret := toMsg(t, spkt)
+				// Claim one line more than the address and path headers take.
+				ret[5]++
+				return ret
+			},
+			srcInterface: 1,
+			expectedSlowPathRequest: slowPathRequest{
+				spType:  slowPathType(slayers.SCMPTypeParameterProblem),
+				code:    slayers.SCMPCodeInvalidCommonHeader,
+				pointer: 5,
+			},
+			expectedLayerType: slayers.LayerTypeSCMPParameterProblem,
+		},
 	}
 
 	for name, tc := range testCases {
@@ -485,6 +510,81 @@ func TestSlowPathProcessing(t *testing.T) {
 	}
 }
 
+func TestSlowPathSuppressedSCMP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	payload := []byte("actualpayloadbytes")
+
+	testCases := map[string]struct {
+		mockMsg       func() []byte
+		expSuppressed bool
+		assertFunc    assert.ErrorAssertionFunc
+	}{
+		"suppressed type": {
+			mockMsg: func() []byte {
+				spkt := prepBaseMsg(t, payload, 0)
+				spkt.DstIA = addr.MustParseIA("1-ff00:0:f1")
+				return toMsg(t, spkt)
+			},
+			expSuppressed: true,
+			assertFunc: func(t assert.TestingT, err error, _ ...any) bool {
+				return assert.ErrorIs(t, err, scmpSuppressed)
+			},
+		},
+		"other type": {
+			mockMsg: func() []byte {
+				spkt := prepBaseMsg(t, payload, 0)
+				_ = spkt.SetDstAddr(addr.MustParseHost("CS"))
+				spkt.DstIA = addr.MustParseIA("1-ff00:0:110")
+				return toMsg(t, spkt)
+			},
+			assertFunc: assert.NoError,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dp := newDP(
+				[]uint16{1},
+				nil,
+				mock_router.NewMockBatchConn(ctrl),
+				map[uint16]netip.AddrPort{},
+				map[addr.SVC][]netip.AddrPort{},
+				addr.MustParseIA("1-ff00:0:110"), nil, testKey)
+			dp.RunConfig.SuppressedSCMPTypes = []slayers.SCMPType{
+				slayers.SCMPTypeParameterProblem,
+			}
+
+			rp := tc.mockMsg()
+			pkt := Packet{}
+			pkt.init(&[bufSize]byte{})
+			pkt.Reset()
+			pkt.Link = newMockLink(1)
+			pkt.RawPacket = pkt.RawPacket[:len(rp)]
+			copy(pkt.RawPacket, rp)
+
+			processor := newPacketProcessor(dp)
+			assert.Equal(t, pSlowPath, processor.processPkt(&pkt))
+			assert.Equal(t, tc.expSuppressed,
+				pkt.slowPathRequest.spType.suppressed(dp.RunConfig.SuppressedSCMPTypes))
+			err := newSlowPathProcessor(dp).processPacket(&pkt)
+			tc.assertFunc(t, err)
+		})
+	}
+}
+
+func TestSlowPathTypeSuppressed(t *testing.T) {
+	types := []slayers.SCMPType{
+		slayers.SCMPTypeParameterProblem,
+		slayers.SCMPTypeTracerouteReply,
+	}
+	assert.True(t, slowPathType(slayers.SCMPTypeParameterProblem).suppressed(types))
+	assert.False(t, slowPathType(slayers.SCMPTypeDestinationUnreachable).suppressed(types))
+	assert.True(t, slowPathType(slowPathRouterAlertIngress).suppressed(types))
+	assert.True(t, slowPathType(slowPathRouterAlertEgress).suppressed(types))
+	assert.False(t, slowPathType(slowPathRouterAlertIngress).suppressed(nil))
+}
+
 func toMsg(t *testing.T, spkt *slayers.SCION) []byte {
 	t.Helper()
 	buffer := gopacket.NewSerializeBuffer()
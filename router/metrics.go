@@ -261,14 +261,15 @@ type InterfaceMetrics map[sizeClass]trafficMetrics
 // trafficMetrics groups all the metrics instances that all share the same interface AND
 // sizeClass label values (but have different names - i.e. they count different things).
 type trafficMetrics struct {
-	InputBytesTotal             prometheus.Counter
-	InputPacketsTotal           prometheus.Counter
-	DroppedPacketsInvalid       prometheus.Counter
-	DroppedPacketsBusyProcessor prometheus.Counter
-	DroppedPacketsBusyForwarder prometheus.Counter
-	DroppedPacketsBusySlowPath  prometheus.Counter
-	ProcessedPackets            prometheus.Counter
-	Output                      [ttMax]outputMetrics
+	InputBytesTotal              prometheus.Counter
+	InputPacketsTotal            prometheus.Counter
+	DroppedPacketsInvalid        prometheus.Counter
+	DroppedPacketsBusyProcessor  prometheus.Counter
+	DroppedPacketsBusyForwarder  prometheus.Counter
+	DroppedPacketsBusySlowPath   prometheus.Counter
+	DroppedPacketsSCMPSuppressed prometheus.Counter
+	ProcessedPackets             prometheus.Counter
+	Output                       [ttMax]outputMetrics
 }
 
 // outputMetrics groups all the metrics about traffic that has reached the output stage. Metrics
@@ -331,12 +332,17 @@ func newTrafficMetrics(
 	c.DroppedPacketsBusySlowPath =
 		metrics.DroppedPacketsTotal.MustCurryWith(ifLabels).MustCurryWith(scLabels).With(reasonMap)
 
+	reasonMap["reason"] = "scmp_suppressed"
+	c.DroppedPacketsSCMPSuppressed =
+		metrics.DroppedPacketsTotal.MustCurryWith(ifLabels).MustCurryWith(scLabels).With(reasonMap)
+
 	c.InputBytesTotal.Add(0)
 	c.InputPacketsTotal.Add(0)
 	c.DroppedPacketsInvalid.Add(0)
 	c.DroppedPacketsBusyProcessor.Add(0)
 	c.DroppedPacketsBusyForwarder.Add(0)
 	c.DroppedPacketsBusySlowPath.Add(0)
+	c.DroppedPacketsSCMPSuppressed.Add(0)
 	c.ProcessedPackets.Add(0)
 	return c
 }
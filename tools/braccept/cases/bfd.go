@@ -18,18 +18,39 @@ import (
 	"hash"
 	"net"
 	"path/filepath"
+	"time"
 
 	"github.com/gopacket/gopacket"
 	"github.com/gopacket/gopacket/layers"
 
 	"github.com/scionproto/scion/pkg/addr"
 	"github.com/scionproto/scion/pkg/slayers"
-	"github.com/scionproto/scion/pkg/slayers/path"
 	"github.com/scionproto/scion/pkg/slayers/path/empty"
 	"github.com/scionproto/scion/pkg/slayers/path/onehop"
 	"github.com/scionproto/scion/tools/braccept/runner"
 )
 
+// bfdTimeout is the time to wait for the BFD reply of the router. The router
+// has to bootstrap the BFD session first, which can take longer than the
+// default timeout on slow hardware.
+const bfdTimeout = time.Second
+
+// bfdParams are the timing parameters of a BFD session as advertised in the
+// BFD control packets.
+type bfdParams struct {
+	detectMult    layers.BFDDetectMultiplier
+	desiredMinTx  layers.BFDTimeInterval
+	requiredMinRx layers.BFDTimeInterval
+}
+
+var (
+	// routerBFDParams are the parameters of the router under test, i.e., the
+	// defaults of the router configuration.
+	routerBFDParams = bfdParams{detectMult: 3, desiredMinTx: 200000, requiredMinRx: 200000}
+	// peerBFDParams are the parameters that the cases advertise by default.
+	peerBFDParams = bfdParams{detectMult: 3, desiredMinTx: 1000000, requiredMinRx: 200000}
+)
+
 func bfdNormalizePacket(pkt gopacket.Packet) {
 	// Apply all the standard normalizations.
 	runner.DefaultNormalizePacket(pkt)
@@ -55,43 +76,62 @@ func bfdNormalizePacket(pkt gopacket.Packet) {
 // ExternalBFD sends an unbootstrapped BFD message to an external interface
 // and expects a bootstrapped BFD message on the same interface.
 func ExternalBFD(artifactsDir string, mac hash.Hash) runner.Case {
+	return externalBFD(artifactsDir, mac, "ExternalBFD", peerBFDParams)
+}
+
+// BFDParameters sends unbootstrapped BFD messages with different detect
+// multipliers and intervals to an external and an internal interface. The
+// peer parameters do not change what the router advertises: the router
+// replies with its own detect multiplier and intervals, and only uses the
+// parameters of the peer to compute the detection time and the actual
+// transmission interval, i.e., the maximum of its desired minimum transmission
+// interval and the required minimum reception interval of the peer (RFC 5880,
+// section 6.8.7).
+//
+// The cases use the same interfaces as ExternalBFD and InternalBFD. The
+// session on an interface is in the Init state after the first case, and
+// stays there for the following cases, as they all advertise the Down state.
+func BFDParameters(artifactsDir string, mac hash.Hash) []runner.Case {
+	peers := []struct {
+		name   string
+		params bfdParams
+	}{
+		{"DetectMult1", bfdParams{detectMult: 1, desiredMinTx: 1000000, requiredMinRx: 200000}},
+		{"DetectMult10", bfdParams{detectMult: 10, desiredMinTx: 1000000, requiredMinRx: 200000}},
+		{"FastPeer", bfdParams{detectMult: 3, desiredMinTx: 50000, requiredMinRx: 50000}},
+		// The router sends at the rate required by the peer, which must thus
+		// be below bfdTimeout.
+		{"SlowPeer", bfdParams{detectMult: 3, desiredMinTx: 2000000, requiredMinRx: 500000}},
+	}
+	var cs []runner.Case
+	for _, p := range peers {
+		cs = append(cs,
+			externalBFD(artifactsDir, mac, "ExternalBFD"+p.name, p.params),
+			internalBFD(artifactsDir, mac, "InternalBFD"+p.name, p.params),
+		)
+	}
+	return cs
+}
+
+// externalBFD sends an unbootstrapped BFD message with the parameters of the
+// peer to the external interface 131 and expects a bootstrapped BFD message
+// with the parameters of the router on the same interface.
+func externalBFD(artifactsDir string, mac hash.Hash, name string, peer bfdParams) runner.Case {
 	options := gopacket.SerializeOptions{
 		FixLengths:       true,
 		ComputeChecksums: true,
 	}
-	ethernet := &layers.Ethernet{
-		SrcMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
-		DstMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x13},
-		EthernetType: layers.EthernetTypeIPv4,
-	}
-	ip := &layers.IPv4{
-		Version:  4,
-		IHL:      5,
-		TTL:      64,
-		SrcIP:    net.IP{192, 168, 13, 3},
-		DstIP:    net.IP{192, 168, 13, 2},
-		Protocol: layers.IPProtocolUDP,
-		Flags:    layers.IPv4DontFragment,
-	}
-	udp := &layers.UDP{
-		SrcPort: layers.UDPPort(40000),
-		DstPort: layers.UDPPort(50000),
-	}
-	_ = udp.SetNetworkLayerForChecksum(ip)
+	ethernet, ip, udp := underlay(
+		net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
+		net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x13},
+		net.IP{192, 168, 13, 3},
+		net.IP{192, 168, 13, 2},
+		40000, 50000,
+	)
 	localIA, _ := addr.ParseIA("1-ff00:0:1")
 	remoteIA, _ := addr.ParseIA("1-ff00:0:3")
-	ohp := &onehop.Path{
-		Info: path.InfoField{
-			ConsDir:   true,
-			Timestamp: 0, // TODO: util.TimeToSecs(time.Now()),
-		},
-		FirstHop: path.HopField{
-			ExpTime:     63,
-			ConsIngress: 0,
-			ConsEgress:  131,
-		},
-	}
-	ohp.FirstHop.Mac = path.MAC(mac, ohp.Info, ohp.FirstHop, nil)
+	// TODO: Set the timestamp to util.TimeToSecs(time.Now()).
+	ohp := newOneHopPath(mac, true, 0, 131, 63)
 	scionL := &slayers.SCION{
 		Version:      0,
 		TrafficClass: 0xb8,
@@ -113,11 +153,11 @@ func ExternalBFD(artifactsDir string, mac hash.Hash) runner.Case {
 	bfd := &layers.BFD{
 		Version:               1,
 		State:                 layers.BFDStateDown,
-		DetectMultiplier:      3,
+		DetectMultiplier:      peer.detectMult,
 		MyDiscriminator:       12345,
 		YourDiscriminator:     0,
-		DesiredMinTxInterval:  1000000,
-		RequiredMinRxInterval: 200000,
+		DesiredMinTxInterval:  peer.desiredMinTx,
+		RequiredMinRxInterval: peer.requiredMinRx,
 	}
 	// Prepare input packet
 	input := gopacket.NewSerializeBuffer()
@@ -144,26 +184,36 @@ func ExternalBFD(artifactsDir string, mac hash.Hash) runner.Case {
 	}
 	bfd.State = layers.BFDStateInit
 	bfd.YourDiscriminator = 12345
-	bfd.DesiredMinTxInterval = 200000
+	bfd.DetectMultiplier = routerBFDParams.detectMult
+	bfd.DesiredMinTxInterval = routerBFDParams.desiredMinTx
+	bfd.RequiredMinRxInterval = routerBFDParams.requiredMinRx
 	err = gopacket.SerializeLayers(want, options, ethernet, ip, udp, scionL, bfd)
 	if err != nil {
 		panic(err)
 	}
 	return runner.Case{
-		Name:              "ExternalBFD",
+		Name:              name,
 		WriteTo:           "veth_131_host",
 		ReadFrom:          "veth_131_host",
 		Input:             input.Bytes(),
 		Want:              want.Bytes(),
-		StoreDir:          filepath.Join(artifactsDir, "ExternalBFD"),
+		StoreDir:          filepath.Join(artifactsDir, name),
 		IgnoreNonMatching: true,
 		NormalizePacket:   bfdNormalizePacket,
+		Timeout:           bfdTimeout,
 	}
 }
 
 // InternalBFD sends an unbootstrapped BFD message to an internal interface
 // and expects a bootstrapped BFD message on the same interface.
 func InternalBFD(artifactsDir string, mac hash.Hash) runner.Case {
+	return internalBFD(artifactsDir, mac, "InternalBFD", peerBFDParams)
+}
+
+// internalBFD sends an unbootstrapped BFD message with the parameters of the
+// peer to the internal interface and expects a bootstrapped BFD message with
+// the parameters of the router on the same interface.
+func internalBFD(artifactsDir string, mac hash.Hash, name string, peer bfdParams) runner.Case {
 	options := gopacket.SerializeOptions{
 		FixLengths:       true,
 		ComputeChecksums: true,
@@ -209,11 +259,11 @@ func InternalBFD(artifactsDir string, mac hash.Hash) runner.Case {
 	bfd := &layers.BFD{
 		Version:               1,
 		State:                 layers.BFDStateDown,
-		DetectMultiplier:      3,
+		DetectMultiplier:      peer.detectMult,
 		MyDiscriminator:       12345,
 		YourDiscriminator:     0,
-		DesiredMinTxInterval:  1000000,
-		RequiredMinRxInterval: 200000,
+		DesiredMinTxInterval:  peer.desiredMinTx,
+		RequiredMinRxInterval: peer.requiredMinRx,
 	}
 	// Prepare input packet
 	input := gopacket.NewSerializeBuffer()
@@ -238,19 +288,146 @@ func InternalBFD(artifactsDir string, mac hash.Hash) runner.Case {
 	}
 	bfd.State = layers.BFDStateInit
 	bfd.YourDiscriminator = 12345
-	bfd.DesiredMinTxInterval = 200000
+	bfd.DetectMultiplier = routerBFDParams.detectMult
+	bfd.DesiredMinTxInterval = routerBFDParams.desiredMinTx
+	bfd.RequiredMinRxInterval = routerBFDParams.requiredMinRx
 	err = gopacket.SerializeLayers(want, options, ethernet, ip, udp, scionL, bfd)
 	if err != nil {
 		panic(err)
 	}
 	return runner.Case{
-		Name:              "InternalBFD",
+		Name:              name,
 		WriteTo:           "veth_int_host",
 		ReadFrom:          "veth_int_host",
 		Input:             input.Bytes(),
 		Want:              want.Bytes(),
-		StoreDir:          filepath.Join(artifactsDir, "InternalBFD"),
+		StoreDir:          filepath.Join(artifactsDir, name),
+		IgnoreNonMatching: true,
+		NormalizePacket:   bfdNormalizePacket,
+		Timeout:           bfdTimeout,
+	}
+}
+
+// ExternalBFDBackToBack sends two BFD messages in quick succession to an
+// external interface and checks that the session state progresses
+// monotonically. The first message is unbootstrapped and must move the session
+// to Init, the second one is sent right after the first reply and must move the
+// session to Up, instead of resetting it to Down or Init.
+//
+// The cases must be run in the given order, because the session state is kept
+// by the router between the cases. Interface 141 is used, so that the cases are
+// not influenced by the session state left behind by ExternalBFD.
+func ExternalBFDBackToBack(artifactsDir string, mac hash.Hash) []runner.Case {
+	return []runner.Case{
+		externalBFDExchange(artifactsDir, mac, "ExternalBFDBackToBackInit",
+			layers.BFDStateDown, 0, layers.BFDStateInit),
+		externalBFDExchange(artifactsDir, mac, "ExternalBFDBackToBackUp",
+			layers.BFDStateInit, 1, layers.BFDStateUp),
+	}
+}
+
+// externalBFDExchange sends a BFD message with the given state to the external
+// interface 141 and expects a reply with the wanted state on the same
+// interface.
+func externalBFDExchange(
+	artifactsDir string,
+	mac hash.Hash,
+	name string,
+	state layers.BFDState,
+	yourDiscriminator layers.BFDDiscriminator,
+	wantState layers.BFDState,
+) runner.Case {
+
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+	ethernet := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
+		DstMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x14},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    net.IP{192, 168, 14, 3},
+		DstIP:    net.IP{192, 168, 14, 2},
+		Protocol: layers.IPProtocolUDP,
+		Flags:    layers.IPv4DontFragment,
+	}
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(40000),
+		DstPort: layers.UDPPort(50000),
+	}
+	_ = udp.SetNetworkLayerForChecksum(ip)
+	localIA := addr.MustParseIA("1-ff00:0:1")
+	remoteIA := addr.MustParseIA("1-ff00:0:4")
+	ohp := newOneHopPath(mac, true, 0, 141, 63)
+	scionL := &slayers.SCION{
+		Version:      0,
+		TrafficClass: 0xb8,
+		FlowID:       0xdead,
+		NextHdr:      slayers.L4BFD,
+		PathType:     onehop.PathType,
+		Path:         ohp,
+		DstIA:        localIA,
+		SrcIA:        remoteIA,
+	}
+	if err := scionL.SetSrcAddr(addr.MustParseHost("192.168.14.3")); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetDstAddr(addr.MustParseHost("192.168.14.2")); err != nil {
+		panic(err)
+	}
+	bfd := &layers.BFD{
+		Version:               1,
+		State:                 state,
+		DetectMultiplier:      3,
+		MyDiscriminator:       12345,
+		YourDiscriminator:     yourDiscriminator,
+		DesiredMinTxInterval:  1000000,
+		RequiredMinRxInterval: 200000,
+	}
+	// Prepare input packet
+	input := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(input, options,
+		ethernet, ip, udp, scionL, bfd,
+	); err != nil {
+		panic(err)
+	}
+	// Prepare want packet
+	want := gopacket.NewSerializeBuffer()
+	ethernet.SrcMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x14}
+	ethernet.DstMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef}
+	ip.SrcIP = net.IP{192, 168, 14, 2}
+	ip.DstIP = net.IP{192, 168, 14, 3}
+	udp.SrcPort, udp.DstPort = udp.DstPort, udp.SrcPort
+	scionL.DstIA = remoteIA
+	scionL.SrcIA = localIA
+	if err := scionL.SetSrcAddr(addr.MustParseHost("192.168.14.2")); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetDstAddr(addr.MustParseHost("192.168.14.3")); err != nil {
+		panic(err)
+	}
+	bfd.State = wantState
+	bfd.YourDiscriminator = 12345
+	bfd.DesiredMinTxInterval = 200000
+	if err := gopacket.SerializeLayers(want, options,
+		ethernet, ip, udp, scionL, bfd,
+	); err != nil {
+		panic(err)
+	}
+	return runner.Case{
+		Name:              name,
+		WriteTo:           "veth_141_host",
+		ReadFrom:          "veth_141_host",
+		Input:             input.Bytes(),
+		Want:              want.Bytes(),
+		StoreDir:          filepath.Join(artifactsDir, name),
 		IgnoreNonMatching: true,
 		NormalizePacket:   bfdNormalizePacket,
+		Timeout:           bfdTimeout,
 	}
 }
@@ -160,3 +160,273 @@ func ChildToChildXover(artifactsDir string, mac hash.Hash) runner.Case {
 		StoreDir: filepath.Join(artifactsDir, "ChildToChildXover"),
 	}
 }
+
+// ChildToChildXoverReverseConsDir tests transit traffic with a xover at which
+// the ConsDir flips in the opposite way than in ChildToChildXover: the first
+// segment is traversed in construction direction and the second one against
+// it. The router must take the ingress interface from the ConsIngress of the
+// last hop of the first segment and the egress interface from the ConsIngress
+// of the first hop of the second segment, and it must verify the MACs of both
+// hops with the SegIDs as received. Neither SegID is updated by this router.
+func ChildToChildXoverReverseConsDir(artifactsDir string, mac hash.Hash) runner.Case {
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+
+	ethernet := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
+		DstMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x15},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    net.IP{192, 168, 15, 3},
+		DstIP:    net.IP{192, 168, 15, 2},
+		Protocol: layers.IPProtocolUDP,
+		Flags:    layers.IPv4DontFragment,
+	}
+
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(40000),
+		DstPort: layers.UDPPort(50000),
+	}
+	_ = udp.SetNetworkLayerForChecksum(ip)
+
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF:  2,
+				CurrINF: 0,
+				SegLen:  [3]uint8{3, 3, 0},
+			},
+			NumINF:  2,
+			NumHops: 6,
+		},
+		InfoFields: []path.InfoField{
+			// first seg, in construction direction
+			{
+				SegID:     0x111,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+			// second seg, against construction direction
+			{
+				SegID:     0x222,
+				ConsDir:   false,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []path.HopField{
+			{ConsIngress: 0, ConsEgress: 651},
+			{ConsIngress: 561, ConsEgress: 511},
+			{ConsIngress: 151, ConsEgress: 0},
+			{ConsIngress: 141, ConsEgress: 0},
+			{ConsIngress: 471, ConsEgress: 411},
+			{ConsIngress: 0, ConsEgress: 741},
+		},
+	}
+	sp.HopFields[2].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[2], nil)
+	sp.HopFields[3].Mac = path.MAC(mac, sp.InfoFields[1], sp.HopFields[3], nil)
+
+	scionL := &slayers.SCION{
+		Version:      0,
+		TrafficClass: 0xb8,
+		FlowID:       0xdead,
+		NextHdr:      slayers.L4UDP,
+		PathType:     scion.PathType,
+		SrcIA:        addr.MustParseIA("1-ff00:0:6"),
+		DstIA:        addr.MustParseIA("1-ff00:0:7"),
+		Path:         sp,
+	}
+
+	if err := scionL.SetSrcAddr(addr.MustParseHost("172.16.6.1")); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetDstAddr(addr.MustParseHost("174.16.7.1")); err != nil {
+		panic(err)
+	}
+
+	scionudp := &slayers.UDP{}
+	scionudp.SrcPort = 40111
+	scionudp.DstPort = 40222
+	scionudp.SetNetworkLayerForChecksum(scionL)
+
+	payload := []byte("actualpayloadbytes")
+
+	// Prepare input packet
+	input := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(input, options,
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	// Prepare want packet
+	want := gopacket.NewSerializeBuffer()
+	ethernet.SrcMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x14}
+	ethernet.DstMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef}
+	ip.SrcIP = net.IP{192, 168, 14, 2}
+	ip.DstIP = net.IP{192, 168, 14, 3}
+	udp.SrcPort, udp.DstPort = udp.DstPort, udp.SrcPort
+	// The router switches to the second segment and then moves on to the
+	// next hop of it. The SegIDs are left as is.
+	if err := sp.IncPath(); err != nil {
+		panic(err)
+	}
+	if err := sp.IncPath(); err != nil {
+		panic(err)
+	}
+
+	if err := gopacket.SerializeLayers(want, options,
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	return runner.Case{
+		Name:     "ChildToChildXoverReverseConsDir",
+		WriteTo:  "veth_151_host",
+		ReadFrom: "veth_141_host",
+		Input:    input.Bytes(),
+		Want:     want.Bytes(),
+		StoreDir: filepath.Join(artifactsDir, "ChildToChildXoverReverseConsDir"),
+	}
+}
+
+// ChildToChildXoverPathPointers tests transit traffic with a xover between two
+// segments that both continue beyond the router. The current hop field must
+// advance by two, past both hop fields of the router, and the current info
+// field by one. The expected indices are set explicitly instead of being
+// derived with IncPath, so that the check does not depend on the path
+// implementation under test.
+func ChildToChildXoverPathPointers(artifactsDir string, mac hash.Hash) runner.Case {
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+
+	ethernet := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
+		DstMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x15},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    net.IP{192, 168, 15, 3},
+		DstIP:    net.IP{192, 168, 15, 2},
+		Protocol: layers.IPProtocolUDP,
+		Flags:    layers.IPv4DontFragment,
+	}
+
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(40000),
+		DstPort: layers.UDPPort(50000),
+	}
+	_ = udp.SetNetworkLayerForChecksum(ip)
+
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF:  2,
+				CurrINF: 0,
+				SegLen:  [3]uint8{3, 3, 0},
+			},
+			NumINF:  2,
+			NumHops: 6,
+		},
+		InfoFields: []path.InfoField{
+			// up seg
+			{
+				SegID:     0x111,
+				ConsDir:   false,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+			// down seg
+			{
+				SegID:     0x222,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []path.HopField{
+			{ConsIngress: 651, ConsEgress: 0},
+			{ConsIngress: 511, ConsEgress: 561},
+			{ConsIngress: 0, ConsEgress: 151},
+			{ConsIngress: 0, ConsEgress: 141},
+			{ConsIngress: 411, ConsEgress: 471},
+			{ConsIngress: 741, ConsEgress: 0},
+		},
+	}
+	sp.HopFields[2].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[2], nil)
+	sp.InfoFields[0].UpdateSegID(sp.HopFields[2].Mac)
+	sp.HopFields[3].Mac = path.MAC(mac, sp.InfoFields[1], sp.HopFields[3], nil)
+
+	scionL := &slayers.SCION{
+		Version:      0,
+		TrafficClass: 0xb8,
+		FlowID:       0xdead,
+		NextHdr:      slayers.L4UDP,
+		PathType:     scion.PathType,
+		SrcIA:        addr.MustParseIA("1-ff00:0:6"),
+		DstIA:        addr.MustParseIA("1-ff00:0:7"),
+		Path:         sp,
+	}
+
+	if err := scionL.SetSrcAddr(addr.MustParseHost("172.16.6.1")); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetDstAddr(addr.MustParseHost("174.16.7.1")); err != nil {
+		panic(err)
+	}
+
+	scionudp := &slayers.UDP{}
+	scionudp.SrcPort = 40111
+	scionudp.DstPort = 40222
+	scionudp.SetNetworkLayerForChecksum(scionL)
+
+	payload := []byte("actualpayloadbytes")
+
+	// Prepare input packet
+	input := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(input, options,
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	// Prepare want packet
+	want := gopacket.NewSerializeBuffer()
+	ethernet.SrcMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x14}
+	ethernet.DstMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef}
+	ip.SrcIP = net.IP{192, 168, 14, 2}
+	ip.DstIP = net.IP{192, 168, 14, 3}
+	udp.SrcPort, udp.DstPort = udp.DstPort, udp.SrcPort
+	// The router consumes its hop field in the up segment (2) and in the down
+	// segment (3); the next AS processes hop field 4 of the down segment.
+	sp.PathMeta.CurrINF = 1
+	sp.PathMeta.CurrHF = 4
+	sp.InfoFields[0].UpdateSegID(sp.HopFields[2].Mac)
+	sp.InfoFields[1].UpdateSegID(sp.HopFields[3].Mac)
+
+	if err := gopacket.SerializeLayers(want, options,
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	return runner.Case{
+		Name:     "ChildToChildXoverPathPointers",
+		WriteTo:  "veth_151_host",
+		ReadFrom: "veth_141_host",
+		Input:    input.Bytes(),
+		Want:     want.Bytes(),
+		StoreDir: filepath.Join(artifactsDir, "ChildToChildXoverPathPointers"),
+	}
+}
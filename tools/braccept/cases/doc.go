@@ -25,6 +25,12 @@ Step 0. Refer to the following for the test's setup:
     Note that all MAC addresses of interfaces on the far side
     of the A/B/C/D routers are identical: f00d:cafe:beef
 
+The WriteTo and ReadFrom devices of a case may differ, e.g., to inject a
+packet on a parent interface and capture it on a child interface. Both devices
+must be provisioned as veth pairs by acceptance/router_multi/test.py, otherwise
+the runner fails the case. Cases whose input is delivered on several devices
+list them in ReadFromMulti instead of ReadFrom.
+
 Step 1. Add a new file with a representative name
 e.g. cases/child_to_child_xover.go
 
@@ -47,10 +53,15 @@ testcase.
 
 Step 3. In the braccept/main.go, include the above function
 
-	multi := []runner.Case{
-		cases.ChildToParent(artifactsDir, mac),
-		cases.ChildToChildXover(artifactsDir, mac),
-	}
+	b.Add(
+		cases.ChildToParent,
+		cases.ChildToChildXover,
+	)
+
+The constructors panic if the packets of a case can not be serialized. The
+runner.Builder recovers from the panic and reports the case as failed, while
+the other cases are still run. Check the construction of the cases without a
+router with "braccept -check".
 
 Step 4. Do a local run, which means set up a working router, execute the
 braccept, shutdown the router. This is done in sequence by:
@@ -0,0 +1,382 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cases
+
+import (
+	"hash"
+	"net"
+	"path/filepath"
+	"time"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+
+	"github.com/scionproto/scion/pkg/addr"
+	libepic "github.com/scionproto/scion/pkg/experimental/epic"
+	"github.com/scionproto/scion/pkg/private/util"
+	"github.com/scionproto/scion/pkg/slayers"
+	"github.com/scionproto/scion/pkg/slayers/path"
+	"github.com/scionproto/scion/pkg/slayers/path/epic"
+	"github.com/scionproto/scion/pkg/slayers/path/scion"
+	"github.com/scionproto/scion/tools/braccept/runner"
+)
+
+// EPIC packets are only valid for a few seconds after they were created.
+// Therefore, the packets of the EPIC cases are rebuilt right before they are
+// sent.
+
+// ParentToChildEPIC tests transit traffic with the EPIC path type over the
+// same BR host. The BR is at the penultimate hop of the path and verifies the
+// PHVF.
+func ParentToChildEPIC(artifactsDir string, mac hash.Hash) runner.Case {
+	c := runner.Case{
+		Name:     "ParentToChildEPIC",
+		WriteTo:  "veth_131_host",
+		ReadFrom: "veth_141_host",
+		StoreDir: filepath.Join(artifactsDir, "ParentToChildEPIC"),
+		Prepare: func(c *runner.Case) {
+			c.Input, c.Want = parentToChildEPIC(mac, false)
+		},
+	}
+	c.Prepare(&c)
+	return c
+}
+
+// ParentToChildEPICBadHVF tests that an EPIC packet with an invalid PHVF is
+// dropped by the BR at the penultimate hop of the path.
+func ParentToChildEPICBadHVF(artifactsDir string, mac hash.Hash) runner.Case {
+	c := runner.Case{
+		Name:     "ParentToChildEPICBadHVF",
+		WriteTo:  "veth_131_host",
+		ReadFrom: "veth_141_host",
+		StoreDir: filepath.Join(artifactsDir, "ParentToChildEPICBadHVF"),
+		Prepare: func(c *runner.Case) {
+			c.Input, _ = parentToChildEPIC(mac, true)
+		},
+	}
+	c.Prepare(&c)
+	return c
+}
+
+func parentToChildEPIC(mac hash.Hash, badHVF bool) ([]byte, []byte) {
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+
+	// Ethernet: SrcMAC=f0:0d:ca:fe:be:ef DstMAC=f0:0d:ca:fe:00:13 EthernetType=IPv4
+	ethernet := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
+		DstMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x13},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	// IP4: Src=192.168.13.3 Dst=192.168.13.2 NextHdr=UDP Flags=DF
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    net.IP{192, 168, 13, 3},
+		DstIP:    net.IP{192, 168, 13, 2},
+		Protocol: layers.IPProtocolUDP,
+		Flags:    layers.IPv4DontFragment,
+	}
+	// UDP: Src=40000 Dst=50000
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(40000),
+		DstPort: layers.UDPPort(50000),
+	}
+	_ = udp.SetNetworkLayerForChecksum(ip)
+
+	// pkt0.ParsePacket(`
+	//	SCION: NextHdr=UDP CurrInfoF=4 CurrHopF=6 SrcType=IPv4 DstType=IPv4 PathType=EPIC
+	//		ADDR: SrcIA=1-ff00:0:3 Src=174.16.3.1 DstIA=1-ff00:0:4 Dst=174.16.4.1
+	//		IF_1: ISD=1 Hops=3 Flags=ConsDir
+	//			HF_1: ConsIngress=0 ConsEgress=311
+	//			HF_2: ConsIngress=131 ConsEgress=141
+	//			HF_3: ConsIngress=411 ConsEgress=0
+	//	UDP_1: Src=40111 Dst=40222
+	// `)
+	now := time.Now()
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: 1,
+				SegLen: [3]uint8{3, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 3,
+		},
+		InfoFields: []path.InfoField{
+			{
+				SegID:     0x111,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(now),
+			},
+		},
+		HopFields: []path.HopField{
+			{ConsIngress: 0, ConsEgress: 311},
+			{ConsIngress: 131, ConsEgress: 141},
+			{ConsIngress: 411, ConsEgress: 0},
+		},
+	}
+	sp.HopFields[1].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[1], nil)
+	auth := path.FullMAC(mac, sp.InfoFields[0], sp.HopFields[1], nil)
+
+	ep := newEPICPath(sp, now)
+
+	payload := []byte("actualpayloadbytes")
+	scionL := &slayers.SCION{
+		Version:      0,
+		TrafficClass: 0xb8,
+		FlowID:       0xdead,
+		NextHdr:      slayers.L4UDP,
+		PathType:     epic.PathType,
+		SrcIA:        addr.MustParseIA("1-ff00:0:3"),
+		DstIA:        addr.MustParseIA("1-ff00:0:4"),
+		PayloadLen:   uint16(8 + len(payload)),
+		Path:         ep,
+	}
+	if err := scionL.SetSrcAddr(addr.MustParseHost("172.16.3.1")); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetDstAddr(addr.MustParseHost("174.16.4.1")); err != nil {
+		panic(err)
+	}
+	setPHVF(scionL, ep, auth, sp.InfoFields[0].Timestamp)
+	if badHVF {
+		ep.PHVF[0] ^= 0xff
+	}
+
+	scionudp := &slayers.UDP{}
+	scionudp.SrcPort = 40111
+	scionudp.DstPort = 40222
+	scionudp.SetNetworkLayerForChecksum(scionL)
+
+	// Prepare input packet
+	input := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(input, options,
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	// Prepare want packet
+	want := gopacket.NewSerializeBuffer()
+	// Ethernet: SrcMAC=f0:0d:ca:fe:00:14 DstMAC=f0:0d:ca:fe:be:ef
+	ethernet.SrcMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x14}
+	ethernet.DstMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef}
+	// 	IP4: Src=192.168.14.2 Dst=192.168.14.3 Checksum=0
+	ip.SrcIP = net.IP{192, 168, 14, 2}
+	ip.DstIP = net.IP{192, 168, 14, 3}
+	// 	UDP: Src=50000 Dst=40000
+	udp.SrcPort, udp.DstPort = udp.DstPort, udp.SrcPort
+	// 	SCION: CurrHopF=7
+	if err := sp.IncPath(); err != nil {
+		panic(err)
+	}
+	sp.InfoFields[0].UpdateSegID(sp.HopFields[1].Mac)
+	setEPICPath(ep, sp)
+
+	if err := gopacket.SerializeLayers(want, options,
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+	return input.Bytes(), want.Bytes()
+}
+
+// ChildToParentEPIC tests transit traffic with the EPIC path type over the
+// same BR host against the construction direction. The BR is at the
+// penultimate hop of the path and verifies the PHVF.
+func ChildToParentEPIC(artifactsDir string, mac hash.Hash) runner.Case {
+	c := runner.Case{
+		Name:     "ChildToParentEPIC",
+		WriteTo:  "veth_141_host",
+		ReadFrom: "veth_131_host",
+		StoreDir: filepath.Join(artifactsDir, "ChildToParentEPIC"),
+		Prepare: func(c *runner.Case) {
+			c.Input, c.Want = childToParentEPIC(mac)
+		},
+	}
+	c.Prepare(&c)
+	return c
+}
+
+func childToParentEPIC(mac hash.Hash) ([]byte, []byte) {
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+
+	// Ethernet: SrcMAC=f0:0d:ca:fe:be:ef DstMAC=f0:0d:ca:fe:00:14 EthernetType=IPv4
+	ethernet := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
+		DstMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x14},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	// IP4: Src=192.168.14.3 Dst=192.168.14.2 NextHdr=UDP Flags=DF
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    net.IP{192, 168, 14, 3},
+		DstIP:    net.IP{192, 168, 14, 2},
+		Protocol: layers.IPProtocolUDP,
+		Flags:    layers.IPv4DontFragment,
+	}
+	// 	UDP: Src=40000 Dst=50000
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(40000),
+		DstPort: layers.UDPPort(50000),
+	}
+	_ = udp.SetNetworkLayerForChecksum(ip)
+
+	// pkt0.ParsePacket(`
+	// 	SCION: NextHdr=UDP CurrInfoF=4 CurrHopF=6 SrcType=IPv4 DstType=IPv4 PathType=EPIC
+	// 		ADDR: SrcIA=1-ff00:0:4 Src=174.16.4.1 DstIA=1-ff00:0:3 Dst=172.16.3.1
+	// 		IF_1: ISD=1 Hops=3
+	// 			HF_1: ConsIngress=411 ConsEgress=0
+	// 			HF_2: ConsIngress=131 ConsEgress=141
+	// 			HF_3: ConsIngress=0 ConsEgress=311
+	// 	UDP_1: Src=40111 Dst=40222
+	// `)
+	now := time.Now()
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: 1,
+				SegLen: [3]uint8{3, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 3,
+		},
+		InfoFields: []path.InfoField{
+			{
+				SegID:     0x111,
+				Timestamp: util.TimeToSecs(now),
+			},
+		},
+		HopFields: []path.HopField{
+			{ConsIngress: 411, ConsEgress: 0},
+			{ConsIngress: 131, ConsEgress: 141},
+			{ConsIngress: 0, ConsEgress: 311},
+		},
+	}
+	sp.HopFields[1].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[1], nil)
+	// The BR verifies the MAC with the SegID it computes on ingress, so the
+	// authenticator is derived before the SegID is updated.
+	auth := path.FullMAC(mac, sp.InfoFields[0], sp.HopFields[1], nil)
+	sp.InfoFields[0].UpdateSegID(sp.HopFields[1].Mac)
+
+	ep := newEPICPath(sp, now)
+
+	payload := []byte("actualpayloadbytes")
+	scionL := &slayers.SCION{
+		Version:      0,
+		TrafficClass: 0xb8,
+		FlowID:       0xdead,
+		NextHdr:      slayers.L4UDP,
+		PathType:     epic.PathType,
+		SrcIA:        addr.MustParseIA("1-ff00:0:4"),
+		DstIA:        addr.MustParseIA("1-ff00:0:3"),
+		PayloadLen:   uint16(8 + len(payload)),
+		Path:         ep,
+	}
+	if err := scionL.SetSrcAddr(addr.MustParseHost("172.16.4.1")); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetDstAddr(addr.MustParseHost("174.16.3.1")); err != nil {
+		panic(err)
+	}
+	setPHVF(scionL, ep, auth, sp.InfoFields[0].Timestamp)
+
+	scionudp := &slayers.UDP{}
+	scionudp.SrcPort = 40111
+	scionudp.DstPort = 40222
+	scionudp.SetNetworkLayerForChecksum(scionL)
+
+	// Prepare input packet
+	input := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(input, options,
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	// Prepare want packet
+	want := gopacket.NewSerializeBuffer()
+	// Ethernet: SrcMAC=f0:0d:ca:fe:00:13 DstMAC=f0:0d:ca:fe:be:ef
+	ethernet.SrcMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x13}
+	ethernet.DstMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef}
+	// IP4: Src=192.168.13.2 Dst=192.168.13.3 Checksum=0
+	ip.SrcIP = net.IP{192, 168, 13, 2}
+	ip.DstIP = net.IP{192, 168, 13, 3}
+	// 	UDP: Src=50000 Dst=40000
+	udp.SrcPort, udp.DstPort = udp.DstPort, udp.SrcPort
+	// 	SCION: CurrHopF=7
+	if err := sp.IncPath(); err != nil {
+		panic(err)
+	}
+	sp.InfoFields[0].UpdateSegID(sp.HopFields[1].Mac)
+	setEPICPath(ep, sp)
+
+	if err := gopacket.SerializeLayers(want, options,
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+	return input.Bytes(), want.Bytes()
+}
+
+// newEPICPath returns an EPIC path that wraps the SCION path sp. The packet
+// timestamp is set to now.
+func newEPICPath(sp *scion.Decoded, now time.Time) *epic.Path {
+	epicTS, err := libepic.CreateTimestamp(
+		time.Unix(int64(sp.InfoFields[0].Timestamp), 0), now)
+	if err != nil {
+		panic(err)
+	}
+	ep := &epic.Path{
+		PktID: epic.PktID{
+			Timestamp: epicTS,
+			Counter:   libepic.PktCounterFromCore(1, 2),
+		},
+		PHVF: make([]byte, epic.HVFLen),
+		LHVF: make([]byte, epic.HVFLen),
+	}
+	setEPICPath(ep, sp)
+	return ep
+}
+
+// setEPICPath sets the SCION path of the EPIC path to sp.
+func setEPICPath(ep *epic.Path, sp *scion.Decoded) {
+	raw, err := sp.ToRaw()
+	if err != nil {
+		panic(err)
+	}
+	ep.ScionPath = raw
+}
+
+// setPHVF sets the PHVF of the EPIC path of the SCION header, computed with
+// the authenticator auth of the penultimate hop. The SCION header must be
+// complete, including the payload length, which is part of the HVF input. The
+// LHVF is not verified by the BR and is left zero.
+func setPHVF(scionL *slayers.SCION, ep *epic.Path, auth []byte, timestamp uint32) {
+	phvf, err := libepic.CalcMac(auth, ep.PktID, scionL, timestamp, nil)
+	if err != nil {
+		panic(err)
+	}
+	copy(ep.PHVF, phvf)
+}
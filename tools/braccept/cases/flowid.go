@@ -0,0 +1,36 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cases
+
+import (
+	"fmt"
+	"hash"
+
+	"github.com/scionproto/scion/tools/braccept/runner"
+)
+
+// ParentToChildFlowIDs tests that the egress interface of transit traffic only
+// depends on the path and not on the FlowID. The router does not balance flows
+// over parallel links, the egress interface is dictated by the current hop
+// field. Thus, a packet with any FlowID must leave on the child interface 141,
+// and the FlowID must be forwarded unmodified.
+func ParentToChildFlowIDs(artifactsDir string, mac hash.Hash) []runner.Case {
+	var cases []runner.Case
+	for _, flowID := range []uint32{0x1, 0xdead, 0xfffff} {
+		name := fmt.Sprintf("ParentToChildFlowID%#x", flowID)
+		cases = append(cases, parentToChild(artifactsDir, mac, name, flowID))
+	}
+	return cases
+}
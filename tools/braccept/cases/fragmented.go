@@ -0,0 +1,220 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cases
+
+import (
+	"hash"
+	"net"
+	"path/filepath"
+	"time"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+
+	"github.com/scionproto/scion/pkg/addr"
+	"github.com/scionproto/scion/pkg/private/util"
+	"github.com/scionproto/scion/pkg/slayers"
+	"github.com/scionproto/scion/pkg/slayers/path"
+	"github.com/scionproto/scion/pkg/slayers/path/scion"
+	"github.com/scionproto/scion/tools/braccept/runner"
+)
+
+// fragmentSize is the size of the IP payload of each fragment. It is the
+// smallest size allowed by IPv4, so that the SCION packet is split into as
+// many fragments as possible.
+const fragmentSize = 8
+
+// UnderlayFragmented tests transit traffic whose SCION packet is fragmented in
+// the underlay IP layer. The fragments must be reassembled before the SCION
+// header is processed, and the packet is forwarded as a whole.
+func UnderlayFragmented(artifactsDir string, mac hash.Hash) runner.Case {
+	input, frags, want := fragmentedParentToChild(mac)
+	return runner.Case{
+		Name:           "UnderlayFragmented",
+		WriteTo:        "veth_131_host",
+		ReadFrom:       "veth_141_host",
+		Input:          input,
+		InputFragments: frags,
+		Want:           want,
+		StoreDir:       filepath.Join(artifactsDir, "UnderlayFragmented"),
+	}
+}
+
+// UnderlayFragmentMissing tests that a SCION packet that is fragmented in the
+// underlay IP layer is dropped if one of the fragments is missing. The first
+// fragment is omitted, otherwise the expiry of the reassembly queue would
+// trigger an ICMP time exceeded message that could interfere with later
+// cases.
+func UnderlayFragmentMissing(artifactsDir string, mac hash.Hash) runner.Case {
+	input, frags, _ := fragmentedParentToChild(mac)
+	return runner.Case{
+		Name:           "UnderlayFragmentMissing",
+		WriteTo:        "veth_131_host",
+		ReadFrom:       "veth_141_host",
+		Input:          input,
+		InputFragments: frags[1:],
+		Want:           nil,
+		StoreDir:       filepath.Join(artifactsDir, "UnderlayFragmentMissing"),
+	}
+}
+
+// fragmentedParentToChild returns a packet from the parent to the child
+// interface, the underlay fragments of that packet, and the packet that is
+// expected to be forwarded.
+func fragmentedParentToChild(mac hash.Hash) ([]byte, [][]byte, []byte) {
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+
+	// Ethernet: SrcMAC=f0:0d:ca:fe:be:ef DstMAC=f0:0d:ca:fe:00:13 EthernetType=IPv4
+	ethernet := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
+		DstMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x13},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	// IP4: Src=192.168.13.3 Dst=192.168.13.2 NextHdr=UDP Id=0x4242
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		Id:       0x4242,
+		SrcIP:    net.IP{192, 168, 13, 3},
+		DstIP:    net.IP{192, 168, 13, 2},
+		Protocol: layers.IPProtocolUDP,
+	}
+	// UDP: Src=40000 Dst=50000
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(40000),
+		DstPort: layers.UDPPort(50000),
+	}
+	_ = udp.SetNetworkLayerForChecksum(ip)
+
+	// pkt0.ParsePacket(`
+	//	SCION: NextHdr=UDP CurrInfoF=4 CurrHopF=6 SrcType=IPv4 DstType=IPv4
+	//		ADDR: SrcIA=1-ff00:0:3 Src=174.16.3.1 DstIA=1-ff00:0:4 Dst=174.16.4.1
+	//		IF_1: ISD=1 Hops=3 Flags=ConsDir
+	//			HF_1: ConsIngress=0 ConsEgress=311
+	//			HF_2: ConsIngress=131 ConsEgress=141
+	//			HF_3: ConsIngress=411 ConsEgress=0
+	//	UDP_1: Src=40111 Dst=40222
+	// `)
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: 1,
+				SegLen: [3]uint8{3, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 3,
+		},
+		InfoFields: []path.InfoField{
+			{
+				SegID:     0x111,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []path.HopField{
+			{ConsIngress: 0, ConsEgress: 311},
+			{ConsIngress: 131, ConsEgress: 141},
+			{ConsIngress: 411, ConsEgress: 0},
+		},
+	}
+	sp.HopFields[1].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[1], nil)
+
+	scionL := &slayers.SCION{
+		Version:      0,
+		TrafficClass: 0xb8,
+		FlowID:       0xdead,
+		NextHdr:      slayers.L4UDP,
+		PathType:     scion.PathType,
+		SrcIA:        addr.MustParseIA("1-ff00:0:3"),
+		DstIA:        addr.MustParseIA("1-ff00:0:4"),
+		Path:         sp,
+	}
+	if err := scionL.SetSrcAddr(addr.MustParseHost("172.16.3.1")); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetDstAddr(addr.MustParseHost("174.16.4.1")); err != nil {
+		panic(err)
+	}
+
+	scionudp := &slayers.UDP{}
+	scionudp.SrcPort = 40111
+	scionudp.DstPort = 40222
+	scionudp.SetNetworkLayerForChecksum(scionL)
+
+	payload := []byte("actualpayloadbytes")
+
+	// Prepare input packet
+	input := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(input, options,
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	// Split the IP payload of the input packet into fragments.
+	ipPayload := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(ipPayload, options,
+		udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+	var frags [][]byte
+	for b, off := ipPayload.Bytes(), 0; off < len(b); off += fragmentSize {
+		fragIP := *ip
+		fragIP.FragOffset = uint16(off / 8)
+		end := off + fragmentSize
+		if end < len(b) {
+			fragIP.Flags = layers.IPv4MoreFragments
+		} else {
+			end = len(b)
+		}
+		frag := gopacket.NewSerializeBuffer()
+		if err := gopacket.SerializeLayers(frag, options,
+			ethernet, &fragIP, gopacket.Payload(b[off:end]),
+		); err != nil {
+			panic(err)
+		}
+		frags = append(frags, frag.Bytes())
+	}
+
+	// Prepare want packet
+	want := gopacket.NewSerializeBuffer()
+	// Ethernet: SrcMAC=f0:0d:ca:fe:00:14 DstMAC=f0:0d:ca:fe:be:ef
+	ethernet.SrcMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x14}
+	ethernet.DstMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef}
+	// 	IP4: Src=192.168.14.2 Dst=192.168.14.3 Flags=DF
+	ip.SrcIP = net.IP{192, 168, 14, 2}
+	ip.DstIP = net.IP{192, 168, 14, 3}
+	ip.Id = 0
+	ip.Flags = layers.IPv4DontFragment
+	// 	UDP: Src=50000 Dst=40000
+	udp.SrcPort, udp.DstPort = udp.DstPort, udp.SrcPort
+	// 	SCION: CurrHopF=7
+	if err := sp.IncPath(); err != nil {
+		panic(err)
+	}
+	sp.InfoFields[0].UpdateSegID(sp.HopFields[1].Mac)
+
+	if err := gopacket.SerializeLayers(want, options,
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+	return input.Bytes(), frags, want.Bytes()
+}
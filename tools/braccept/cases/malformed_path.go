@@ -142,12 +142,13 @@ func MalformedPathSingletonSegment(artifactsDir string, mac hash.Hash) runner.Ca
 	}
 
 	return runner.Case{
-		Name:     "MalformedPathSingletonSegment",
-		WriteTo:  "veth_151_host",
-		ReadFrom: "no_pkt_expected",
-		Input:    input.Bytes(),
-		Want:     nil,
-		StoreDir: filepath.Join(artifactsDir, "MalformedPathSingletonSegment"),
+		Name:                "MalformedPathSingletonSegment",
+		WriteTo:             "veth_151_host",
+		ReadFrom:            "no_pkt_expected",
+		Input:               input.Bytes(),
+		Want:                nil,
+		StoreDir:            filepath.Join(artifactsDir, "MalformedPathSingletonSegment"),
+		InputMayBeMalformed: true,
 	}
 }
 
@@ -249,11 +250,12 @@ func MalformedPathCurrHFNotInCurrINF(artifactsDir string, mac hash.Hash) runner.
 	}
 
 	return runner.Case{
-		Name:     "MalformedPathCurrHFNotInCurrINF",
-		WriteTo:  "veth_131_host",
-		ReadFrom: "no_pkt_expected",
-		Input:    input.Bytes(),
-		Want:     nil,
-		StoreDir: filepath.Join(artifactsDir, "MalformedPathCurrHFNotInCurrINF"),
+		Name:                "MalformedPathCurrHFNotInCurrINF",
+		WriteTo:             "veth_131_host",
+		ReadFrom:            "no_pkt_expected",
+		Input:               input.Bytes(),
+		Want:                nil,
+		StoreDir:            filepath.Join(artifactsDir, "MalformedPathCurrHFNotInCurrINF"),
+		InputMayBeMalformed: true,
 	}
 }
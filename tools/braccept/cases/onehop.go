@@ -223,3 +223,26 @@ func OutgoingOneHop(artifactsDir string, mac hash.Hash) runner.Case {
 		StoreDir: filepath.Join(artifactsDir, "OutgoingOneHop"),
 	}
 }
+
+// newOneHopPath creates a one-hop path with the first hop field set and its
+// MAC computed with mac. The timestamp, the segment ID and the second hop field
+// are zero.
+func newOneHopPath(
+	mac hash.Hash,
+	consDir bool,
+	ingress, egress uint16,
+	expTime uint8,
+) *onehop.Path {
+	ohp := &onehop.Path{
+		Info: path.InfoField{
+			ConsDir: consDir,
+		},
+		FirstHop: path.HopField{
+			ExpTime:     expTime,
+			ConsIngress: ingress,
+			ConsEgress:  egress,
+		},
+	}
+	ohp.FirstHop.Mac = path.MAC(mac, ohp.Info, ohp.FirstHop, nil)
+	return ohp
+}
@@ -0,0 +1,44 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cases
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/pkg/scrypto"
+	"github.com/scionproto/scion/pkg/slayers/path"
+)
+
+func TestNewOneHopPath(t *testing.T) {
+	mac, err := scrypto.InitMac([]byte("testkey_xxxxxxxx"))
+	require.NoError(t, err)
+
+	ohp := newOneHopPath(mac, true, 0, 131, 63)
+	assert.Equal(t, path.InfoField{ConsDir: true}, ohp.Info)
+	assert.Equal(t, uint8(63), ohp.FirstHop.ExpTime)
+	assert.Equal(t, uint16(0), ohp.FirstHop.ConsIngress)
+	assert.Equal(t, uint16(131), ohp.FirstHop.ConsEgress)
+	assert.Equal(t, path.HopField{}, ohp.SecondHop)
+
+	want := path.MAC(mac, ohp.Info, path.HopField{
+		ExpTime:    63,
+		ConsEgress: 131,
+	}, nil)
+	assert.Equal(t, want, ohp.FirstHop.Mac)
+	assert.NotEqual(t, [path.MacLen]byte{}, ohp.FirstHop.Mac)
+}
@@ -15,6 +15,7 @@
 package cases
 
 import (
+	"fmt"
 	"hash"
 	"net"
 	"path/filepath"
@@ -33,6 +34,11 @@ import (
 
 // ParentToChild tests transit traffic over the same BR host.
 func ParentToChild(artifactsDir string, mac hash.Hash) runner.Case {
+	return parentToChild(artifactsDir, mac, "ParentToChild", 0xdead)
+}
+
+// parentToChild builds the ParentToChild case with the given name and FlowID.
+func parentToChild(artifactsDir string, mac hash.Hash, name string, flowID uint32) runner.Case {
 	options := gopacket.SerializeOptions{
 		FixLengths:       true,
 		ComputeChecksums: true,
@@ -97,7 +103,7 @@ func ParentToChild(artifactsDir string, mac hash.Hash) runner.Case {
 	scionL := &slayers.SCION{
 		Version:      0,
 		TrafficClass: 0xb8,
-		FlowID:       0xdead,
+		FlowID:       flowID,
 		NextHdr:      slayers.L4UDP,
 		PathType:     scion.PathType,
 		SrcIA:        addr.MustParseIA("1-ff00:0:3"),
@@ -149,11 +155,420 @@ func ParentToChild(artifactsDir string, mac hash.Hash) runner.Case {
 	}
 
 	return runner.Case{
-		Name:     "ParentToChild",
+		Name:     name,
+		WriteTo:  "veth_131_host",
+		ReadFrom: "veth_141_host",
+		Input:    input.Bytes(),
+		Want:     want.Bytes(),
+		StoreDir: filepath.Join(artifactsDir, name),
+	}
+}
+
+// ParentToChildEgressCheck tests that transit traffic that enters on the
+// parent interface leaves on the child interface. The input is written to the
+// parent veth and the forwarded packet is read from the child veth, so both
+// must be provisioned. Packets captured on the parent veth fail the case. The
+// expected packet carries the path with the current hop field advanced, which
+// asserts that the router processed the hop.
+func ParentToChildEgressCheck(artifactsDir string, mac hash.Hash) runner.Case {
+	c := ParentToChild(artifactsDir, mac)
+	in, want := currHF(c.Input), currHF(c.Want)
+	if c.WriteTo == c.ReadFrom || want != in+1 {
+		panic(fmt.Sprintf("case does not forward over a hop: write_to=%s read_from=%s "+
+			"in_curr_hf=%d want_curr_hf=%d", c.WriteTo, c.ReadFrom, in, want))
+	}
+	c.Name = "ParentToChildEgressCheck"
+	c.StoreDir = filepath.Join(artifactsDir, "ParentToChildEgressCheck")
+	return c
+}
+
+// currHF returns the index of the current hop field of the SCION path in the
+// raw packet.
+func currHF(raw []byte) uint8 {
+	pkt := gopacket.NewPacket(raw, layers.LinkTypeEthernet, gopacket.Default)
+	scionL, ok := pkt.Layer(slayers.LayerTypeSCION).(*slayers.SCION)
+	if !ok {
+		panic("packet without SCION layer")
+	}
+	sp, ok := scionL.Path.(*scion.Raw)
+	if !ok {
+		panic(fmt.Sprintf("unexpected path type: %T", scionL.Path))
+	}
+	return sp.PathMeta.CurrHF
+}
+
+// ParentToChildRawPath tests transit traffic over the same BR host, where the
+// input packet is built from the raw representation of the SCION path. The
+// forwarded packet must be identical to the one built from the decoded path,
+// i.e., the router must be agnostic to the representation used by the sender.
+func ParentToChildRawPath(artifactsDir string, mac hash.Hash) runner.Case {
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+
+	// Ethernet: SrcMAC=f0:0d:ca:fe:be:ef DstMAC=f0:0d:ca:fe:00:13 EthernetType=IPv4
+	ethernet := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
+		DstMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x13},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	// IP4: Src=192.168.13.3 Dst=192.168.13.2 NextHdr=UDP Flags=DF
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    net.IP{192, 168, 13, 3},
+		DstIP:    net.IP{192, 168, 13, 2},
+		Protocol: layers.IPProtocolUDP,
+		Flags:    layers.IPv4DontFragment,
+	}
+	// UDP: Src=40000 Dst=50000
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(40000),
+		DstPort: layers.UDPPort(50000),
+	}
+	_ = udp.SetNetworkLayerForChecksum(ip)
+
+	// pkt0.ParsePacket(`
+	//	SCION: NextHdr=UDP CurrInfoF=4 CurrHopF=6 SrcType=IPv4 DstType=IPv4
+	//		ADDR: SrcIA=1-ff00:0:3 Src=174.16.3.1 DstIA=1-ff00:0:4 Dst=174.16.4.1
+	//		IF_1: ISD=1 Hops=3 Flags=ConsDir
+	//			HF_1: ConsIngress=0 ConsEgress=311
+	//			HF_2: ConsIngress=131 ConsEgress=141
+	//			HF_3: ConsIngress=411 ConsEgress=0
+	//	UDP_1: Src=40111 Dst=40222
+	// `)
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: 1,
+				SegLen: [3]uint8{3, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 3,
+		},
+		InfoFields: []path.InfoField{
+			{
+				SegID:     0x111,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []path.HopField{
+			{ConsIngress: 0, ConsEgress: 311},
+			{ConsIngress: 131, ConsEgress: 141},
+			{ConsIngress: 411, ConsEgress: 0},
+		},
+	}
+	sp.HopFields[1].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[1], nil)
+	rp, err := sp.ToRaw()
+	if err != nil {
+		panic(err)
+	}
+
+	scionL := &slayers.SCION{
+		Version:      0,
+		TrafficClass: 0xb8,
+		FlowID:       0xdead,
+		NextHdr:      slayers.L4UDP,
+		PathType:     scion.PathType,
+		SrcIA:        addr.MustParseIA("1-ff00:0:3"),
+		DstIA:        addr.MustParseIA("1-ff00:0:4"),
+		Path:         rp,
+	}
+	if err := scionL.SetSrcAddr(addr.MustParseHost("172.16.3.1")); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetDstAddr(addr.MustParseHost("174.16.4.1")); err != nil {
+		panic(err)
+	}
+
+	scionudp := &slayers.UDP{}
+	scionudp.SrcPort = 40111
+	scionudp.DstPort = 40222
+	scionudp.SetNetworkLayerForChecksum(scionL)
+
+	payload := []byte("actualpayloadbytes")
+
+	// Prepare input packet
+	input := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(input, options,
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	// Prepare want packet from the decoded path.
+	want := gopacket.NewSerializeBuffer()
+	// Ethernet: SrcMAC=f0:0d:ca:fe:00:14 DstMAC=f0:0d:ca:fe:be:ef
+	ethernet.SrcMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x14}
+	ethernet.DstMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef}
+	// 	IP4: Src=192.168.14.2 Dst=192.168.14.3 Checksum=0
+	ip.SrcIP = net.IP{192, 168, 14, 2}
+	ip.DstIP = net.IP{192, 168, 14, 3}
+	// 	UDP: Src=50000 Dst=40000
+	udp.SrcPort, udp.DstPort = udp.DstPort, udp.SrcPort
+	// 	SCION: CurrHopF=7
+	if err := sp.IncPath(); err != nil {
+		panic(err)
+	}
+	sp.InfoFields[0].UpdateSegID(sp.HopFields[1].Mac)
+	scionL.Path = sp
+
+	if err := gopacket.SerializeLayers(want, options,
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	return runner.Case{
+		Name:     "ParentToChildRawPath",
+		WriteTo:  "veth_131_host",
+		ReadFrom: "veth_141_host",
+		Input:    input.Bytes(),
+		Want:     want.Bytes(),
+		StoreDir: filepath.Join(artifactsDir, "ParentToChildRawPath"),
+	}
+}
+
+// ParentToChildUnknownNextHdr tests transit traffic over the same BR host,
+// where the SCION header announces an upper-layer protocol that is unknown to
+// the router. The router does not process the upper layer of transit traffic,
+// so the packet must be forwarded with the payload as is.
+func ParentToChildUnknownNextHdr(artifactsDir string, mac hash.Hash) runner.Case {
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+
+	ethernet := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
+		DstMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x13},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    net.IP{192, 168, 13, 3},
+		DstIP:    net.IP{192, 168, 13, 2},
+		Protocol: layers.IPProtocolUDP,
+		Flags:    layers.IPv4DontFragment,
+	}
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(40000),
+		DstPort: layers.UDPPort(50000),
+	}
+	_ = udp.SetNetworkLayerForChecksum(ip)
+
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: 1,
+				SegLen: [3]uint8{3, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 3,
+		},
+		InfoFields: []path.InfoField{
+			{
+				SegID:     0x111,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []path.HopField{
+			{ConsIngress: 0, ConsEgress: 311},
+			{ConsIngress: 131, ConsEgress: 141},
+			{ConsIngress: 411, ConsEgress: 0},
+		},
+	}
+	sp.HopFields[1].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[1], nil)
+
+	scionL := &slayers.SCION{
+		Version:      0,
+		TrafficClass: 0xb8,
+		FlowID:       0xdead,
+		// 253 is reserved for experimentation and is not assigned to any
+		// protocol known to the router.
+		NextHdr:  slayers.L4ProtocolType(253),
+		PathType: scion.PathType,
+		SrcIA:    addr.MustParseIA("1-ff00:0:3"),
+		DstIA:    addr.MustParseIA("1-ff00:0:4"),
+		Path:     sp,
+	}
+	if err := scionL.SetSrcAddr(addr.MustParseHost("172.16.3.1")); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetDstAddr(addr.MustParseHost("174.16.4.1")); err != nil {
+		panic(err)
+	}
+
+	payload := []byte("opaqueupperlayerpayload")
+
+	// Prepare input packet
+	input := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(input, options,
+		ethernet, ip, udp, scionL, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	// Prepare want packet
+	want := gopacket.NewSerializeBuffer()
+	ethernet.SrcMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x14}
+	ethernet.DstMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef}
+	ip.SrcIP = net.IP{192, 168, 14, 2}
+	ip.DstIP = net.IP{192, 168, 14, 3}
+	udp.SrcPort, udp.DstPort = udp.DstPort, udp.SrcPort
+	if err := sp.IncPath(); err != nil {
+		panic(err)
+	}
+	sp.InfoFields[0].UpdateSegID(sp.HopFields[1].Mac)
+
+	if err := gopacket.SerializeLayers(want, options,
+		ethernet, ip, udp, scionL, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	return runner.Case{
+		Name:     "ParentToChildUnknownNextHdr",
+		WriteTo:  "veth_131_host",
+		ReadFrom: "veth_141_host",
+		Input:    input.Bytes(),
+		Want:     want.Bytes(),
+		StoreDir: filepath.Join(artifactsDir, "ParentToChildUnknownNextHdr"),
+	}
+}
+
+// ParentToChildHBHOptions tests transit traffic over the same BR host, where
+// the packet carries a Hop-by-Hop extension with padding and options that are
+// unknown to the router. The router skips the Hop-by-Hop extension of transit
+// traffic, so the packet must be forwarded with the options in their original
+// order.
+func ParentToChildHBHOptions(artifactsDir string, mac hash.Hash) runner.Case {
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+
+	ethernet := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
+		DstMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x13},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    net.IP{192, 168, 13, 3},
+		DstIP:    net.IP{192, 168, 13, 2},
+		Protocol: layers.IPProtocolUDP,
+		Flags:    layers.IPv4DontFragment,
+	}
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(40000),
+		DstPort: layers.UDPPort(50000),
+	}
+	_ = udp.SetNetworkLayerForChecksum(ip)
+
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: 1,
+				SegLen: [3]uint8{3, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 3,
+		},
+		InfoFields: []path.InfoField{
+			{
+				SegID:     0x111,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []path.HopField{
+			{ConsIngress: 0, ConsEgress: 311},
+			{ConsIngress: 131, ConsEgress: 141},
+			{ConsIngress: 411, ConsEgress: 0},
+		},
+	}
+	sp.HopFields[1].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[1], nil)
+
+	scionL := &slayers.SCION{
+		Version:      0,
+		TrafficClass: 0xb8,
+		FlowID:       0xdead,
+		NextHdr:      slayers.HopByHopClass,
+		PathType:     scion.PathType,
+		SrcIA:        addr.MustParseIA("1-ff00:0:3"),
+		DstIA:        addr.MustParseIA("1-ff00:0:4"),
+		Path:         sp,
+	}
+	if err := scionL.SetSrcAddr(addr.MustParseHost("172.16.3.1")); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetDstAddr(addr.MustParseHost("174.16.4.1")); err != nil {
+		panic(err)
+	}
+
+	hbh := &slayers.HopByHopExtn{}
+	hbh.NextHdr = slayers.L4UDP
+	hbh.Options = []*slayers.HopByHopOption{
+		{OptType: slayers.OptTypePad1},
+		{OptType: slayers.OptTypePadN, OptData: []byte{0x00, 0x00}},
+		// Option types 0x42 and 0x43 are not assigned and thus unknown to
+		// the router.
+		{OptType: 0x42, OptData: []byte{0xaa, 0xbb, 0xcc, 0xdd}},
+		{
+			OptType:  0x43,
+			OptData:  []byte{0x11, 0x22, 0x33, 0x44},
+			OptAlign: [2]uint8{4, 2},
+		},
+	}
+
+	scionudp := &slayers.UDP{}
+	scionudp.SrcPort = 40111
+	scionudp.DstPort = 40222
+	scionudp.SetNetworkLayerForChecksum(scionL)
+
+	payload := []byte("actualpayloadbytes")
+
+	// Prepare input packet
+	input := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(input, options,
+		ethernet, ip, udp, scionL, hbh, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	// Prepare want packet
+	want := gopacket.NewSerializeBuffer()
+	ethernet.SrcMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x14}
+	ethernet.DstMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef}
+	ip.SrcIP = net.IP{192, 168, 14, 2}
+	ip.DstIP = net.IP{192, 168, 14, 3}
+	udp.SrcPort, udp.DstPort = udp.DstPort, udp.SrcPort
+	if err := sp.IncPath(); err != nil {
+		panic(err)
+	}
+	sp.InfoFields[0].UpdateSegID(sp.HopFields[1].Mac)
+
+	if err := gopacket.SerializeLayers(want, options,
+		ethernet, ip, udp, scionL, hbh, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	return runner.Case{
+		Name:     "ParentToChildHBHOptions",
 		WriteTo:  "veth_131_host",
 		ReadFrom: "veth_141_host",
 		Input:    input.Bytes(),
 		Want:     want.Bytes(),
-		StoreDir: filepath.Join(artifactsDir, "ParentToChild"),
+		StoreDir: filepath.Join(artifactsDir, "ParentToChildHBHOptions"),
 	}
 }
@@ -15,6 +15,7 @@
 package cases
 
 import (
+	"bytes"
 	"hash"
 	"net"
 	"path/filepath"
@@ -29,6 +30,7 @@ import (
 	"github.com/scionproto/scion/pkg/slayers"
 	"github.com/scionproto/scion/pkg/slayers/path"
 	"github.com/scionproto/scion/pkg/slayers/path/scion"
+	"github.com/scionproto/scion/pkg/spao"
 	"github.com/scionproto/scion/tools/braccept/runner"
 )
 
@@ -191,3 +193,350 @@ func PeerToChild(artifactsDir string, mac hash.Hash) runner.Case {
 		StoreDir: filepath.Join(artifactsDir, "ChildToChildXover"),
 	}
 }
+
+// PeerToChildWithSPAO tests transit traffic over a peering hop that carries an
+// SCION Packet Authenticator Option (SPAO) in an end-to-end extension. The path
+// is the same as in PeerToChild. The router must forward the extension
+// untouched, and since the authenticator excludes the path fields that routers
+// update, it remains valid for the destination. The option uses a non-DRKey
+// SPI, so the authenticator covers the ISD-ASes and the host addresses, too.
+func PeerToChildWithSPAO(artifactsDir string, mac hash.Hash) runner.Case {
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+
+	// We inject the packet into A (at IF 121) as if coming from 2 (at IF 211)
+	ethernet := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef}, // IF 211
+		DstMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x12}, // IF 121
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+
+	ip := &layers.IPv4{ // On the 2->A link
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    net.IP{192, 168, 12, 3}, // from 2's 211 IP
+		DstIP:    net.IP{192, 168, 12, 2}, // to A's 121 IP
+		Protocol: layers.IPProtocolUDP,
+		Flags:    layers.IPv4DontFragment,
+	}
+
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(40000),
+		DstPort: layers.UDPPort(50000),
+	}
+	_ = udp.SetNetworkLayerForChecksum(ip)
+
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF:  1,
+				CurrINF: 1,
+				SegLen:  [3]uint8{1, 2, 0},
+			},
+			NumINF:  2,
+			NumHops: 3,
+		},
+		InfoFields: []path.InfoField{
+			// up seg
+			{
+				SegID:     0x111,
+				ConsDir:   false,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+			// down seg
+			{
+				SegID:     0x222,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+				Peer:      true,
+			},
+		},
+		HopFields: []path.HopField{
+			{ConsIngress: 211, ConsEgress: 0},   // at 2 out to A
+			{ConsIngress: 121, ConsEgress: 151}, // at A in from 2 out to 5
+			{ConsIngress: 511, ConsEgress: 0},   // at 5 in from A
+		},
+	}
+
+	// Only HF[1] is verified by A. The others are signed with arbitrary keys.
+	macGenX, err := scrypto.InitMac([]byte("1234567812345678"))
+	if err != nil {
+		panic(err)
+	}
+	macGenY, err := scrypto.InitMac([]byte("abcdefghabcdefgh"))
+	if err != nil {
+		panic(err)
+	}
+	sp.HopFields[0].Mac = path.MAC(macGenX, sp.InfoFields[0], sp.HopFields[0], nil)
+	// HF[1] is a peering hop and shares the SegID with HF[2].
+	sp.HopFields[1].Mac = path.MAC(mac, sp.InfoFields[1], sp.HopFields[1], nil)
+	sp.HopFields[2].Mac = path.MAC(macGenY, sp.InfoFields[1], sp.HopFields[2], nil)
+
+	scionL := &slayers.SCION{
+		Version:      0,
+		TrafficClass: 0xb8,
+		FlowID:       0xdead,
+		NextHdr:      slayers.End2EndClass,
+		PathType:     scion.PathType,
+		SrcIA:        addr.MustParseIA("1-ff00:0:2"),
+		DstIA:        addr.MustParseIA("1-ff00:0:5"),
+		Path:         sp,
+	}
+	if err := scionL.SetSrcAddr(addr.MustParseHost("172.16.2.1")); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetDstAddr(addr.MustParseHost("174.16.5.1")); err != nil {
+		panic(err)
+	}
+
+	scionudp := &slayers.UDP{}
+	scionudp.SrcPort = 40111
+	scionudp.DstPort = 40222
+	scionudp.SetNetworkLayerForChecksum(scionL)
+
+	payload := []byte("actualpayloadbytes")
+
+	// The authenticated payload is the upper layer, i.e., the UDP datagram.
+	e2ePayload := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(e2ePayload, options,
+		scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+	optAuth, err := slayers.NewPacketAuthOption(slayers.PacketAuthOptionParams{
+		SPI:         slayers.PacketAuthSPI(1 << 21),
+		Algorithm:   slayers.PacketAuthCMAC,
+		TimestampSN: 0x1234,
+		Auth:        make([]byte, 16),
+	})
+	if err != nil {
+		panic(err)
+	}
+	macInput := spao.MACInput{
+		Key:        []byte("peeringspaokey00"),
+		Header:     optAuth,
+		ScionLayer: scionL,
+		PldType:    slayers.L4UDP,
+		Pld:        e2ePayload.Bytes(),
+	}
+	if _, err := spao.ComputeAuthCMAC(macInput, make([]byte, spao.MACBufferSize),
+		optAuth.Authenticator()); err != nil {
+		panic(err)
+	}
+	e2e := &slayers.EndToEndExtn{
+		Options: []*slayers.EndToEndOption{optAuth.EndToEndOption},
+	}
+	e2e.NextHdr = slayers.L4UDP
+
+	// Prepare input packet
+	input := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(input, options,
+		ethernet, ip, udp, scionL, e2e, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	// Prepare want packet
+	// We expect it out of A's 151 IF on its way to 5's 511 IF.
+	want := gopacket.NewSerializeBuffer()
+	ethernet.SrcMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x15} // IF 151
+	ethernet.DstMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef} // IF 511
+	ip.SrcIP = net.IP{192, 168, 15, 2}                                     // from A's 151 IP
+	ip.DstIP = net.IP{192, 168, 15, 3}                                     // to 5's 511 IP
+	udp.SrcPort, udp.DstPort = udp.DstPort, udp.SrcPort
+	if err := sp.IncPath(); err != nil {
+		panic(err)
+	}
+
+	// The router only updates the path meta header, which is zeroed in the
+	// authenticated data. Hence, the authenticator of the input is still valid
+	// for the forwarded packet, and the option is expected unchanged.
+	forwarded, err := spao.ComputeAuthCMAC(macInput, make([]byte, spao.MACBufferSize), nil)
+	if err != nil {
+		panic(err)
+	}
+	if !bytes.Equal(forwarded, optAuth.Authenticator()) {
+		panic("authenticator changed by forwarding")
+	}
+
+	if err := gopacket.SerializeLayers(want, options,
+		ethernet, ip, udp, scionL, e2e, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	return runner.Case{
+		Name:     "PeerToChildWithSPAO",
+		WriteTo:  "veth_121_host", // Where we inject the test packet
+		ReadFrom: "veth_151_host", // Where we capture the forwarded packet
+		Input:    input.Bytes(),
+		Want:     want.Bytes(),
+		StoreDir: filepath.Join(artifactsDir, "PeerToChildWithSPAO"),
+	}
+}
+
+// PeerToChildMultiHop tests transit traffic that enters via a peering link and
+// continues on a down segment with more than one hop after the peering hop.
+// The peering hop at the router is the first hop of the down segment. It
+// shares the SegID acc value with the next hop in construction direction, so
+// the router must verify the MAC with the SegID as is and must not update it
+// when forwarding. Otherwise, the next AS on the down segment fails to verify
+// its hop field.
+// In this test case, the up segment has two hops, the last of which is the
+// peering hop at the peering link's origin.
+func PeerToChildMultiHop(artifactsDir string, mac hash.Hash) runner.Case {
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+
+	// We inject the packet into A (at IF 121) as if coming from 2 (at IF 211)
+	ethernet := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef}, // IF 211
+		DstMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x12}, // IF 121
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+
+	ip := &layers.IPv4{ // On the 2->A link
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    net.IP{192, 168, 12, 3}, // from 2's 211 IP
+		DstIP:    net.IP{192, 168, 12, 2}, // to A's 121 IP
+		Protocol: layers.IPProtocolUDP,
+		Flags:    layers.IPv4DontFragment,
+	}
+
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(40000),
+		DstPort: layers.UDPPort(50000),
+	}
+	_ = udp.SetNetworkLayerForChecksum(ip)
+
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF:  2,
+				CurrINF: 1,
+				SegLen:  [3]uint8{2, 3, 0},
+			},
+			NumINF:  2,
+			NumHops: 5,
+		},
+		InfoFields: []path.InfoField{
+			// up seg
+			{
+				SegID:     0x111,
+				ConsDir:   false,
+				Timestamp: util.TimeToSecs(time.Now()),
+				Peer:      true,
+			},
+			// down seg
+			{
+				SegID:     0x222,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+				Peer:      true,
+			},
+		},
+		HopFields: []path.HopField{
+			{ConsIngress: 621, ConsEgress: 0},   // at 6 out to 2
+			{ConsIngress: 211, ConsEgress: 261}, // at 2 in from 6 out to A
+			{ConsIngress: 121, ConsEgress: 151}, // at A in from 2 out to 5
+			{ConsIngress: 511, ConsEgress: 571}, // at 5 in from A out to 7
+			{ConsIngress: 751, ConsEgress: 0},   // at 7 in from 5
+		},
+	}
+
+	// Only HF[2] was signed by the AS that we hand the packet to. The others
+	// are signed with different keys and are not checked at that AS.
+	macGenX, err := scrypto.InitMac([]byte("1234567812345678"))
+	if err != nil {
+		panic(err)
+	}
+	macGenY, err := scrypto.InitMac([]byte("abcdefghabcdefgh"))
+	if err != nil {
+		panic(err)
+	}
+
+	// The up segment has already been traversed.
+	sp.HopFields[0].Mac = path.MAC(macGenX, sp.InfoFields[0], sp.HopFields[0], nil)
+	sp.HopFields[1].Mac = path.MAC(macGenX, sp.InfoFields[0], sp.HopFields[1], nil)
+
+	// HF[2] is a peering hop so it has the same SegID acc value as the next one
+	// in construction direction, HF[3]. That is, SEG[1]'s SegID.
+	sp.HopFields[2].Mac = path.MAC(mac, sp.InfoFields[1], sp.HopFields[2], nil)
+	sp.HopFields[3].Mac = path.MAC(macGenY, sp.InfoFields[1], sp.HopFields[3], nil)
+	// HF[4] is verified with the SegID acc value updated by 5.
+	downSeg := sp.InfoFields[1]
+	downSeg.UpdateSegID(sp.HopFields[3].Mac)
+	sp.HopFields[4].Mac = path.MAC(macGenY, downSeg, sp.HopFields[4], nil)
+
+	// The end-to-end trip is from 6,172.16.6.1 to 7,172.16.7.1
+	// That won't change through forwarding.
+	scionL := &slayers.SCION{
+		Version:      0,
+		TrafficClass: 0xb8,
+		FlowID:       0xdead,
+		NextHdr:      slayers.L4UDP,
+		PathType:     scion.PathType,
+		SrcIA:        addr.MustParseIA("1-ff00:0:6"),
+		DstIA:        addr.MustParseIA("1-ff00:0:7"),
+		Path:         sp,
+	}
+	if err := scionL.SetSrcAddr(addr.MustParseHost("172.16.6.1")); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetDstAddr(addr.MustParseHost("174.16.7.1")); err != nil {
+		panic(err)
+	}
+
+	scionudp := &slayers.UDP{}
+	scionudp.SrcPort = 40111
+	scionudp.DstPort = 40222
+	scionudp.SetNetworkLayerForChecksum(scionL)
+
+	payload := []byte("actualpayloadbytes")
+
+	// Prepare input packet
+	input := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(input, options,
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	// Prepare want packet
+	// We expect it out of A's 151 IF on its way to 5's 511 IF.
+
+	want := gopacket.NewSerializeBuffer()
+	ethernet.SrcMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x15} // IF 151
+	ethernet.DstMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef} // IF 511
+	ip.SrcIP = net.IP{192, 168, 15, 2}                                     // from A's 151 IP
+	ip.DstIP = net.IP{192, 168, 15, 3}                                     // to 5's 511 IP
+	udp.SrcPort, udp.DstPort = udp.DstPort, udp.SrcPort
+	if err := sp.IncPath(); err != nil {
+		panic(err)
+	}
+
+	// Out of A, the current hop is HF[3]. The SegID acc value is unchanged,
+	// since HF[2] is a peering hop, and matches the one HF[3] was created with.
+
+	if err := gopacket.SerializeLayers(want, options,
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	return runner.Case{
+		Name:     "PeerToChildMultiHop",
+		WriteTo:  "veth_121_host",
+		ReadFrom: "veth_151_host",
+		Input:    input.Bytes(),
+		Want:     want.Bytes(),
+		StoreDir: filepath.Join(artifactsDir, "PeerToChildMultiHop"),
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cases
+
+import (
+	"hash"
+	"net"
+	"path/filepath"
+	"time"
+
+	"github.com/gopacket/gopacket"
+
+	"github.com/scionproto/scion/pkg/addr"
+	"github.com/scionproto/scion/pkg/private/util"
+	"github.com/scionproto/scion/pkg/slayers"
+	"github.com/scionproto/scion/pkg/slayers/path"
+	"github.com/scionproto/scion/pkg/slayers/path/scion"
+	"github.com/scionproto/scion/tools/braccept/runner"
+)
+
+// SCMPExternalInterfaceDown sends a packet from the parent interface 131 to
+// the child interface 151, whose BFD session is down, and expects an SCMP
+// external interface down error on the ingress interface. The error contains
+// the local ISD-AS and the ID of the interface that is down.
+//
+// The case is run with BFD enabled. The router then only considers an external
+// interface up once the BFD session with the peer is up, and none of the cases
+// set up a session on interface 151.
+func SCMPExternalInterfaceDown(artifactsDir string, mac hash.Hash) runner.Case {
+	return scmpInterfaceDown(artifactsDir, mac, "SCMPExternalInterfaceDown",
+		151, 511, addr.MustParseIA("1-ff00:0:5"), addr.MustParseHost("174.16.5.1"),
+		slayers.SCMPTypeExternalInterfaceDown,
+		&slayers.SCMPExternalInterfaceDown{
+			IA:   addr.MustParseIA("1-ff00:0:1"),
+			IfID: 151,
+		},
+	)
+}
+
+// SCMPInternalConnectivityDown sends a packet from the parent interface 131 to
+// the child interface 181 of the sibling router brC, and expects an SCMP
+// internal connectivity down error on the ingress interface, because the BFD
+// session with the sibling router is down. The error contains the local
+// ISD-AS, the ingress interface and the egress interface.
+//
+// The case is run with BFD enabled. The router then only considers a sibling
+// router reachable once the BFD session with it is up. The internal BFD cases
+// only move the session with brC to the Init state, never to the Up state.
+func SCMPInternalConnectivityDown(artifactsDir string, mac hash.Hash) runner.Case {
+	return scmpInterfaceDown(artifactsDir, mac, "SCMPInternalConnectivityDown",
+		181, 811, addr.MustParseIA("1-ff00:0:8"), addr.MustParseHost("174.16.8.1"),
+		slayers.SCMPTypeInternalConnectivityDown,
+		&slayers.SCMPInternalConnectivityDown{
+			IA:      addr.MustParseIA("1-ff00:0:1"),
+			Ingress: 131,
+			Egress:  181,
+		},
+	)
+}
+
+// scmpInterfaceDown sends a packet from the parent interface 131 to the egress
+// interface, and expects the SCMP error scmpP of type typ on the ingress
+// interface. nextIngress is the ingress interface of the next hop in the
+// destination AS dstIA. The BFD packets that the router sends on interface 131
+// meanwhile are ignored.
+func scmpInterfaceDown(
+	artifactsDir string,
+	mac hash.Hash,
+	name string,
+	egress, nextIngress uint16,
+	dstIA addr.IA,
+	dst addr.Host,
+	typ slayers.SCMPType,
+	scmpP gopacket.SerializableLayer,
+) runner.Case {
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+
+	ethernet, ip, udp := underlay(
+		net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
+		net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x13},
+		net.IP{192, 168, 13, 3},
+		net.IP{192, 168, 13, 2},
+		40000, 50000,
+	)
+
+	// SCION: NextHdr=UDP CurrInfoF=4 CurrHopF=6 SrcType=IPv4 DstType=IPv4
+	// 		ADDR: SrcIA=1-ff00:0:3 Src=172.16.3.1 DstIA=dstIA Dst=dst
+	// 		IF_1: ISD=1 Hops=3 Flags=ConsDir
+	//			HF_1: ConsIngress=0 ConsEgress=311
+	//			HF_2: ConsIngress=131 ConsEgress=egress
+	//			HF_3: ConsIngress=nextIngress ConsEgress=0
+	// UDP_1: Src=40111 Dst=40222
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: 1,
+				SegLen: [3]uint8{3, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 3,
+		},
+		InfoFields: []path.InfoField{
+			{
+				SegID:     0x111,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []path.HopField{
+			{ConsIngress: 0, ConsEgress: 311},
+			{ConsIngress: 131, ConsEgress: egress},
+			{ConsIngress: nextIngress, ConsEgress: 0},
+		},
+	}
+	sp.HopFields[1].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[1], nil)
+
+	scionL := &slayers.SCION{
+		Version:      0,
+		TrafficClass: 0xb8,
+		FlowID:       0xdead,
+		NextHdr:      slayers.L4UDP,
+		PathType:     scion.PathType,
+		SrcIA:        addr.MustParseIA("1-ff00:0:3"),
+		DstIA:        dstIA,
+		Path:         sp,
+	}
+	srcA := addr.MustParseHost("172.16.3.1")
+	if err := scionL.SetSrcAddr(srcA); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetDstAddr(dst); err != nil {
+		panic(err)
+	}
+
+	scionudp := &slayers.UDP{}
+	scionudp.SrcPort = 40111
+	scionudp.DstPort = 40222
+	scionudp.SetNetworkLayerForChecksum(scionL)
+
+	payload := []byte("actualpayloadbytes")
+
+	// Prepare input packet
+	input := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(input, options,
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	// Prepare want packet
+	want := gopacket.NewSerializeBuffer()
+	ethernet.SrcMAC, ethernet.DstMAC = ethernet.DstMAC, ethernet.SrcMAC
+	ip.SrcIP, ip.DstIP = ip.DstIP, ip.SrcIP
+	udp.SrcPort, udp.DstPort = udp.DstPort, udp.SrcPort
+
+	scionL.DstIA = scionL.SrcIA
+	scionL.SrcIA = addr.MustParseIA("1-ff00:0:1")
+	if err := scionL.SetDstAddr(srcA); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetSrcAddr(addr.MustParseHost("192.168.0.11")); err != nil {
+		panic(err)
+	}
+
+	p, err := sp.Reverse()
+	if err != nil {
+		panic(err)
+	}
+	sp = p.(*scion.Decoded)
+	if err := sp.IncPath(); err != nil {
+		panic(err)
+	}
+	scionL.NextHdr = slayers.End2EndClass
+	e2e := normalizedSCMPPacketAuthEndToEndExtn()
+	e2e.NextHdr = slayers.L4SCMP
+	scmpH := &slayers.SCMP{
+		TypeCode: slayers.CreateSCMPTypeCode(typ, 0),
+	}
+	scmpH.SetNetworkLayerForChecksum(scionL)
+
+	// Skip Ethernet + IPv4 + UDP
+	quoteStart := 14 + 20 + 8
+	quote := input.Bytes()[quoteStart:]
+	if err := gopacket.SerializeLayers(want, options,
+		ethernet, ip, udp, scionL, e2e, scmpH, scmpP, gopacket.Payload(quote),
+	); err != nil {
+		panic(err)
+	}
+
+	return runner.Case{
+		Name:              name,
+		WriteTo:           "veth_131_host",
+		ReadFrom:          "veth_131_host",
+		Input:             input.Bytes(),
+		Want:              want.Bytes(),
+		StoreDir:          filepath.Join(artifactsDir, name),
+		IgnoreNonMatching: true,
+		NormalizePacket:   scmpNormalizePacket,
+	}
+}
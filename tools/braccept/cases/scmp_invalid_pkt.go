@@ -173,13 +173,171 @@ func SCMPBadPktLen(artifactsDir string, mac hash.Hash) runner.Case {
 	}
 
 	return runner.Case{
-		Name:            "SCMPBadPktLen",
-		WriteTo:         "veth_131_host",
-		ReadFrom:        "veth_131_host",
-		Input:           input.Bytes(),
-		Want:            want.Bytes(),
-		StoreDir:        filepath.Join(artifactsDir, "SCMPBadPktLen"),
-		NormalizePacket: scmpNormalizePacket,
+		Name:                "SCMPBadPktLen",
+		WriteTo:             "veth_131_host",
+		ReadFrom:            "veth_131_host",
+		Input:               input.Bytes(),
+		Want:                want.Bytes(),
+		StoreDir:            filepath.Join(artifactsDir, "SCMPBadPktLen"),
+		InputMayBeMalformed: true,
+		NormalizePacket:     scmpNormalizePacket,
+	}
+}
+
+// SCMPInvalidHdrLen tests a packet whose common header claims a header length
+// that is longer than the address and path headers. The router must reply
+// with a parameter problem that points at the HdrLen field.
+func SCMPInvalidHdrLen(artifactsDir string, mac hash.Hash) runner.Case {
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+
+	ethernet := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
+		DstMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x13},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    net.IP{192, 168, 13, 3},
+		DstIP:    net.IP{192, 168, 13, 2},
+		Protocol: layers.IPProtocolUDP,
+		Flags:    layers.IPv4DontFragment,
+	}
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(40000),
+		DstPort: layers.UDPPort(50000),
+	}
+	_ = udp.SetNetworkLayerForChecksum(ip)
+
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: 1,
+				SegLen: [3]uint8{3, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 3,
+		},
+		InfoFields: []path.InfoField{
+			{
+				SegID:     0x111,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []path.HopField{
+			{ConsIngress: 0, ConsEgress: 311},
+			{ConsIngress: 131, ConsEgress: 141},
+			{ConsIngress: 411, ConsEgress: 0},
+		},
+	}
+	sp.HopFields[1].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[1], nil)
+
+	scionL := &slayers.SCION{
+		Version:      0,
+		TrafficClass: 0xb8,
+		FlowID:       0xdead,
+		NextHdr:      slayers.L4UDP,
+		PathType:     scion.PathType,
+		SrcIA:        addr.MustParseIA("1-ff00:0:3"),
+		DstIA:        addr.MustParseIA("1-ff00:0:4"),
+		Path:         sp,
+	}
+	srcA := addr.MustParseHost("172.16.3.1")
+	if err := scionL.SetSrcAddr(srcA); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetDstAddr(addr.MustParseHost("174.16.4.1")); err != nil {
+		panic(err)
+	}
+
+	scionudp := &slayers.UDP{}
+	scionudp.SrcPort = 40111
+	scionudp.DstPort = 40222
+	scionudp.SetNetworkLayerForChecksum(scionL)
+
+	payload := []byte("actualpayloadbytes")
+
+	// do a serialization run to fix lengths
+	if err := gopacket.SerializeLayers(gopacket.NewSerializeBuffer(), options,
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	// Prepare input packet
+	input := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(input, gopacket.SerializeOptions{ComputeChecksums: true},
+		ethernet, ip, udp, scionL, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+	// Mess the header length up: claim one more line than the address and
+	// path headers take. The offset of the HdrLen field in the common header
+	// is 5.
+	const hdrLenOffset = 5
+	input.Bytes()[14+20+8+hdrLenOffset]++
+
+	// Prepare want packet
+	want := gopacket.NewSerializeBuffer()
+	ethernet.SrcMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x13}
+	ethernet.DstMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef}
+	ip.SrcIP = net.IP{192, 168, 13, 2}
+	ip.DstIP = net.IP{192, 168, 13, 3}
+	udp.SrcPort, udp.DstPort = udp.DstPort, udp.SrcPort
+
+	scionL.DstIA = scionL.SrcIA
+	scionL.SrcIA = addr.MustParseIA("1-ff00:0:1")
+	if err := scionL.SetDstAddr(srcA); err != nil {
+		panic(err)
+	}
+	intlA := addr.MustParseHost("192.168.0.11")
+	if err := scionL.SetSrcAddr(intlA); err != nil {
+		panic(err)
+	}
+
+	p, err := sp.Reverse()
+	if err != nil {
+		panic(err)
+	}
+	sp = p.(*scion.Decoded)
+	if err := sp.IncPath(); err != nil {
+		panic(err)
+	}
+	scionL.NextHdr = slayers.End2EndClass
+	e2e := normalizedSCMPPacketAuthEndToEndExtn()
+	e2e.NextHdr = slayers.L4SCMP
+	scmpH := &slayers.SCMP{
+		TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem,
+			slayers.SCMPCodeInvalidCommonHeader),
+	}
+	scmpH.SetNetworkLayerForChecksum(scionL)
+	scmpP := &slayers.SCMPParameterProblem{
+		Pointer: hdrLenOffset,
+	}
+
+	// Skip Ethernet + IPv4 + UDP
+	quoteStart := 14 + 20 + 8
+	quote := input.Bytes()[quoteStart:]
+	if err := gopacket.SerializeLayers(want, options,
+		ethernet, ip, udp, scionL, e2e, scmpH, scmpP, gopacket.Payload(quote),
+	); err != nil {
+		panic(err)
+	}
+
+	return runner.Case{
+		Name:                "SCMPInvalidHdrLen",
+		WriteTo:             "veth_131_host",
+		ReadFrom:            "veth_131_host",
+		Input:               input.Bytes(),
+		Want:                want.Bytes(),
+		StoreDir:            filepath.Join(artifactsDir, "SCMPInvalidHdrLen"),
+		InputMayBeMalformed: true,
+		NormalizePacket:     scmpNormalizePacket,
 	}
 }
 
@@ -335,12 +493,192 @@ func SCMPQuoteCut(artifactsDir string, mac hash.Hash) runner.Case {
 	}
 
 	return runner.Case{
-		Name:            "SCMPQuoteCut",
+		Name:                "SCMPQuoteCut",
+		WriteTo:             "veth_131_host",
+		ReadFrom:            "veth_131_host",
+		Input:               input.Bytes(),
+		Want:                want.Bytes(),
+		StoreDir:            filepath.Join(artifactsDir, "SCMPQuoteCut"),
+		InputMayBeMalformed: true,
+		NormalizePacket:     scmpNormalizePacket,
+	}
+}
+
+// SCMPQuoteCutExtensions tests that a long packet with hop-by-hop and
+// end-to-end extension headers that triggers an SCMP error is quoted starting
+// at the SCION header, including the extension headers, and that the quote is
+// cut off such that the response does not exceed the maximum SCMP packet
+// length.
+func SCMPQuoteCutExtensions(artifactsDir string, mac hash.Hash) runner.Case {
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+
+	ethernet := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
+		DstMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x13},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    net.IP{192, 168, 13, 3},
+		DstIP:    net.IP{192, 168, 13, 2},
+		Protocol: layers.IPProtocolUDP,
+		Flags:    layers.IPv4DontFragment,
+	}
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(40000),
+		DstPort: layers.UDPPort(50000),
+	}
+	_ = udp.SetNetworkLayerForChecksum(ip)
+
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: 1,
+				SegLen: [3]uint8{3, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 3,
+		},
+		InfoFields: []path.InfoField{
+			{
+				SegID:     0x111,
+				ConsDir:   true,
+				Timestamp: util.TimeToSecs(time.Now()),
+			},
+		},
+		HopFields: []path.HopField{
+			{ConsIngress: 0, ConsEgress: 311},
+			{ConsIngress: 131, ConsEgress: 141},
+			{ConsIngress: 411, ConsEgress: 0},
+		},
+	}
+	// Invalid MAC to trigger an SCMP error:
+	sp.HopFields[1].Mac = path.MAC(mac, sp.InfoFields[0], sp.HopFields[1], nil)
+	sp.HopFields[1].Mac[0] ^= 0xff
+
+	scionL := &slayers.SCION{
+		Version:      0,
+		TrafficClass: 0xb8,
+		FlowID:       0xdead,
+		NextHdr:      slayers.HopByHopClass,
+		PathType:     scion.PathType,
+		SrcIA:        addr.MustParseIA("1-ff00:0:3"),
+		DstIA:        addr.MustParseIA("1-ff00:0:4"),
+		Path:         sp,
+	}
+	srcA := addr.MustParseHost("172.16.3.1")
+	if err := scionL.SetSrcAddr(srcA); err != nil {
+		panic(err)
+	}
+	if err := scionL.SetDstAddr(addr.MustParseHost("174.16.4.1")); err != nil {
+		panic(err)
+	}
+
+	hbh := &slayers.HopByHopExtn{}
+	hbh.NextHdr = slayers.End2EndClass
+	hbh.Options = []*slayers.HopByHopOption{
+		{
+			OptType:  0x1e,
+			OptData:  []byte{0xaa, 0xaa, 0xaa, 0xaa, 0xbb, 0xbb, 0xbb, 0xbb},
+			OptAlign: [2]uint8{4, 2},
+		},
+	}
+	e2e := &slayers.EndToEndExtn{}
+	e2e.NextHdr = slayers.L4UDP
+	e2e.Options = []*slayers.EndToEndOption{
+		{
+			OptType:  0x3e,
+			OptData:  []byte{0x11, 0x22, 0x22, 0x44, 0x44, 0x44, 0x44},
+			OptAlign: [2]uint8{4, 3},
+		},
+	}
+
+	scionudp := &slayers.UDP{}
+	scionudp.SrcPort = 40111
+	scionudp.DstPort = 40222
+	scionudp.SetNetworkLayerForChecksum(scionL)
+
+	payload := make([]byte, slayers.MaxSCMPPacketLen)
+	for i := 0; i < slayers.MaxSCMPPacketLen; i++ {
+		// Use random values A-Z.
+		payload[i] = byte((i % 25) + 65)
+	}
+	pointer := slayers.CmnHdrLen + scionL.AddrHdrLen() +
+		(4 + 8*sp.NumINF + 12*int(sp.PathMeta.CurrHF))
+
+	// Prepare input packet
+	input := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(input, options,
+		ethernet, ip, udp, scionL, hbh, e2e, scionudp, gopacket.Payload(payload),
+	); err != nil {
+		panic(err)
+	}
+
+	// Prepare want packet
+	want := gopacket.NewSerializeBuffer()
+	ethernet.SrcMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x13}
+	ethernet.DstMAC = net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef}
+	ip.SrcIP = net.IP{192, 168, 13, 2}
+	ip.DstIP = net.IP{192, 168, 13, 3}
+	udp.SrcPort, udp.DstPort = udp.DstPort, udp.SrcPort
+
+	scionL.DstIA = scionL.SrcIA
+	scionL.SrcIA = addr.MustParseIA("1-ff00:0:1")
+	if err := scionL.SetDstAddr(srcA); err != nil {
+		panic(err)
+	}
+	intlA := addr.MustParseHost("192.168.0.11")
+	if err := scionL.SetSrcAddr(intlA); err != nil {
+		panic(err)
+	}
+
+	p, err := sp.Reverse()
+	if err != nil {
+		panic(err)
+	}
+	sp = p.(*scion.Decoded)
+	if err := sp.IncPath(); err != nil {
+		panic(err)
+	}
+	// The reply only carries the authenticator option, the extensions of the
+	// original packet are only part of the quote.
+	scionL.NextHdr = slayers.End2EndClass
+	replyE2E := normalizedSCMPPacketAuthEndToEndExtn()
+	replyE2E.NextHdr = slayers.L4SCMP
+	scmpH := &slayers.SCMP{
+		TypeCode: slayers.CreateSCMPTypeCode(slayers.SCMPTypeParameterProblem,
+			slayers.SCMPCodeInvalidHopFieldMAC),
+	}
+	scmpH.SetNetworkLayerForChecksum(scionL)
+	scmpP := &slayers.SCMPParameterProblem{
+		Pointer: uint16(pointer),
+	}
+
+	// Skip Ethernet + IPv4 + UDP
+	quoteStart := 14 + 20 + 8
+	// headerLen is the length of the SCION header, plus the e2e.option len
+	// plus the SCMP header (8).
+	headerLen := slayers.CmnHdrLen + scionL.AddrHdrLen() + scionL.Path.Len() + 32 + 8
+	quoteEnd := quoteStart + slayers.MaxSCMPPacketLen - headerLen
+	quote := input.Bytes()[quoteStart:quoteEnd]
+	if err := gopacket.SerializeLayers(want, options,
+		ethernet, ip, udp, scionL, replyE2E, scmpH, scmpP, gopacket.Payload(quote),
+	); err != nil {
+		panic(err)
+	}
+
+	return runner.Case{
+		Name:            "SCMPQuoteCutExtensions",
 		WriteTo:         "veth_131_host",
 		ReadFrom:        "veth_131_host",
 		Input:           input.Bytes(),
 		Want:            want.Bytes(),
-		StoreDir:        filepath.Join(artifactsDir, "SCMPQuoteCut"),
+		StoreDir:        filepath.Join(artifactsDir, "SCMPQuoteCutExtensions"),
 		NormalizePacket: scmpNormalizePacket,
 	}
 }
@@ -447,12 +785,13 @@ func NoSCMPReplyForSCMPError(artifactsDir string, mac hash.Hash) runner.Case {
 		panic(err)
 	}
 	return runner.Case{
-		Name:            "NoSCMPReplyForSCMPError",
-		WriteTo:         "veth_131_host",
-		ReadFrom:        "no_pkt_expected",
-		Input:           input.Bytes(),
-		Want:            nil,
-		StoreDir:        filepath.Join(artifactsDir, "NoSCMPReplyForSCMPError"),
-		NormalizePacket: scmpNormalizePacket,
+		Name:                "NoSCMPReplyForSCMPError",
+		WriteTo:             "veth_131_host",
+		ReadFrom:            "no_pkt_expected",
+		Input:               input.Bytes(),
+		Want:                nil,
+		StoreDir:            filepath.Join(artifactsDir, "NoSCMPReplyForSCMPError"),
+		InputMayBeMalformed: true,
+		NormalizePacket:     scmpNormalizePacket,
 	}
 }
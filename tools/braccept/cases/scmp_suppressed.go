@@ -0,0 +1,33 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cases
+
+import (
+	"hash"
+	"path/filepath"
+
+	"github.com/scionproto/scion/tools/braccept/runner"
+)
+
+// SCMPBadMACSuppressed sends the packet of SCMPBadMAC to a router that is
+// configured to suppress SCMP parameter problem messages. The router is
+// expected to drop the packet without sending any SCMP message.
+func SCMPBadMACSuppressed(artifactsDir string, mac hash.Hash) runner.Case {
+	c := SCMPBadMAC(artifactsDir, mac)
+	c.Name = "SCMPBadMACSuppressed"
+	c.StoreDir = filepath.Join(artifactsDir, "SCMPBadMACSuppressed")
+	c.Want = nil
+	return c
+}
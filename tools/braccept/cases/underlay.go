@@ -0,0 +1,51 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cases
+
+import (
+	"net"
+
+	"github.com/gopacket/gopacket/layers"
+)
+
+// underlay returns the Ethernet, IPv4 and UDP layers of an underlay packet
+// between the given addresses. The IPv4 header has the DF flag set, and the
+// UDP layer has the IPv4 layer set for the checksum computation.
+func underlay(
+	srcMAC, dstMAC net.HardwareAddr,
+	srcIP, dstIP net.IP,
+	srcPort, dstPort uint16,
+) (*layers.Ethernet, *layers.IPv4, *layers.UDP) {
+	ethernet := &layers.Ethernet{
+		SrcMAC:       srcMAC,
+		DstMAC:       dstMAC,
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    srcIP,
+		DstIP:    dstIP,
+		Protocol: layers.IPProtocolUDP,
+		Flags:    layers.IPv4DontFragment,
+	}
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(srcPort),
+		DstPort: layers.UDPPort(dstPort),
+	}
+	_ = udp.SetNetworkLayerForChecksum(ip)
+	return ethernet, ip, udp
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cases
+
+import (
+	"net"
+	"testing"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnderlay(t *testing.T) {
+	ethernet, ip, udp := underlay(
+		net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
+		net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x13},
+		net.IP{192, 168, 13, 3},
+		net.IP{192, 168, 13, 2},
+		40000, 50000,
+	)
+
+	// Computing the UDP checksum fails if the network layer is not set.
+	buf := gopacket.NewSerializeBuffer()
+	err := gopacket.SerializeLayers(buf,
+		gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true},
+		ethernet, ip, udp, gopacket.Payload("payload"),
+	)
+	require.NoError(t, err)
+
+	pkt := gopacket.NewPacket(buf.Bytes(), layers.LayerTypeEthernet, gopacket.Default)
+	require.Nil(t, pkt.ErrorLayer())
+	gotIP := pkt.Layer(layers.LayerTypeIPv4).(*layers.IPv4)
+	assert.Equal(t, net.IP{192, 168, 13, 3}, gotIP.SrcIP.To4())
+	assert.Equal(t, net.IP{192, 168, 13, 2}, gotIP.DstIP.To4())
+	assert.Equal(t, layers.IPv4DontFragment, gotIP.Flags)
+	gotUDP := pkt.Layer(layers.LayerTypeUDP).(*layers.UDP)
+	assert.Equal(t, layers.UDPPort(40000), gotUDP.SrcPort)
+	assert.Equal(t, layers.UDPPort(50000), gotUDP.DstPort)
+	assert.NotZero(t, gotUDP.Checksum)
+
+	// Without the network layer, the checksum cannot be computed.
+	err = gopacket.SerializeLayers(gopacket.NewSerializeBuffer(),
+		gopacket.SerializeOptions{ComputeChecksums: true},
+		&layers.UDP{SrcPort: 40000, DstPort: 50000},
+	)
+	assert.Error(t, err)
+}
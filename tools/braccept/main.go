@@ -23,6 +23,8 @@ import (
 	"hash"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
 
 	"github.com/gopacket/gopacket/layers"
 
@@ -38,14 +40,56 @@ var (
 	bfd        = flag.Bool("bfd", false, "Run BFD tests instead of the common ones")
 	logConsole = flag.String("log.console", "debug", "Console logging level: debug|info|error")
 	dir        = flag.String("artifacts", "", "Artifacts directory")
+	validate   = flag.Bool("validate", false,
+		"Validate the input packets of the cases before sending them")
+	bench        = flag.Bool("bench", false, "Benchmark the runner instead of running the tests")
+	benchPackets = flag.Int("bench.packets", 100000, "Number of packets injected by -bench")
+	scmpSuppress = flag.Bool("scmp_suppress", false,
+		"Run the SCMP suppression tests instead of the common ones")
+	run = flag.String("run", "",
+		"Run only the cases whose name matches the regular expression")
+	manifest = flag.String("manifest", "",
+		"Run only the cases listed in the YAML or JSON manifest file. "+
+			"It is combined with -run")
+	outputJSON = flag.String("output.json", "",
+		"Write the results of the cases as JSON to the file")
+	pcapAlways = flag.Bool("pcap.always", false,
+		"Store the packets of all cases as pcap files, not only of the failing ones")
+	check = flag.Bool("check", false,
+		"Check the construction of the cases without opening any device")
+	parallel = flag.Int("parallel", 1,
+		"Number of cases that are run concurrently. Cases that use a common device "+
+			"are never run concurrently")
+	slow = flag.Int("slow", 0, "Report the durations of the N slowest cases at the end")
+	list = flag.Bool("list", false,
+		"List the names of the cases grouped by suite without running them. "+
+			"It is combined with -run")
 )
 
+// suite is a group of cases that is run against the same router setup.
+type suite struct {
+	name string
+	add  func(b *runner.Builder)
+}
+
+// suites are the suites of cases. The common suite is run by default, the
+// others if the flag of the same name is set.
+var suites = []suite{
+	{name: "common", add: commonCases},
+	{name: "bfd", add: bfdCases},
+	{name: "scmp_suppress", add: scmpSuppressCases},
+}
+
 func main() {
 	os.Exit(realMain())
 }
 
 func realMain() int {
 	flag.Parse()
+	if *bfd && *scmpSuppress {
+		fmt.Fprintf(os.Stderr, "-bfd and -scmp_suppress are mutually exclusive\n")
+		return 1
+	}
 	logCfg := log.Config{Console: log.ConsoleConfig{Level: *logConsole}}
 	if err := log.Setup(logCfg); err != nil {
 		flag.Usage()
@@ -66,92 +110,249 @@ func realMain() int {
 		artifactsDir = v
 	}
 	hfMAC, err := loadKey(artifactsDir)
+	if err != nil && (*check || *list) {
+		// The MACs are not verified by the check or the listing, any key will do.
+		log.Info("Loading keys failed, checking with a fixed key", "err", err)
+		hfMAC, err = checkKey()
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Loading keys failed: %v\n", err)
 		return 1
 	}
 
+	registerScionPorts()
+
+	if *list {
+		return listCases(artifactsDir, hfMAC)
+	}
+	selected := suites[0]
+	if *bfd {
+		selected = suites[1]
+	}
+	if *scmpSuppress {
+		selected = suites[2]
+	}
+	b := runner.Builder{ArtifactsDir: artifactsDir, MAC: hfMAC}
+	selected.add(&b)
+	// A case that fails to construct is reported as failed, the other cases
+	// are still run.
+	for _, res := range b.Failed {
+		log.Error(fmt.Sprintf("%s\n%s", res.Name, res.Err.Error()))
+	}
+	multi := b.Cases
+
+	var unlisted int
+	if *manifest != "" {
+		m, err := runner.LoadManifest(*manifest)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			return 1
+		}
+		if multi, unlisted, err = runner.SelectManifest(multi, m); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid manifest %s: %s\n", *manifest, err)
+			return 1
+		}
+	}
+	multi, filtered, err := runner.Select(multi, *run)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+	log.Info("Selected cases", "selected", len(multi), "filtered", filtered+unlisted)
+
+	if *check {
+		return checkCases(multi) + len(b.Failed)
+	}
+
 	rc, err := runner.NewRunConfig()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Loading devices failed: %v\n", err)
 		return 1
 	}
-
-	registerScionPorts()
+	if *bench {
+		return runBench(rc, cases.ParentToChild(artifactsDir, hfMAC))
+	}
 
 	log.Info("BR V2 acceptance tests:")
-
-	multi := []runner.Case{
-		cases.ParentToChild(artifactsDir, hfMAC),
-		cases.ParentToInternalHost(artifactsDir, hfMAC),
-		cases.ParentToInternalHostMultiSegment(artifactsDir, hfMAC),
-		cases.ChildToParent(artifactsDir, hfMAC),
-		cases.ChildToChildXover(artifactsDir, hfMAC),
-		cases.ChildToInternalHost(artifactsDir, hfMAC),
-		cases.ChildToInternalHostShortcut(artifactsDir, hfMAC),
-		cases.ChildToInternalParent(artifactsDir, hfMAC),
-		cases.InternalHostToChild(artifactsDir, hfMAC),
-		cases.InternalParentToChild(artifactsDir, hfMAC),
-		cases.InvalidSrcInternalParentToChild(artifactsDir, hfMAC),
-		cases.SCMPDestinationUnreachable(artifactsDir, hfMAC),
-		cases.SCMPBadMAC(artifactsDir, hfMAC),
-		cases.SCMPBadMACInternal(artifactsDir, hfMAC),
-		cases.SCMPExpiredHopAfterXover(artifactsDir, hfMAC),
-		cases.SCMPExpiredHopAfterXoverConsDir(artifactsDir, hfMAC),
-		cases.SCMPExpiredHopAfterXoverInternal(artifactsDir, hfMAC),
-		cases.SCMPExpiredHopAfterXoverInternalConsDir(artifactsDir, hfMAC),
-		cases.SCMPExpiredHop(artifactsDir, hfMAC),
-		cases.SCMPChildToParentXover(artifactsDir, hfMAC),
-		cases.SCMPParentToChildXover(artifactsDir, hfMAC),
-		cases.SCMPParentToParentXover(artifactsDir, hfMAC),
-		cases.SCMPChildToParentLocalXover(artifactsDir, hfMAC),
-		cases.SCMPParentToChildLocalXover(artifactsDir, hfMAC),
-		cases.SCMPParentToParentLocalXover(artifactsDir, hfMAC),
-		cases.SCMPInternalXover(artifactsDir, hfMAC),
-		cases.SCMPUnknownHop(artifactsDir, hfMAC),
-		cases.SCMPUnknownHopEgress(artifactsDir, hfMAC),
-		cases.SCMPUnknownHopWrongRouter(artifactsDir, hfMAC),
-		cases.SCMPInvalidHopParentToParent(artifactsDir, hfMAC),
-		cases.SCMPInvalidHopChildToChild(artifactsDir, hfMAC),
-		cases.SCMPTracerouteIngress(artifactsDir, hfMAC),
-		cases.SCMPTracerouteIngressConsDir(artifactsDir, hfMAC),
-		cases.SCMPTracerouteEgress(artifactsDir, hfMAC),
-		cases.SCMPTracerouteEgressConsDir(artifactsDir, hfMAC),
-		cases.SCMPTracerouteEgressAfterXover(artifactsDir, hfMAC),
-		cases.SCMPTracerouteInternal(artifactsDir, hfMAC),
-		cases.SCMPTracerouteIngressWithSPAO(artifactsDir, hfMAC),
-		cases.SCMPBadPktLen(artifactsDir, hfMAC),
-		cases.SCMPQuoteCut(artifactsDir, hfMAC),
-		cases.SCMPInvalidSrcIAInternalHostToChild(artifactsDir, hfMAC),
-		cases.SCMPInvalidDstIAInternalHostToChild(artifactsDir, hfMAC),
-		cases.SCMPInvalidSrcIAChildToParent(artifactsDir, hfMAC),
-		cases.SCMPInvalidDstIAChildToParent(artifactsDir, hfMAC),
-		cases.NoSCMPReplyForSCMPError(artifactsDir, hfMAC),
-		cases.MalformedPathSingletonSegment(artifactsDir, hfMAC),
-		cases.MalformedPathCurrHFNotInCurrINF(artifactsDir, hfMAC),
-		cases.IncomingOneHop(artifactsDir, hfMAC),
-		cases.OutgoingOneHop(artifactsDir, hfMAC),
-		cases.SVC(artifactsDir, hfMAC),
-		cases.JumboPacket(artifactsDir, hfMAC),
-		cases.ChildToPeer(artifactsDir, hfMAC),
-		cases.PeerToChild(artifactsDir, hfMAC),
+	rc.StoreAlways = *pcapAlways
+	if *parallel > 1 {
+		rc.EnableParallel()
 	}
-
-	if *bfd {
-		multi = []runner.Case{
-			cases.ExternalBFD(artifactsDir, hfMAC),
-			cases.InternalBFD(artifactsDir, hfMAC),
+	results := slices.Clone(b.Failed)
+	ret, passed, skipped := len(b.Failed), 0, 0
+	runFn := func(c runner.Case) runner.Result { return runCase(rc, c) }
+	runner.Schedule(multi, *parallel, runFn, func(res runner.Result) {
+		results = append(results, res)
+		switch {
+		case res.Skipped:
+			log.Info(res.Name, "result", "skipped", "reason", res.Reason)
+			skipped++
+		case res.Err != nil:
+			log.Error(fmt.Sprintf("%s\n%s", res.Name, res.Err.Error()),
+				"duration", res.Duration)
+			ret++
+		default:
+			log.Info(res.Name, "result", "expected packet was captured!",
+				"duration", res.Duration)
+			passed++
+		}
+	})
+	log.Info("BR V2 acceptance tests done",
+		"passed", passed, "failed", ret, "skipped", skipped)
+	if *slow > 0 {
+		log.Info("Slowest cases:")
+		for _, res := range runner.Slowest(results, *slow) {
+			log.Info(res.Name, "duration", res.Duration, "passed", res.Err == nil)
 		}
 	}
+	if *outputJSON != "" {
+		if err := runner.WriteResults(*outputJSON, results); err != nil {
+			log.Error("Writing results failed", "err", err)
+			ret++
+		}
+	}
+	return ret
+}
+
+// runCase validates the input of the case if requested, and runs it.
+func runCase(rc *runner.RunConfig, c runner.Case) runner.Result {
+	if skip, reason := c.Skip(); skip {
+		return runner.Result{Name: c.Name, Skipped: true, Reason: reason}
+	}
+	start := time.Now()
+	var err error
+	if *validate {
+		err = c.ValidateInput()
+	}
+	if err == nil {
+		err = c.Run(rc)
+	}
+	return runner.Result{Name: c.Name, Duration: time.Since(start), Err: err}
+}
+
+func commonCases(b *runner.Builder) {
+	b.Add(
+		cases.ParentToChild,
+		cases.ParentToChildEgressCheck,
+		cases.ParentToChildRawPath,
+		cases.ParentToChildUnknownNextHdr,
+		cases.ParentToChildHBHOptions,
+		cases.ParentToInternalHost,
+		cases.ParentToInternalHostMultiSegment,
+		cases.ParentToRouterHost,
+		cases.ChildToParent,
+		cases.ChildToChildXover,
+		cases.ChildToChildXoverReverseConsDir,
+		cases.ChildToChildXoverPathPointers,
+		cases.ParentToChildIPv6,
+		cases.ChildToParentIPv6,
+		cases.ChildToChildXoverIPv6,
+		cases.ParentToChildEPIC,
+		cases.ParentToChildEPICBadHVF,
+		cases.ChildToParentEPIC,
+		cases.ChildToInternalHost,
+		cases.ChildToInternalHostShortcut,
+		cases.ChildToInternalParent,
+		cases.InternalHostToChild,
+		cases.InternalParentToChild,
+		cases.InvalidSrcInternalParentToChild,
+		cases.SCMPDestinationUnreachable,
+		cases.SCMPBadMAC,
+		cases.SCMPZeroMAC,
+		cases.SCMPBadMACWrongDirection,
+		cases.SCMPBadMACInternal,
+		cases.SCMPExpiredHopAfterXover,
+		cases.SCMPExpiredHopAfterXoverConsDir,
+		cases.SCMPExpiredHopAfterXoverInternal,
+		cases.SCMPExpiredHopAfterXoverInternalConsDir,
+		cases.SCMPExpiredHop,
+		cases.SCMPExpiredHopBadMAC,
+		cases.SCMPChildToParentXover,
+		cases.SCMPParentToChildXover,
+		cases.SCMPParentToParentXover,
+		cases.SCMPChildToParentLocalXover,
+		cases.SCMPParentToChildLocalXover,
+		cases.SCMPParentToParentLocalXover,
+		cases.SCMPInternalXover,
+		cases.SCMPUnknownHop,
+		cases.SCMPUnknownHopEgress,
+		cases.SCMPUnknownHopWrongRouter,
+		cases.SCMPInvalidHopParentToParent,
+		cases.SCMPInvalidHopChildToChild,
+		cases.SCMPTracerouteIngress,
+		cases.SCMPTracerouteIngressConsDir,
+		cases.SCMPTracerouteEgress,
+		cases.SCMPTracerouteEgressConsDir,
+		cases.SCMPTracerouteEgressAfterXover,
+		cases.SCMPTracerouteInternal,
+		cases.SCMPTracerouteIngressWithSPAO,
+		cases.SCMPTracerouteLastHop,
+		cases.SCMPBadPktLen,
+		cases.SCMPInvalidHdrLen,
+		cases.SCMPQuoteCut,
+		cases.SCMPQuoteCutExtensions,
+		cases.SCMPInvalidSrcIAInternalHostToChild,
+		cases.SCMPInvalidDstIAInternalHostToChild,
+		cases.SCMPInvalidSrcIAChildToParent,
+		cases.SCMPInvalidDstIAChildToParent,
+		cases.NoSCMPReplyForSCMPError,
+		cases.MalformedPathSingletonSegment,
+		cases.MalformedPathCurrHFNotInCurrINF,
+		cases.IncomingOneHop,
+		cases.OutgoingOneHop,
+		cases.SVC,
+		cases.JumboPacket,
+		cases.UnderlayFragmented,
+		cases.UnderlayFragmentMissing,
+		cases.ChildToPeer,
+		cases.PeerToChild,
+		cases.PeerToChildMultiHop,
+		cases.PeerToChildWithSPAO,
+	)
+	b.AddMulti(cases.ParentToChildFlowIDs)
+}
+
+func bfdCases(b *runner.Builder) {
+	b.Add(
+		cases.ExternalBFD,
+		cases.InternalBFD,
+		cases.SCMPExternalInterfaceDown,
+		cases.SCMPInternalConnectivityDown,
+	)
+	b.AddMulti(cases.ExternalBFDBackToBack, cases.BFDParameters)
+}
+
+// scmpSuppressCases are run against a router that suppresses SCMP parameter
+// problem messages, but still sends other SCMP messages.
+func scmpSuppressCases(b *runner.Builder) {
+	b.Add(
+		cases.SCMPBadMACSuppressed,
+		cases.SCMPDestinationUnreachable,
+	)
+}
 
+// listCases prints the names of the cases that match -run, grouped by suite.
+func listCases(artifactsDir string, mac hash.Hash) int {
 	ret := 0
-	for _, c := range multi {
-		if err := c.Run(rc); err != nil {
-			log.Error(fmt.Sprintf("%s\n%s", c.Name, err.Error()))
+	for _, s := range suites {
+		b := runner.Builder{ArtifactsDir: artifactsDir, MAC: mac}
+		s.add(&b)
+		for _, res := range b.Failed {
+			log.Error(fmt.Sprintf("%s\n%s", res.Name, res.Err.Error()))
 			ret++
+		}
+		selected, _, err := runner.Select(b.Cases, *run)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			return 1
+		}
+		if len(selected) == 0 {
 			continue
 		}
-		log.Info(c.Name, "result", "expected packet was captured!")
+		fmt.Printf("%s:\n", s.name)
+		for _, c := range selected {
+			fmt.Printf("  %s\n", c.Name)
+		}
 	}
 	return ret
 }
@@ -169,6 +370,53 @@ func loadKey(artifactsDir string) (hash.Hash, error) {
 	return macGen(), nil
 }
 
+// checkKey returns a MAC for a fixed key, which is used by the check if no keys
+// are available.
+func checkKey() (hash.Hash, error) {
+	macGen, err := scrypto.HFMacFactory([]byte("braccept-check"))
+	if err != nil {
+		return nil, err
+	}
+	return macGen(), nil
+}
+
+// checkCases checks the construction of the cases without running them.
+func checkCases(cs []runner.Case) int {
+	ret := 0
+	for _, c := range cs {
+		if err := c.Check(); err != nil {
+			log.Error(fmt.Sprintf("%s\n%s", c.Name, err.Error()))
+			ret++
+			continue
+		}
+		log.Info(c.Name, "result", "construction is valid")
+	}
+	log.Info("BR V2 acceptance test check done", "checked", len(cs), "failed", ret)
+	return ret
+}
+
+// runBench injects the input of the case repeatedly and reports the throughput
+// of the runner. The forwarded packets are counted but not compared, so that
+// the numbers reflect the overhead of the harness rather than of the test
+// cases.
+func runBench(rc *runner.RunConfig, c runner.Case) int {
+	log.Info("Runner benchmark:", "case", c.Name, "packets", *benchPackets)
+	res, err := rc.Bench(c, *benchPackets)
+	if err != nil {
+		log.Error("Benchmark failed", "err", err)
+		return 1
+	}
+	log.Info("Benchmark done",
+		"sent", res.Sent,
+		"received", res.Received,
+		"duration", res.Duration,
+		"sent_pps", fmt.Sprintf("%.0f", res.SentPPS()),
+		"received_pps", fmt.Sprintf("%.0f", res.ReceivedPPS()),
+		"throughput_mbps", fmt.Sprintf("%.2f", res.Throughput()/1e6),
+	)
+	return 0
+}
+
 // registerScionPorts registers the following UDP ports in gopacket such as SCION is the
 // next layer. In other words, map the following ports to expect SCION as the payload.
 func registerScionPorts() {
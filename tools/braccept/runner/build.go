@@ -0,0 +1,89 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"fmt"
+	"hash"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+// Constructor constructs a case from the artifacts directory and the MAC of
+// the hop fields.
+type Constructor func(artifactsDir string, mac hash.Hash) Case
+
+// MultiConstructor constructs multiple cases from the artifacts directory and
+// the MAC of the hop fields.
+type MultiConstructor func(artifactsDir string, mac hash.Hash) []Case
+
+// Builder constructs cases. The constructors panic if a case can not be
+// constructed, e.g., because a packet fails to serialize. The builder recovers
+// from the panic and records a failed result for the constructor instead, so
+// that a single broken case does not prevent the other cases from running.
+type Builder struct {
+	ArtifactsDir string
+	MAC          hash.Hash
+	// Cases are the constructed cases.
+	Cases []Case
+	// Failed contains a result for each constructor that failed. The result
+	// is named after the constructor.
+	Failed []Result
+}
+
+// Add constructs the cases and adds them to the builder.
+func (b *Builder) Add(cs ...Constructor) {
+	for _, c := range cs {
+		b.add(constructorName(c), func(artifactsDir string, mac hash.Hash) []Case {
+			return []Case{c(artifactsDir, mac)}
+		})
+	}
+}
+
+// AddMulti constructs the cases of each constructor and adds them to the
+// builder. If a constructor fails, none of its cases are added.
+func (b *Builder) AddMulti(cs ...MultiConstructor) {
+	for _, c := range cs {
+		b.add(constructorName(c), c)
+	}
+}
+
+func (b *Builder) add(name string, c MultiConstructor) {
+	cases, err := b.construct(c)
+	if err != nil {
+		b.Failed = append(b.Failed, Result{Name: name, Err: err})
+		return
+	}
+	b.Cases = append(b.Cases, cases...)
+}
+
+func (b *Builder) construct(c MultiConstructor) (cases []Case, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = serrors.New("constructing case failed", "panic", fmt.Sprint(r))
+		}
+	}()
+	return c(b.ArtifactsDir, b.MAC), nil
+}
+
+// constructorName returns the name of the constructor function without the
+// package path, e.g., ParentToChild.
+func constructorName(c any) string {
+	name := runtime.FuncForPC(reflect.ValueOf(c).Pointer()).Name()
+	return name[strings.LastIndex(name, ".")+1:]
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"hash"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func workingCase(artifactsDir string, mac hash.Hash) Case {
+	return Case{Name: "Working", StoreDir: artifactsDir}
+}
+
+func brokenCase(string, hash.Hash) Case {
+	panic("serializing failed")
+}
+
+func workingCases(artifactsDir string, mac hash.Hash) []Case {
+	return []Case{{Name: "First"}, {Name: "Second"}}
+}
+
+func brokenCases(string, hash.Hash) []Case {
+	panic("serializing failed")
+}
+
+func TestBuilder(t *testing.T) {
+	b := Builder{ArtifactsDir: "artifacts"}
+	b.Add(brokenCase, workingCase)
+	b.AddMulti(brokenCases, workingCases)
+
+	var names []string
+	for _, c := range b.Cases {
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{"Working", "First", "Second"}, names)
+	assert.Equal(t, "artifacts", b.Cases[0].StoreDir)
+
+	require.Len(t, b.Failed, 2)
+	for i, name := range []string{"brokenCase", "brokenCases"} {
+		assert.Equal(t, name, b.Failed[i].Name)
+		assert.ErrorContains(t, b.Failed[i].Err, "serializing failed")
+		assert.False(t, b.Failed[i].Skipped)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+// Check checks the construction of the case without sending any packet. It
+// runs the Prepare hook, validates the input packet unless it may be
+// malformed, and checks that the expected packets decode and that the
+// normalization can serialize them and leaves them comparable. A panic in any
+// of these steps is reported as an error.
+func (t *Case) Check() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = serrors.New("panic", "case", t.Name, "panic", fmt.Sprint(r))
+		}
+	}()
+	if t.Name == "" || t.WriteTo == "" || len(t.readDevices()) == 0 ||
+		slices.Contains(t.ReadFromMulti, "") {
+
+		return serrors.New("name and devices must be set", "case", t.Name,
+			"write_to", t.WriteTo, "read_from", t.readDevices())
+	}
+	if err := t.validateConfig(); err != nil {
+		return serrors.Wrap("invalid configuration", err, "case", t.Name)
+	}
+	// Prepare may modify the packets, work on a copy.
+	c := *t
+	if c.Prepare != nil {
+		c.Prepare(&c)
+	}
+	if len(c.Input) == 0 {
+		return serrors.New("input packet not set", "case", c.Name)
+	}
+	if err := c.ValidateInput(); err != nil {
+		return err
+	}
+	normalizeFn := c.NormalizePacket
+	if normalizeFn == nil {
+		normalizeFn = DefaultNormalizePacket
+	}
+	for i, want := range c.wants() {
+		decode := func() gopacket.Packet {
+			return gopacket.NewPacket(want, layers.LinkTypeEthernet, gopacket.Default)
+		}
+		if err := decodeError(decode()); err != nil {
+			return serrors.Wrap("invalid expected packet", err, "case", c.Name, "want", i)
+		}
+		for _, lt := range c.CompareLayers {
+			if decode().Layer(lt) == nil {
+				return serrors.New("expected packet lacks compared layer", "case", c.Name,
+					"want", i, "layer", lt)
+			}
+		}
+		if err := comparePkts(decode(), decode(), normalizeFn, c.CompareLayers...); err != nil {
+			return serrors.Wrap("normalized expected packet differs from itself", err,
+				"case", c.Name, "want", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"testing"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scionproto/scion/pkg/slayers"
+)
+
+func TestCaseCheck(t *testing.T) {
+	layers.RegisterUDPPortLayerType(layers.UDPPort(30001), slayers.LayerTypeSCION)
+	valid := func() Case {
+		return Case{
+			Name:     "case",
+			WriteTo:  "veth_131_host",
+			ReadFrom: "veth_141_host",
+			Input:    prepareInput(t, nil),
+			Want:     prepareInput(t, nil),
+		}
+	}
+
+	testCases := map[string]struct {
+		Modify      func(*Case)
+		ErrContains string
+	}{
+		"valid": {
+			Modify: func(*Case) {},
+		},
+		"valid multi": {
+			Modify: func(c *Case) {
+				c.WantMulti = [][]byte{c.Want, c.Want}
+				c.Want = nil
+			},
+		},
+		"no packet expected": {
+			Modify: func(c *Case) { c.Want = nil },
+		},
+		"missing device": {
+			Modify:      func(c *Case) { c.ReadFrom = "" },
+			ErrContains: "devices must be set",
+		},
+		"read from multi": {
+			Modify: func(c *Case) {
+				c.ReadFromMulti = []string{"veth_141_host", "veth_142_host"}
+				c.ReadFrom = ""
+			},
+		},
+		"read from and read from multi": {
+			Modify:      func(c *Case) { c.ReadFromMulti = []string{"veth_142_host"} },
+			ErrContains: "must not both be set",
+		},
+		"empty read from multi device": {
+			Modify: func(c *Case) {
+				c.ReadFromMulti = []string{"veth_141_host", ""}
+				c.ReadFrom = ""
+			},
+			ErrContains: "devices must be set",
+		},
+		"want and want multi": {
+			Modify:      func(c *Case) { c.WantMulti = [][]byte{c.Want} },
+			ErrContains: "must not both be set",
+		},
+		"no input": {
+			Modify:      func(c *Case) { c.Input = nil },
+			ErrContains: "input packet not set",
+		},
+		"invalid input": {
+			Modify:      func(c *Case) { c.Input = c.Input[:len(c.Input)-4] },
+			ErrContains: "invalid input packet",
+		},
+		"invalid want": {
+			Modify:      func(c *Case) { c.Want = c.Want[:20] },
+			ErrContains: "invalid expected packet",
+		},
+		"prepare": {
+			Modify: func(c *Case) {
+				c.Input = nil
+				c.Prepare = func(c *Case) { c.Input = prepareInput(t, nil) }
+			},
+		},
+		"compare layers": {
+			Modify: func(c *Case) {
+				c.CompareLayers = []gopacket.LayerType{slayers.LayerTypeSCION}
+			},
+		},
+		"compared layer missing": {
+			Modify: func(c *Case) {
+				c.CompareLayers = []gopacket.LayerType{layers.LayerTypeIPv6}
+			},
+			ErrContains: "lacks compared layer",
+		},
+		"panicking normalization": {
+			Modify: func(c *Case) {
+				c.NormalizePacket = func(gopacket.Packet) { panic("boom") }
+			},
+			ErrContains: "boom",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			c := valid()
+			tc.Modify(&c)
+			err := c.Check()
+			if tc.ErrContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.ErrContains)
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/gopacket/gopacket/layers"
 
 	"github.com/scionproto/scion/pkg/private/serrors"
+	"github.com/scionproto/scion/pkg/slayers"
 )
 
 func layerString(l gopacket.Layer) string {
@@ -68,19 +69,55 @@ func normalizePacket(pkt gopacket.Packet, fn NormalizePacketFn) gopacket.Packet
 // DefaultNormalizePacket zeroes-out all the fields in the packet that can't
 // generally be predicted by the test, both in received and in expected packet
 // and thus makes them equal even if the field value varies among test runs.
+// The layers are normalized regardless of their position, e.g., also in
+// 802.1Q tagged frames. The tags themselves are compared.
 func DefaultNormalizePacket(pkt gopacket.Packet) {
 	for _, l := range pkt.Layers() {
 		switch v := l.(type) {
 		case *layers.IPv4:
 			v.Id = 0
 			v.Checksum = 0
+		case *layers.IPv6:
+			// The kernel may set the flow label of outgoing packets.
+			v.FlowLabel = 0
 		case *layers.UDP:
 			v.Checksum = 0
 		}
 	}
 }
 
-func comparePkts(got, want gopacket.Packet, normalizeFn NormalizePacketFn) error {
+// IgnoreSCIONFlowID zeroes-out the flow ID of the SCION header, for cases
+// where the flow ID is chosen by the router. It does not modify the packets
+// that are quoted in SCMP error messages. It is meant to be combined with other
+// normalization functions with ChainNormalizePacket.
+func IgnoreSCIONFlowID(pkt gopacket.Packet) {
+	for _, l := range pkt.Layers() {
+		if v, ok := l.(*slayers.SCION); ok {
+			v.FlowID = 0
+		}
+	}
+}
+
+// ChainNormalizePacket returns a normalization function that applies the
+// functions in the given order. The order matters if a function inspects a
+// field that a preceding function modifies, e.g., a function that only
+// normalizes the packets of a specific flow does not find the flow anymore if
+// it runs after IgnoreSCIONFlowID. DefaultNormalizePacket is usually the first
+// function.
+func ChainNormalizePacket(fns ...NormalizePacketFn) NormalizePacketFn {
+	return func(pkt gopacket.Packet) {
+		for _, fn := range fns {
+			fn(pkt)
+		}
+	}
+}
+
+// comparePkts compares the layers of the packets after normalizing them. If
+// only is set, only the layers of the given types are compared, and both
+// packets must contain them. Otherwise, all layers of got are compared.
+func comparePkts(got, want gopacket.Packet, normalizeFn NormalizePacketFn,
+	only ...gopacket.LayerType) error {
+
 	if got == nil || want == nil {
 		return serrors.New("can not compare nil packets")
 	}
@@ -90,6 +127,15 @@ func comparePkts(got, want gopacket.Packet, normalizeFn NormalizePacketFn) error
 	}
 	var err error
 	var errors serrors.List
+	if len(only) > 0 {
+		for _, t := range only {
+			err = compareLayers(got.Layer(t), want.Layer(t))
+			if err != nil {
+				errors = append(errors, serrors.Wrap("layer mismatch", err, "layer", t))
+			}
+		}
+		return errors.ToError()
+	}
 	for _, l := range got.Layers() {
 		err = compareLayers(l, want.Layer(l.LayerType()))
 		if err != nil {
@@ -98,3 +144,30 @@ func comparePkts(got, want gopacket.Packet, normalizeFn NormalizePacketFn) error
 	}
 	return errors.ToError()
 }
+
+// matchAny compares the packet to each of the wanted packets and returns the
+// index of the first one that matches. If none matches, -1 and the mismatches
+// are returned. If only is set, only the layers of the given types are
+// compared.
+func matchAny(got gopacket.Packet, want []gopacket.Packet,
+	normalizeFn NormalizePacketFn, only ...gopacket.LayerType) (int, error) {
+
+	if len(want) == 0 {
+		return -1, serrors.New("no packet expected")
+	}
+	if len(want) == 1 {
+		if err := comparePkts(got, want[0], normalizeFn, only...); err != nil {
+			return -1, err
+		}
+		return 0, nil
+	}
+	var errors serrors.List
+	for i, w := range want {
+		err := comparePkts(got, w, normalizeFn, only...)
+		if err == nil {
+			return i, nil
+		}
+		errors = append(errors, serrors.Wrap("packet mismatch", err, "want", i))
+	}
+	return -1, errors.ToError()
+}
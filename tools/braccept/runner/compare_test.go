@@ -19,7 +19,9 @@ import (
 	"time"
 
 	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/scionproto/scion/pkg/addr"
 	"github.com/scionproto/scion/pkg/private/util"
@@ -105,3 +107,155 @@ func prepareSCION(t *testing.T, diff string) gopacket.Packet {
 	packet := gopacket.NewPacket(input.Bytes(), slayers.LayerTypeSCION, gopacket.Default)
 	return packet
 }
+
+// TestComparePktNormalized checks that the default normalization keeps the
+// path pointers in the comparison.
+func TestComparePktNormalized(t *testing.T) {
+	layers.RegisterUDPPortLayerType(layers.UDPPort(30001), slayers.LayerTypeSCION)
+	decode := func(raw []byte) gopacket.Packet {
+		return gopacket.NewPacket(raw, layers.LinkTypeEthernet, gopacket.Default)
+	}
+	setCurrHF := func(scionL *slayers.SCION, _ []byte) []byte {
+		scionL.Path.(*scion.Decoded).PathMeta.CurrHF = 0
+		return nil
+	}
+
+	want := decode(prepareInput(t, nil))
+	assert.NoError(t, comparePkts(decode(prepareInput(t, nil)), want, DefaultNormalizePacket))
+	err := comparePkts(decode(prepareInput(t, setCurrHF)), want, DefaultNormalizePacket)
+	assert.ErrorContains(t, err, "CurrHF")
+}
+
+// TestComparePktNormalizedIPv6 checks that the default normalization ignores
+// the flow label of the IPv6 underlay.
+func TestComparePktNormalizedIPv6(t *testing.T) {
+	layers.RegisterUDPPortLayerType(layers.UDPPort(30001), slayers.LayerTypeSCION)
+	decode := func(raw []byte) gopacket.Packet {
+		return gopacket.NewPacket(raw, layers.LinkTypeEthernet, gopacket.Default)
+	}
+
+	want := decode(toIPv6(t, prepareInput(t, nil), 0))
+	got := decode(toIPv6(t, prepareInput(t, nil), 0x12345))
+	assert.Error(t, comparePkts(got, want, nil))
+	assert.NoError(t, comparePkts(got, want, DefaultNormalizePacket))
+}
+
+// TestComparePktNormalizedVLAN checks that the default normalization applies
+// to the underlay of 802.1Q tagged frames, and that the tag itself is
+// compared.
+func TestComparePktNormalizedVLAN(t *testing.T) {
+	layers.RegisterUDPPortLayerType(layers.UDPPort(30001), slayers.LayerTypeSCION)
+	decode := func(raw []byte) gopacket.Packet {
+		return gopacket.NewPacket(raw, layers.LinkTypeEthernet, gopacket.Default)
+	}
+
+	want := decode(toVLAN(t, prepareInput(t, nil), 10, 1))
+	require.NotNil(t, want.Layer(layers.LayerTypeDot1Q))
+	got := decode(toVLAN(t, prepareInput(t, nil), 10, 2))
+	assert.Error(t, comparePkts(got, want, nil))
+	assert.NoError(t, comparePkts(got, want, DefaultNormalizePacket))
+
+	err := comparePkts(decode(toVLAN(t, prepareInput(t, nil), 20, 1)), want,
+		DefaultNormalizePacket)
+	assert.ErrorContains(t, err, "Dot1Q")
+	assert.Error(t, comparePkts(decode(prepareInput(t, nil)), want, DefaultNormalizePacket))
+}
+
+// TestComparePktIgnoreSCIONFlowID checks that packets that only differ in the
+// SCION flow ID are equal after chaining IgnoreSCIONFlowID after the default
+// normalization.
+func TestComparePktIgnoreSCIONFlowID(t *testing.T) {
+	layers.RegisterUDPPortLayerType(layers.UDPPort(30001), slayers.LayerTypeSCION)
+	decode := func(raw []byte) gopacket.Packet {
+		return gopacket.NewPacket(raw, layers.LinkTypeEthernet, gopacket.Default)
+	}
+	setFlowID := func(scionL *slayers.SCION, _ []byte) []byte {
+		scionL.FlowID = 0xbeef
+		return nil
+	}
+	normalize := ChainNormalizePacket(DefaultNormalizePacket, IgnoreSCIONFlowID)
+
+	want := decode(prepareInput(t, nil))
+	err := comparePkts(decode(prepareInput(t, setFlowID)), want, DefaultNormalizePacket)
+	assert.ErrorContains(t, err, "FlowID")
+	assert.NoError(t, comparePkts(decode(prepareInput(t, setFlowID)), want, normalize))
+}
+
+// TestComparePktCompareLayers checks that only the selected layers are
+// compared, and that they must be present in both packets.
+func TestComparePktCompareLayers(t *testing.T) {
+	layers.RegisterUDPPortLayerType(layers.UDPPort(30001), slayers.LayerTypeSCION)
+	decode := func(raw []byte) gopacket.Packet {
+		return gopacket.NewPacket(raw, layers.LinkTypeEthernet, gopacket.Default)
+	}
+	setFlowID := func(scionL *slayers.SCION, _ []byte) []byte {
+		scionL.FlowID = 0xbeef
+		return nil
+	}
+
+	want := decode(toVLAN(t, prepareInput(t, nil), 10, 1))
+	got := decode(toVLAN(t, prepareInput(t, nil), 20, 2))
+	assert.Error(t, comparePkts(got, want, nil))
+	assert.NoError(t, comparePkts(got, want, nil, slayers.LayerTypeSCION))
+
+	err := comparePkts(decode(prepareInput(t, setFlowID)), want, nil, slayers.LayerTypeSCION)
+	assert.ErrorContains(t, err, "FlowID")
+	err = comparePkts(got, want, nil, slayers.LayerTypeSCION, layers.LayerTypeIPv6)
+	assert.ErrorContains(t, err, "layer mismatch")
+
+	match, err := matchAny(got, []gopacket.Packet{decode(prepareInput(t, setFlowID)), want},
+		nil, slayers.LayerTypeSCION)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, match)
+}
+
+func TestMatchAny(t *testing.T) {
+	a := prepareSCION(t, "172.168.1.1")
+	b := prepareSCION(t, "172.168.1.2")
+	c := prepareSCION(t, "172.168.1.3")
+
+	testCases := map[string]struct {
+		got         gopacket.Packet
+		want        []gopacket.Packet
+		match       int
+		errContains string
+	}{
+		"nothing expected": {
+			got:         a,
+			match:       -1,
+			errContains: "no packet expected",
+		},
+		"single match": {
+			got:  a,
+			want: []gopacket.Packet{a},
+		},
+		"single mismatch": {
+			got:         a,
+			want:        []gopacket.Packet{b},
+			match:       -1,
+			errContains: "layer mismatch",
+		},
+		"any order": {
+			got:   a,
+			want:  []gopacket.Packet{b, c, a},
+			match: 2,
+		},
+		"no match": {
+			got:         a,
+			want:        []gopacket.Packet{b, c},
+			match:       -1,
+			errContains: "packet mismatch",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			match, err := matchAny(tc.got, tc.want, nil)
+			assert.Equal(t, tc.match, match)
+			if tc.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.errContains)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"os"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+// Manifest lists the names of the cases to run. It is stored as a YAML or
+// JSON file, e.g.:
+//
+//	cases:
+//	  - ParentToChild
+//	  - ChildToParent
+type Manifest struct {
+	Cases []string `yaml:"cases"`
+}
+
+// LoadManifest loads the manifest from the YAML or JSON file.
+func LoadManifest(file string) (Manifest, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return Manifest{}, serrors.Wrap("reading manifest", err, "file", file)
+	}
+	var m Manifest
+	if err := yaml.UnmarshalStrict(raw, &m); err != nil {
+		return Manifest{}, serrors.Wrap("parsing manifest", err, "file", file)
+	}
+	if len(m.Cases) == 0 {
+		return Manifest{}, serrors.New("manifest lists no cases", "file", file)
+	}
+	return m, nil
+}
+
+// SelectManifest returns the cases that are listed in the manifest, in the
+// order of cases, and the number of cases that are not listed. It fails if the
+// manifest lists a name that does not belong to any of the cases.
+func SelectManifest(cases []Case, m Manifest) ([]Case, int, error) {
+	listed := make(map[string]bool, len(m.Cases))
+	for _, name := range m.Cases {
+		listed[name] = false
+	}
+	var selected []Case
+	for _, c := range cases {
+		if _, ok := listed[c.Name]; ok {
+			listed[c.Name] = true
+			selected = append(selected, c)
+		}
+	}
+	var unknown []string
+	for _, name := range m.Cases {
+		if !listed[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, 0, serrors.New("manifest lists unknown cases",
+			"cases", strings.Join(unknown, ", "))
+	}
+	return selected, len(cases) - len(selected), nil
+}
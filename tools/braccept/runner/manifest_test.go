@@ -0,0 +1,110 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadManifest(t *testing.T) {
+	testCases := map[string]struct {
+		Content   string
+		Cases     []string
+		Assertion assert.ErrorAssertionFunc
+	}{
+		"yaml": {
+			Content:   "cases:\n  - ParentToChild\n  - SCMPBadMAC\n",
+			Cases:     []string{"ParentToChild", "SCMPBadMAC"},
+			Assertion: assert.NoError,
+		},
+		"json": {
+			Content:   `{"cases": ["ParentToChild", "SCMPBadMAC"]}`,
+			Cases:     []string{"ParentToChild", "SCMPBadMAC"},
+			Assertion: assert.NoError,
+		},
+		"unknown field": {
+			Content:   "case:\n  - ParentToChild\n",
+			Assertion: assert.Error,
+		},
+		"empty": {
+			Content:   "cases: []\n",
+			Assertion: assert.Error,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "manifest.yml")
+			require.NoError(t, os.WriteFile(file, []byte(tc.Content), 0o644))
+			m, err := LoadManifest(file)
+			tc.Assertion(t, err)
+			assert.Equal(t, tc.Cases, m.Cases)
+		})
+	}
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadManifest(filepath.Join(t.TempDir(), "missing.yml"))
+		assert.Error(t, err)
+	})
+}
+
+func TestSelectManifest(t *testing.T) {
+	cases := []Case{
+		{Name: "ParentToChild"},
+		{Name: "SCMPTracerouteIngress"},
+		{Name: "SCMPTracerouteEgress"},
+	}
+	names := func(cases []Case) []string {
+		var r []string
+		for _, c := range cases {
+			r = append(r, c.Name)
+		}
+		return r
+	}
+	testCases := map[string]struct {
+		Manifest  Manifest
+		Selected  []string
+		Skipped   int
+		Assertion assert.ErrorAssertionFunc
+	}{
+		"registration order": {
+			Manifest:  Manifest{Cases: []string{"SCMPTracerouteEgress", "ParentToChild"}},
+			Selected:  []string{"ParentToChild", "SCMPTracerouteEgress"},
+			Skipped:   1,
+			Assertion: assert.NoError,
+		},
+		"duplicate": {
+			Manifest:  Manifest{Cases: []string{"ParentToChild", "ParentToChild"}},
+			Selected:  []string{"ParentToChild"},
+			Skipped:   2,
+			Assertion: assert.NoError,
+		},
+		"unknown": {
+			Manifest:  Manifest{Cases: []string{"ParentToChild", "SCMPTraceroute"}},
+			Assertion: assert.Error,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			selected, skipped, err := SelectManifest(cases, tc.Manifest)
+			tc.Assertion(t, err)
+			assert.Equal(t, tc.Selected, names(selected))
+			assert.Equal(t, tc.Skipped, skipped)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"cmp"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+// Result is the result of running a case.
+type Result struct {
+	Name string
+	// Skipped is set if the case was not run. Reason then contains the reason
+	// for skipping it.
+	Skipped bool
+	Reason  string
+	// Duration is the time it took to run the case.
+	Duration time.Duration
+	// Err is the error returned by the case. It contains the differences
+	// between the expected and the captured packets. A nil Err means that the
+	// case passed.
+	Err error
+}
+
+// Slowest returns the n results of the cases that took the longest to run,
+// slowest first. Skipped cases are ignored. Results with the same duration
+// keep their order.
+func Slowest(results []Result, n int) []Result {
+	var run []Result
+	for _, r := range results {
+		if !r.Skipped {
+			run = append(run, r)
+		}
+	}
+	slices.SortStableFunc(run, func(a, b Result) int {
+		return cmp.Compare(b.Duration, a.Duration)
+	})
+	return run[:min(n, len(run))]
+}
+
+type jsonResult struct {
+	Name     string  `json:"name"`
+	Passed   bool    `json:"passed"`
+	Skipped  bool    `json:"skipped,omitempty"`
+	Reason   string  `json:"reason,omitempty"`
+	Duration float64 `json:"duration_seconds"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// WriteResults writes the results as a JSON array to the file. The file is
+// replaced atomically, so that readers never see partial results.
+func WriteResults(file string, results []Result) error {
+	out := make([]jsonResult, 0, len(results))
+	for _, r := range results {
+		jr := jsonResult{
+			Name:     r.Name,
+			Passed:   !r.Skipped && r.Err == nil,
+			Skipped:  r.Skipped,
+			Reason:   r.Reason,
+			Duration: r.Duration.Seconds(),
+		}
+		if r.Err != nil {
+			jr.Error = r.Err.Error()
+		}
+		out = append(out, jr)
+	}
+	raw, err := json.MarshalIndent(out, "", "    ")
+	if err != nil {
+		return serrors.Wrap("encoding results", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*")
+	if err != nil {
+		return serrors.Wrap("creating temporary file", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(raw); err != nil {
+		f.Close()
+		return serrors.Wrap("writing results", err, "file", f.Name())
+	}
+	if err := f.Close(); err != nil {
+		return serrors.Wrap("closing results", err, "file", f.Name())
+	}
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		return serrors.Wrap("setting file mode", err, "file", f.Name())
+	}
+	if err := os.Rename(f.Name(), file); err != nil {
+		return serrors.Wrap("renaming results", err, "file", file)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+func TestWriteResults(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "results.json")
+	require.NoError(t, os.WriteFile(file, []byte("stale"), 0o644))
+
+	results := []Result{
+		{Name: "ParentToChild", Duration: 1500 * time.Millisecond},
+		{Name: "SCMPBadMAC", Err: serrors.New("layer mismatch")},
+		{Name: "ParentToChildEPIC", Skipped: true, Reason: "EPIC not supported"},
+	}
+	require.NoError(t, WriteResults(file, results))
+
+	raw, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"name": "ParentToChild", "passed": true, "duration_seconds": 1.5},
+		{"name": "SCMPBadMAC", "passed": false, "duration_seconds": 0,
+			"error": "layer mismatch"},
+		{"name": "ParentToChildEPIC", "passed": false, "skipped": true,
+			"reason": "EPIC not supported", "duration_seconds": 0}
+	]`, string(raw))
+
+	// No temporary files are left behind.
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestWriteResultsMissingDir(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "missing", "results.json")
+	assert.Error(t, WriteResults(file, nil))
+}
+
+func TestSlowest(t *testing.T) {
+	results := []Result{
+		{Name: "A", Duration: 2 * time.Second},
+		{Name: "B", Duration: 5 * time.Second},
+		{Name: "C", Skipped: true},
+		{Name: "D", Duration: time.Second},
+		{Name: "E", Duration: 5 * time.Second, Err: serrors.New("timeout")},
+	}
+	names := func(rs []Result) []string {
+		var n []string
+		for _, r := range rs {
+			n = append(n, r.Name)
+		}
+		return n
+	}
+	assert.Equal(t, []string{"B", "E", "A"}, names(Slowest(results, 3)))
+	assert.Equal(t, []string{"B", "E", "A", "D"}, names(Slowest(results, 10)))
+	assert.Empty(t, Slowest(results, 0))
+	// The input is not modified.
+	assert.Equal(t, "A", results[0].Name)
+}
@@ -21,16 +21,15 @@ import (
 	"errors"
 	"fmt"
 	"net"
-	"os"
-	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gopacket/gopacket"
 	"github.com/gopacket/gopacket/afpacket"
 	"github.com/gopacket/gopacket/layers"
-	"github.com/gopacket/gopacket/pcapgo"
 
 	"github.com/scionproto/scion/pkg/log"
 	"github.com/scionproto/scion/pkg/private/common"
@@ -39,12 +38,25 @@ import (
 
 var errTimeout = serrors.New("timeout")
 
+// subscriberBuffer is the number of captured packets that are buffered for a
+// running case if cases run in parallel.
+const subscriberBuffer = 64
+
 // RunConfig contains handles to all devices used in the acceptance test and
 // should be used to read/write from devices.
 type RunConfig struct {
 	deviceNames []string
 	handles     map[string]*afpacket.TPacket
 	packetChans []reflect.SelectCase
+	// StoreAlways selects whether the packets of a case are stored in its
+	// artifact directory even if the case passes. By default, only the packets
+	// of failing cases are stored.
+	StoreAlways bool
+
+	mu sync.Mutex
+	// subscribers maps the devices to the channels of the running cases that
+	// use them. It is nil, unless parallel runs are enabled.
+	subscribers map[string]chan gopacket.Packet
 }
 
 // NewRunConfig creates a new run configuration. After usage Close should be
@@ -75,9 +87,6 @@ func NewRunConfig() (*RunConfig, error) {
 			Chan: reflect.ValueOf(ch),
 		})
 	}
-	// allocate space for timer channel
-	packetChans = append(packetChans, reflect.SelectCase{})
-
 	return &RunConfig{
 		deviceNames: deviceNames,
 		handles:     handles,
@@ -94,39 +103,177 @@ func (c *RunConfig) WritePacket(devName string, pkt []byte) error {
 	return writePktTo.WritePacketData(pkt)
 }
 
+// checkDevices checks that all devices are provisioned. Without this check, a
+// case that reads from a missing device would only fail with a timeout.
+func (c *RunConfig) checkDevices(devs ...string) error {
+	var missing []string
+	for _, dev := range devs {
+		if _, ok := c.handles[dev]; !ok && !slices.Contains(missing, dev) {
+			missing = append(missing, dev)
+		}
+	}
+	if len(missing) > 0 {
+		return serrors.New("device not provisioned", "devices", missing,
+			"available", c.deviceNames)
+	}
+	return nil
+}
+
 // ExpectedPacket fully describes a packet to be expected. To expect an empty
 // packet a nil Pkt value can be used.
 type ExpectedPacket struct {
-	Storer            packetStorer
+	Storer            *packetStorer
 	DevName           string
 	Timeout           time.Duration
 	IgnoreNonMatching bool
 	Pkt               gopacket.Packet
+	// Pkts, if set, are expected in any order instead of Pkt.
+	Pkts []gopacket.Packet
+	// DevNames, if set, are the devices the packets are expected on instead
+	// of DevName. Each packet may arrive on any of them.
+	DevNames []string
+	// Sent is the time the input packet was sent. It is the reference for
+	// MinDelay and MaxDelay, which bound the capture time of the expected
+	// packet if they are set.
+	Sent               time.Time
+	MinDelay, MaxDelay time.Duration
+	// CompareLayers, if set, restricts the comparison to the layers of the
+	// given types.
+	CompareLayers []gopacket.LayerType
+}
+
+// wanted returns the expected packets.
+func (p ExpectedPacket) wanted() []gopacket.Packet {
+	if len(p.Pkts) > 0 {
+		return p.Pkts
+	}
+	if p.Pkt != nil {
+		return []gopacket.Packet{p.Pkt}
+	}
+	return nil
+}
+
+// devices returns the devices the packets are expected on.
+func (p ExpectedPacket) devices() []string {
+	if len(p.DevNames) > 0 {
+		return p.DevNames
+	}
+	return []string{p.DevName}
+}
+
+// EnableParallel allows cases that use distinct devices to run concurrently.
+// The captured packets are dispatched to the running case that uses the
+// device they were captured on. Packets captured on a device that no running
+// case uses are dropped. Therefore, a case only detects packets on unexpected
+// devices among the devices it uses, unless it expects no packet at all.
+func (c *RunConfig) EnableParallel() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.subscribers != nil {
+		return
+	}
+	c.subscribers = make(map[string]chan gopacket.Packet)
+	for i, name := range c.deviceNames {
+		pkts, ok := c.packetChans[i].Chan.Interface().(chan gopacket.Packet)
+		if !ok {
+			log.Error("Unexpected packet channel", "device", name)
+			continue
+		}
+		go func() {
+			defer log.HandlePanic()
+			for pkt := range pkts {
+				c.mu.Lock()
+				ch := c.subscribers[name]
+				c.mu.Unlock()
+				if ch == nil {
+					log.Debug("Dropping packet captured on unused device", "device", name)
+					continue
+				}
+				select {
+				case ch <- pkt:
+				default:
+					log.Debug("Dropping packet, buffer full", "device", name)
+				}
+			}
+		}()
+	}
+}
+
+// capture contains the packet channels of the devices a case listens on.
+type capture struct {
+	deviceNames []string
+	packetChans []reflect.SelectCase
+	release     func()
 }
 
-// ExpectPacket expects packet pkt on the device devName. It stores all received
-// packets using the storer. If the received packet in the device is matching
-// the expected packet and no other packet is received nil is returned.
+// subscribe returns the packet channels for the devices. If parallel runs are
+// not enabled, the channels of all devices are returned. release must be
+// called once the packets are no longer read.
+func (c *RunConfig) subscribe(devs ...string) *capture {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.subscribers == nil {
+		return &capture{
+			deviceNames: c.deviceNames,
+			packetChans: slices.Clone(c.packetChans),
+			release:     func() {},
+		}
+	}
+	cp := &capture{}
+	for _, dev := range devs {
+		if slices.Contains(cp.deviceNames, dev) {
+			continue
+		}
+		ch := make(chan gopacket.Packet, subscriberBuffer)
+		c.subscribers[dev] = ch
+		cp.deviceNames = append(cp.deviceNames, dev)
+		cp.packetChans = append(cp.packetChans, reflect.SelectCase{
+			Dir:  reflect.SelectRecv,
+			Chan: reflect.ValueOf(ch),
+		})
+	}
+	cp.release = func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, dev := range cp.deviceNames {
+			delete(c.subscribers, dev)
+		}
+	}
+	return cp
+}
+
+// ExpectPacket expects packet pkt on the device DevName, or on any of the
+// devices DevNames. It stores all received packets using the storer. If the
+// received packets in the devices are matching the expected packets and no
+// other packet is received nil is returned.
 // Otherwise details of what went wrong are returned in the error.
 func (c *RunConfig) ExpectPacket(pkt ExpectedPacket, normalizeFn NormalizePacketFn) error {
+	cp := c.subscribe(pkt.devices()...)
+	defer cp.release()
+	return cp.expectPacket(pkt, normalizeFn)
+}
+
+func (c *capture) expectPacket(pkt ExpectedPacket, normalizeFn NormalizePacketFn) error {
 	timerCh := time.After(pkt.Timeout)
-	c.packetChans[len(c.deviceNames)] = reflect.SelectCase{
+	packetChans := append(slices.Clone(c.packetChans), reflect.SelectCase{
 		Dir:  reflect.SelectRecv,
 		Chan: reflect.ValueOf(timerCh),
-	}
+	})
+	remaining := slices.Clone(pkt.wanted())
 	var errors serrors.List
 	for i := 0; ; i++ {
-		idx, pktV, ok := reflect.Select(c.packetChans)
+		idx, pktV, ok := reflect.Select(packetChans)
 		if !ok {
 			return serrors.New("unexpected device closed", "device", c.deviceNames[idx])
 		}
-		if idx == len(c.packetChans)-1 {
+		if idx == len(packetChans)-1 {
 			// No packet expected return errors if there are any.
-			if pkt.Pkt == nil {
+			if len(pkt.wanted()) == 0 {
 				return errors.ToError()
 			}
 			// Timeout receiving packets
-			return serrors.Join(errTimeout, nil, "other err", errors.ToError())
+			return serrors.Join(errTimeout, nil, "missing", len(remaining),
+				"other err", errors.ToError())
 		}
 		got, ok := pktV.Interface().(gopacket.Packet)
 		if !ok {
@@ -136,22 +283,33 @@ func (c *RunConfig) ExpectPacket(pkt ExpectedPacket, normalizeFn NormalizePacket
 		}
 		pkt.Storer.storePkt(fmt.Sprintf("got-%d", i), got)
 		// Packet received
-		if c.deviceNames[idx] != pkt.DevName {
+		if !slices.Contains(pkt.devices(), c.deviceNames[idx]) {
 			errors = append(errors, serrors.New("received packet on unexpected interface",
-				"pkt", i, "expected", pkt.DevName, "actual", c.deviceNames[idx], "packet", got))
+				"pkt", i, "expected", pkt.devices(), "actual", c.deviceNames[idx],
+				"packet", got))
 			continue
 		}
-		if err := got.ErrorLayer(); err != nil {
-			errors = append(errors, serrors.Wrap("error decoding packet", err.Error(),
-				"pkt", i))
+		if err := decodeError(got); err != nil {
+			errors = append(errors, serrors.Wrap("invalid packet", err, "pkt", i))
 			continue
 		}
-		if err := comparePkts(got, pkt.Pkt, normalizeFn); err != nil {
+		match, err := matchAny(got, remaining, normalizeFn, pkt.CompareLayers...)
+		if err != nil {
 			errors = append(errors, serrors.Wrap("received mismatching packet", err,
 				"pkt", i))
 			continue
 		}
+		if err := checkResponseDelay(captureTime(got).Sub(pkt.Sent),
+			pkt.MinDelay, pkt.MaxDelay); err != nil {
+			errors = append(errors, serrors.Wrap("response delay out of bounds", err,
+				"pkt", i))
+			continue
+		}
 		// match found
+		remaining = slices.Delete(remaining, match, match+1)
+		if len(remaining) > 0 {
+			continue
+		}
 		if pkt.IgnoreNonMatching {
 			return nil
 		}
@@ -159,6 +317,117 @@ func (c *RunConfig) ExpectPacket(pkt ExpectedPacket, normalizeFn NormalizePacket
 	}
 }
 
+// captureTime returns the time the packet was captured. If the capture
+// timestamp is not available, the current time is returned.
+func captureTime(pkt gopacket.Packet) time.Time {
+	if md := pkt.Metadata(); md != nil && !md.Timestamp.IsZero() {
+		return md.Timestamp
+	}
+	return time.Now()
+}
+
+// BenchResult contains the results of a runner benchmark.
+type BenchResult struct {
+	// Sent is the number of injected packets.
+	Sent int
+	// Received is the number of packets captured on the read device.
+	Received int
+	// Bytes is the number of injected bytes.
+	Bytes int
+	// Duration is the time from the first injected packet until the last
+	// packet was captured.
+	Duration time.Duration
+}
+
+// SentPPS returns the injection rate in packets per second.
+func (r BenchResult) SentPPS() float64 {
+	return float64(r.Sent) / r.Duration.Seconds()
+}
+
+// ReceivedPPS returns the capture rate in packets per second.
+func (r BenchResult) ReceivedPPS() float64 {
+	return float64(r.Received) / r.Duration.Seconds()
+}
+
+// Throughput returns the injection throughput in bits per second.
+func (r BenchResult) Throughput() float64 {
+	return float64(8*r.Bytes) / r.Duration.Seconds()
+}
+
+// Bench measures the throughput of the runner itself. It injects count copies
+// of the input packet of the case on the device WriteTo and concurrently
+// captures the packets on the device ReadFrom. Captured packets are counted,
+// but not compared. The benchmark ends when all packets have been captured or
+// when no packet has been captured for the case timeout after the last packet
+// was injected.
+func (c *RunConfig) Bench(t Case, count int) (BenchResult, error) {
+	if err := c.checkDevices(t.WriteTo, t.ReadFrom); err != nil {
+		return BenchResult{}, err
+	}
+	idx := -1
+	for i, name := range c.deviceNames {
+		if name == t.ReadFrom {
+			idx = i
+		}
+	}
+	if idx == -1 {
+		return BenchResult{}, serrors.New("device not found", "device", t.ReadFrom)
+	}
+	pkts, ok := c.packetChans[idx].Chan.Interface().(chan gopacket.Packet)
+	if !ok {
+		return BenchResult{}, serrors.New("unexpected packet channel", "device", t.ReadFrom)
+	}
+
+	sendDone := make(chan time.Time, 1)
+	sendErr := make(chan error, 1)
+	start := time.Now()
+	go func() {
+		defer log.HandlePanic()
+		for i := 0; i < count; i++ {
+			if err := c.WritePacket(t.WriteTo, t.Input); err != nil {
+				sendErr <- serrors.Wrap("writing input packet", err, "sent", i)
+				return
+			}
+		}
+		sendDone <- time.Now()
+	}()
+
+	res := BenchResult{Sent: count, Bytes: count * len(t.Input)}
+	last := start
+	var idle <-chan time.Time
+	for res.Received < count {
+		select {
+		case err := <-sendErr:
+			return BenchResult{}, err
+		case end := <-sendDone:
+			last = maxTime(last, end)
+			idle = time.After(caseTimeout)
+		case _, ok := <-pkts:
+			if !ok {
+				return BenchResult{}, serrors.New("unexpected device closed",
+					"device", t.ReadFrom)
+			}
+			res.Received++
+			last = maxTime(last, time.Now())
+			if idle != nil {
+				idle = time.After(caseTimeout)
+			}
+		case <-idle:
+			res.Duration = last.Sub(start)
+			return res, nil
+		}
+	}
+	res.Duration = last.Sub(start)
+	return res, nil
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
 // Close tears down the state.
 func (c *RunConfig) Close() {
 	for _, tp := range c.handles {
@@ -173,36 +442,82 @@ func (c *RunConfig) Close() {
 }
 
 // Run executes a test case. It writes input pkt to interface `WriteTo` and
-// listens for want pkt in interface `ReadFrom`. It stores all the packets
-// in the artifact directory for further debug.
-func (t *Case) Run(cfg *RunConfig) error {
-	storer := packetStorer{
+// listens for the wanted pkts in interface `ReadFrom`, or in all interfaces of
+// `ReadFromMulti`. All interfaces must be provisioned, even if they differ.
+// If the case fails, or if StoreAlways is set in the configuration, it stores
+// all the packets in the artifact directory for further debug.
+func (t *Case) Run(cfg *RunConfig) (err error) {
+	if t.Prepare != nil {
+		t.Prepare(t)
+	}
+	storer := &packetStorer{
 		StoreDir: t.StoreDir,
 		TestName: t.Name,
 	}
+	defer func() {
+		if err != nil || cfg.StoreAlways {
+			storer.flush()
+		}
+	}()
 	inputPkt := gopacket.NewPacket(t.Input, layers.LinkTypeEthernet, gopacket.Default)
-	defer storer.storePkt("input", inputPkt)
-	var wantPkt gopacket.Packet
-	if t.Want != nil {
-		wantPkt = gopacket.NewPacket(t.Want, layers.LinkTypeEthernet, gopacket.Default)
-		defer storer.storePkt("want", wantPkt)
+	storer.storePkt("input", inputPkt)
+	for i, frag := range t.InputFragments {
+		fragPkt := gopacket.NewPacket(frag, layers.LinkTypeEthernet, gopacket.Default)
+		storer.storePkt(fmt.Sprintf("input-frag-%d", i), fragPkt)
+	}
+	var wantPkts []gopacket.Packet
+	for i, want := range t.wants() {
+		wantPkt := gopacket.NewPacket(want, layers.LinkTypeEthernet, gopacket.Default)
+		if len(t.WantMulti) > 0 {
+			storer.storePkt(fmt.Sprintf("want-%d", i), wantPkt)
+		} else {
+			storer.storePkt("want", wantPkt)
+		}
+		wantPkts = append(wantPkts, wantPkt)
+	}
+
+	if err := t.validateConfig(); err != nil {
+		return err
+	}
+	devs := append([]string{t.WriteTo}, t.readDevices()...)
+	if err := cfg.checkDevices(devs...); err != nil {
+		return err
+	}
+	// Cases that expect no packet at all make sure that no packet is captured
+	// on any device.
+	if len(wantPkts) == 0 {
+		devs = cfg.deviceNames
 	}
+	cp := cfg.subscribe(devs...)
+	defer cp.release()
 
-	if err := cfg.WritePacket(t.WriteTo, t.Input); err != nil {
+	sent := time.Now()
+	if len(t.InputFragments) > 0 {
+		for i, frag := range t.InputFragments {
+			if err := cfg.WritePacket(t.WriteTo, frag); err != nil {
+				return serrors.Wrap("writing input fragment", err, "fragment", i)
+			}
+		}
+	} else if err := cfg.WritePacket(t.WriteTo, t.Input); err != nil {
 		return serrors.Wrap("writing input packet", err)
 	}
 	ePkt := ExpectedPacket{
 		Storer:            storer,
 		DevName:           t.ReadFrom,
-		Timeout:           350 * time.Millisecond,
+		DevNames:          t.ReadFromMulti,
+		Timeout:           t.readTimeout(),
 		IgnoreNonMatching: t.IgnoreNonMatching,
-		Pkt:               wantPkt,
+		Pkts:              wantPkts,
+		Sent:              sent,
+		MinDelay:          t.MinResponseDelay,
+		MaxDelay:          t.MaxResponseDelay,
+		CompareLayers:     t.CompareLayers,
 	}
 	normalizePacket := t.NormalizePacket
 	if normalizePacket == nil {
 		normalizePacket = DefaultNormalizePacket
 	}
-	err := cfg.ExpectPacket(ePkt, normalizePacket)
+	err = cp.expectPacket(ePkt, normalizePacket)
 	if err == nil {
 		return nil
 	}
@@ -212,41 +527,3 @@ func (t *Case) Run(cfg *RunConfig) error {
 	return serrors.Wrap("Errors were found", err,
 		"Packets are stored in", t.StoreDir)
 }
-
-type packetStorer struct {
-	StoreDir string
-	TestName string
-}
-
-func (s *packetStorer) storePkt(fileName string, packet gopacket.Packet) {
-	if err := os.MkdirAll(s.StoreDir, os.ModePerm); err != nil {
-		log.Error(s.TestName, "err", err)
-		return
-	}
-	filename := filepath.Join(s.StoreDir, fileName+".pcap")
-	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY, 0o600)
-	if err != nil {
-		log.Error(s.TestName, "err", err)
-		return
-	}
-	defer func() {
-		err := f.Close()
-		if err != nil {
-			log.Error(s.TestName, "err", err)
-		}
-	}()
-
-	w := pcapgo.NewWriter(f)
-	if err := w.WriteFileHeader(1024, layers.LinkTypeEthernet); err != nil {
-		log.Error(s.TestName, "err", err)
-		return
-	}
-	c := gopacket.CaptureInfo{
-		Length:        len(packet.Data()),
-		CaptureLength: len(packet.Data()),
-	}
-	if err := w.WritePacket(c, packet.Data()); err != nil {
-		log.Error(s.TestName, "err", err)
-		return
-	}
-}
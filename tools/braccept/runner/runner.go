@@ -15,13 +15,27 @@
 
 package runner
 
-import "github.com/gopacket/gopacket"
+import (
+	"regexp"
+	"time"
+
+	"github.com/gopacket/gopacket"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+// caseTimeout is the default time to wait for the expected packet of a case.
+const caseTimeout = 350 * time.Millisecond
 
 type NormalizePacketFn func(gopacket.Packet)
 
 // Case represents a border router test case.
 type Case struct {
-	Name              string
+	Name string
+	// WriteTo is the device the input packet is written to, and ReadFrom is
+	// the device the expected packets are captured on. They differ for cases
+	// that forward from one interface to another. Both devices must be
+	// provisioned by the test setup, otherwise the case fails.
 	WriteTo, ReadFrom string
 	Input, Want       []byte
 	StoreDir          string
@@ -30,4 +44,135 @@ type Case struct {
 	// expected packet. It can modify the packet fields so that unpredictable
 	// values are zeroed out and the packets match.
 	NormalizePacket NormalizePacketFn
+	// InputMayBeMalformed marks cases whose input packet is malformed on
+	// purpose. The input of such cases is not validated by ValidateInput.
+	InputMayBeMalformed bool
+	// InputFragments, if set, are written to WriteTo in order instead of
+	// Input. This is used for packets that are fragmented in the underlay.
+	// Input then contains the unfragmented packet, which is validated and
+	// stored for reference.
+	InputFragments [][]byte
+	// MinResponseDelay and MaxResponseDelay bound the time between sending
+	// the input packet and capturing the expected packet. A zero value
+	// disables the respective bound.
+	MinResponseDelay, MaxResponseDelay time.Duration
+	// Prepare, if set, is called right before the input packet is sent. It
+	// can update the packets of the case, e.g., to refresh timestamps that
+	// are only valid for a short time.
+	Prepare func(*Case)
+	// SkipIf, if set, is called before the case is run. If it returns true,
+	// the case is skipped for the returned reason. This is used for cases that
+	// depend on optional router features.
+	SkipIf func() (bool, string)
+	// WantMulti, if set, contains the packets that are expected on ReadFrom
+	// instead of Want. The packets may arrive in any order. This is used for
+	// inputs that legitimately produce several packets.
+	WantMulti [][]byte
+	// ReadFromMulti, if set, contains the devices the expected packets are
+	// captured on instead of ReadFrom. Each expected packet may arrive on any
+	// of the devices. This is used for inputs that are delivered on several
+	// devices.
+	ReadFromMulti []string
+	// CompareLayers, if set, restricts the comparison of the captured and the
+	// expected packets to the decoded layers of the given types, e.g., to
+	// slayers.LayerTypeSCION for cases that only check the SCION header and
+	// not the underlay. The layers must be present in both packets. Only the
+	// contents of a layer are compared, not its payload, so the layers of the
+	// payload that matter, e.g., the SCMP layers, must be listed as well.
+	CompareLayers []gopacket.LayerType
+	// Timeout, if non-zero, overrides the default time to wait for the
+	// expected packet. Cases that involve slow control-plane interactions,
+	// e.g., BFD bootstrapping, can use it to get more headroom.
+	Timeout time.Duration
+}
+
+// Skip reports whether the case should be skipped, and why.
+func (t *Case) Skip() (bool, string) {
+	if t.SkipIf == nil {
+		return false, ""
+	}
+	return t.SkipIf()
+}
+
+// wants returns the packets that are expected on ReadFrom.
+func (t *Case) wants() [][]byte {
+	if len(t.WantMulti) > 0 {
+		return t.WantMulti
+	}
+	if t.Want != nil {
+		return [][]byte{t.Want}
+	}
+	return nil
+}
+
+// readDevices returns the devices the expected packets are captured on.
+func (t *Case) readDevices() []string {
+	if len(t.ReadFromMulti) > 0 {
+		return t.ReadFromMulti
+	}
+	if t.ReadFrom != "" {
+		return []string{t.ReadFrom}
+	}
+	return nil
+}
+
+// validateConfig checks that the settings of the case are consistent.
+func (t *Case) validateConfig() error {
+	if t.Want != nil && len(t.WantMulti) > 0 {
+		return serrors.New("Want and WantMulti must not both be set")
+	}
+	if t.ReadFrom != "" && len(t.ReadFromMulti) > 0 {
+		return serrors.New("ReadFrom and ReadFromMulti must not both be set")
+	}
+	if t.Timeout < 0 {
+		return serrors.New("invalid timeout", "timeout", t.Timeout)
+	}
+	if t.MaxResponseDelay > 0 && t.MinResponseDelay > t.MaxResponseDelay {
+		return serrors.New("invalid response delay bounds",
+			"min", t.MinResponseDelay, "max", t.MaxResponseDelay)
+	}
+	return nil
+}
+
+// readTimeout returns the time to wait for the expected packet. The timeout
+// extends beyond the largest response delay bound, so that a late response is
+// reported as such rather than as a timeout.
+func (t *Case) readTimeout() time.Duration {
+	timeout := caseTimeout
+	if t.Timeout > 0 {
+		timeout = t.Timeout
+	}
+	return timeout + max(t.MinResponseDelay, t.MaxResponseDelay)
+}
+
+// checkResponseDelay checks that the delay is within the bounds. A zero bound
+// is not checked.
+func checkResponseDelay(delay, minDelay, maxDelay time.Duration) error {
+	if minDelay > 0 && delay < minDelay {
+		return serrors.New("response arrived too early", "delay", delay, "min", minDelay)
+	}
+	if maxDelay > 0 && delay > maxDelay {
+		return serrors.New("response arrived too late", "delay", delay, "max", maxDelay)
+	}
+	return nil
+}
+
+// Select returns the cases whose name matches the regular expression pattern,
+// and the number of cases that do not match. An empty pattern selects all
+// cases.
+func Select(cases []Case, pattern string) ([]Case, int, error) {
+	if pattern == "" {
+		return cases, 0, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, 0, serrors.Wrap("parsing case pattern", err, "pattern", pattern)
+	}
+	var selected []Case
+	for _, c := range cases {
+		if re.MatchString(c.Name) {
+			selected = append(selected, c)
+		}
+	}
+	return selected, len(cases) - len(selected), nil
 }
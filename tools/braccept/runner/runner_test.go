@@ -0,0 +1,170 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckResponseDelay(t *testing.T) {
+	testCases := map[string]struct {
+		Delay, Min, Max time.Duration
+		ErrContains     string
+	}{
+		"no bounds": {
+			Delay: time.Second,
+		},
+		"within bounds": {
+			Delay: 50 * time.Millisecond,
+			Min:   10 * time.Millisecond,
+			Max:   100 * time.Millisecond,
+		},
+		"on the bounds": {
+			Delay: 10 * time.Millisecond,
+			Min:   10 * time.Millisecond,
+			Max:   10 * time.Millisecond,
+		},
+		"too early": {
+			Delay:       5 * time.Millisecond,
+			Min:         10 * time.Millisecond,
+			ErrContains: "too early",
+		},
+		"too late": {
+			Delay:       150 * time.Millisecond,
+			Max:         100 * time.Millisecond,
+			ErrContains: "too late",
+		},
+		"only max": {
+			Delay: 0,
+			Max:   100 * time.Millisecond,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := checkResponseDelay(tc.Delay, tc.Min, tc.Max)
+			if tc.ErrContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.ErrContains)
+		})
+	}
+}
+
+func TestCaseReadTimeout(t *testing.T) {
+	testCases := map[string]struct {
+		Case Case
+		Want time.Duration
+	}{
+		"default": {
+			Want: caseTimeout,
+		},
+		"explicit": {
+			Case: Case{Timeout: 2 * time.Second},
+			Want: 2 * time.Second,
+		},
+		"delay bounds": {
+			Case: Case{MinResponseDelay: 50 * time.Millisecond},
+			Want: caseTimeout + 50*time.Millisecond,
+		},
+		"explicit and delay bounds": {
+			Case: Case{
+				Timeout:          time.Second,
+				MinResponseDelay: 50 * time.Millisecond,
+				MaxResponseDelay: 100 * time.Millisecond,
+			},
+			Want: time.Second + 100*time.Millisecond,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.Want, tc.Case.readTimeout())
+		})
+	}
+}
+
+func TestCaseSkip(t *testing.T) {
+	t.Run("no SkipIf", func(t *testing.T) {
+		c := Case{Name: "case"}
+		skip, reason := c.Skip()
+		assert.False(t, skip)
+		assert.Empty(t, reason)
+	})
+	t.Run("SkipIf", func(t *testing.T) {
+		for _, want := range []bool{true, false} {
+			c := Case{
+				Name:   "case",
+				SkipIf: func() (bool, string) { return want, "feature not supported" },
+			}
+			skip, reason := c.Skip()
+			assert.Equal(t, want, skip)
+			assert.Equal(t, "feature not supported", reason)
+		}
+	})
+}
+
+func TestSelect(t *testing.T) {
+	cases := []Case{
+		{Name: "ParentToChild"},
+		{Name: "SCMPTracerouteIngress"},
+		{Name: "SCMPTracerouteEgress"},
+	}
+	names := func(cases []Case) []string {
+		var r []string
+		for _, c := range cases {
+			r = append(r, c.Name)
+		}
+		return r
+	}
+	testCases := map[string]struct {
+		Pattern  string
+		Selected []string
+		Skipped  int
+	}{
+		"empty pattern": {
+			Selected: []string{"ParentToChild", "SCMPTracerouteIngress", "SCMPTracerouteEgress"},
+		},
+		"prefix": {
+			Pattern:  "^SCMPTraceroute",
+			Selected: []string{"SCMPTracerouteIngress", "SCMPTracerouteEgress"},
+			Skipped:  1,
+		},
+		"exact": {
+			Pattern:  "^SCMPTracerouteEgress$",
+			Selected: []string{"SCMPTracerouteEgress"},
+			Skipped:  2,
+		},
+		"no match": {
+			Pattern: "BFD",
+			Skipped: 3,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			selected, skipped, err := Select(cases, tc.Pattern)
+			require.NoError(t, err)
+			assert.Equal(t, tc.Selected, names(selected))
+			assert.Equal(t, tc.Skipped, skipped)
+		})
+	}
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, _, err := Select(cases, "(")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"slices"
+
+	"github.com/scionproto/scion/pkg/log"
+)
+
+// Schedule runs the cases with run, with up to n cases running concurrently.
+// Cases that use a common device are never run concurrently, and cases that
+// expect no packet at all are run on their own, because they listen on all
+// devices. The results are passed to report in the order of the cases,
+// independent of the order in which the cases complete. A non-positive n runs
+// the cases one after the other.
+func Schedule(cases []Case, n int, run func(Case) Result, report func(Result)) {
+	n = max(n, 1)
+	results := make([]Result, len(cases))
+	done := make([]bool, len(cases))
+	completed := make(chan int)
+
+	pending := make([]int, len(cases))
+	for i := range pending {
+		pending[i] = i
+	}
+	busy := make(map[string]bool)
+	running, exclusive, next := 0, false, 0
+	for len(pending) > 0 || running > 0 {
+		for i := 0; i < len(pending) && running < n && !exclusive; {
+			idx := pending[i]
+			c := cases[idx]
+			devs := c.devices()
+			if devs == nil && running > 0 ||
+				slices.ContainsFunc(devs, func(d string) bool { return busy[d] }) {
+				i++
+				continue
+			}
+			pending = slices.Delete(pending, i, i+1)
+			for _, d := range devs {
+				busy[d] = true
+			}
+			exclusive = devs == nil
+			running++
+			go func() {
+				defer log.HandlePanic()
+				results[idx] = run(c)
+				completed <- idx
+			}()
+		}
+
+		idx := <-completed
+		for _, d := range cases[idx].devices() {
+			delete(busy, d)
+		}
+		exclusive = false
+		running--
+		done[idx] = true
+		for ; next < len(cases) && done[next]; next++ {
+			report(results[next])
+		}
+	}
+}
+
+// devices returns the devices the case uses. It returns nil if the case
+// expects no packet at all, and thus listens on all devices.
+func (t *Case) devices() []string {
+	if len(t.wants()) == 0 {
+		return nil
+	}
+	devs := []string{t.WriteTo}
+	for _, d := range t.readDevices() {
+		if !slices.Contains(devs, d) {
+			devs = append(devs, d)
+		}
+	}
+	return devs
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule(t *testing.T) {
+	want := []byte{1}
+	cases := []Case{
+		{Name: "a", WriteTo: "veth_131_host", ReadFrom: "veth_141_host", Want: want},
+		{Name: "b", WriteTo: "veth_141_host", ReadFrom: "veth_131_host", Want: want},
+		{Name: "c", WriteTo: "veth_151_host", ReadFrom: "veth_121_host", Want: want},
+		{Name: "d", WriteTo: "veth_131_host", ReadFrom: "veth_141_host"},
+		{Name: "e", WriteTo: "veth_int_host", ReadFrom: "veth_int_host", Want: want},
+		{Name: "f", WriteTo: "veth_151_host", ReadFrom: "veth_141_host", Want: want},
+		{Name: "g", WriteTo: "veth_121_host", ReadFrom: "veth_121_host",
+			WantMulti: [][]byte{want, want}},
+	}
+
+	for _, n := range []int{0, 1, 2, 4} {
+		var mu sync.Mutex
+		busy := make(map[string]bool)
+		running, maxRunning := 0, 0
+		run := func(c Case) Result {
+			mu.Lock()
+			devs := c.devices()
+			if devs == nil {
+				assert.Zero(t, running, "exclusive case %s runs concurrently", c.Name)
+			}
+			for _, d := range devs {
+				assert.False(t, busy[d], "device %s used concurrently by %s", d, c.Name)
+				busy[d] = true
+			}
+			running++
+			maxRunning = max(maxRunning, running)
+			mu.Unlock()
+
+			// Later cases complete earlier.
+			time.Sleep(time.Duration(len(cases)-len(c.Name)) * time.Millisecond)
+
+			mu.Lock()
+			for _, d := range devs {
+				delete(busy, d)
+			}
+			running--
+			mu.Unlock()
+			return Result{Name: c.Name}
+		}
+		var reported []string
+		Schedule(cases, n, run, func(r Result) {
+			reported = append(reported, r.Name)
+		})
+		assert.Equal(t, []string{"a", "b", "c", "d", "e", "f", "g"}, reported, "n=%d", n)
+		assert.LessOrEqual(t, maxRunning, max(n, 1), "n=%d", n)
+	}
+}
+
+func TestScheduleConcurrent(t *testing.T) {
+	want := []byte{1}
+	cases := []Case{
+		{Name: "a", WriteTo: "veth_131_host", ReadFrom: "veth_141_host", Want: want},
+		{Name: "b", WriteTo: "veth_151_host", ReadFrom: "veth_121_host", Want: want},
+	}
+	started := make(chan struct{})
+	run := func(c Case) Result {
+		if c.Name == "b" {
+			close(started)
+			return Result{Name: c.Name}
+		}
+		// Case a only completes once case b has started.
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			t.Error("independent cases were not run concurrently")
+		}
+		return Result{Name: c.Name}
+	}
+	var reported []string
+	Schedule(cases, 2, run, func(r Result) { reported = append(reported, r.Name) })
+	assert.Equal(t, []string{"a", "b"}, reported)
+}
+
+func TestCaseDevices(t *testing.T) {
+	want := []byte{1}
+	testCases := map[string]struct {
+		Case    Case
+		Devices []string
+	}{
+		"distinct": {
+			Case:    Case{WriteTo: "veth_131_host", ReadFrom: "veth_141_host", Want: want},
+			Devices: []string{"veth_131_host", "veth_141_host"},
+		},
+		"same": {
+			Case:    Case{WriteTo: "veth_int_host", ReadFrom: "veth_int_host", Want: want},
+			Devices: []string{"veth_int_host"},
+		},
+		"read from multi": {
+			Case: Case{WriteTo: "veth_131_host", Want: want,
+				ReadFromMulti: []string{"veth_141_host", "veth_131_host", "veth_142_host"}},
+			Devices: []string{"veth_131_host", "veth_141_host", "veth_142_host"},
+		},
+		"no packet expected": {
+			Case: Case{WriteTo: "veth_131_host", ReadFrom: "veth_141_host"},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.Devices, tc.Case.devices())
+		})
+	}
+}
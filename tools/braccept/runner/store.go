@@ -0,0 +1,99 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+	"github.com/gopacket/gopacket/pcapgo"
+
+	"github.com/scionproto/scion/pkg/log"
+	"github.com/scionproto/scion/pkg/private/serrors"
+)
+
+// pcapSnapLen is the snapshot length of the stored pcap files. It is large
+// enough for the jumbo frames used by the cases.
+const pcapSnapLen = 65536
+
+// packetStorer records the packets of a case, and stores them as pcap files
+// in the artifact directory of the case.
+type packetStorer struct {
+	StoreDir string
+	TestName string
+
+	pkts []storedPacket
+}
+
+type storedPacket struct {
+	name string
+	ci   gopacket.CaptureInfo
+	data []byte
+}
+
+// storePkt records the packet under the file name. The recorded packets are
+// only written to disk by flush. A nil storer ignores the packet.
+func (s *packetStorer) storePkt(fileName string, packet gopacket.Packet) {
+	if s == nil {
+		return
+	}
+	data := packet.Data()
+	ci := gopacket.CaptureInfo{
+		Length:        len(data),
+		CaptureLength: len(data),
+	}
+	if md := packet.Metadata(); md != nil {
+		ci.Timestamp = md.Timestamp
+	}
+	s.pkts = append(s.pkts, storedPacket{name: fileName, ci: ci, data: data})
+}
+
+// flush writes each recorded packet to a pcap file with Ethernet link type in
+// the artifact directory. Errors are logged.
+func (s *packetStorer) flush() {
+	if err := os.MkdirAll(s.StoreDir, os.ModePerm); err != nil {
+		log.Error(s.TestName, "err", err)
+		return
+	}
+	for _, pkt := range s.pkts {
+		filename := filepath.Join(s.StoreDir, pkt.name+".pcap")
+		if err := writePcap(filename, pkt.ci, pkt.data); err != nil {
+			log.Error(s.TestName, "err", err)
+		}
+	}
+	s.pkts = nil
+}
+
+func writePcap(filename string, ci gopacket.CaptureInfo, data []byte) (err error) {
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return serrors.Wrap("opening pcap file", err)
+	}
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = serrors.Wrap("closing pcap file", closeErr, "file", filename)
+		}
+	}()
+	w := pcapgo.NewWriter(f)
+	if err := w.WriteFileHeader(pcapSnapLen, layers.LinkTypeEthernet); err != nil {
+		return serrors.Wrap("writing pcap header", err, "file", filename)
+	}
+	if err := w.WritePacket(ci, data); err != nil {
+		return serrors.Wrap("writing packet", err, "file", filename)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+	"github.com/gopacket/gopacket/pcapgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketStorer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "ParentToChild")
+	s := &packetStorer{StoreDir: dir, TestName: "ParentToChild"}
+
+	small := prepareInput(t, nil)
+	// A jumbo frame must not be truncated.
+	jumbo := append(prepareInput(t, nil), bytes.Repeat([]byte{0xab}, 8000)...)
+	s.storePkt("want", gopacket.NewPacket(small, layers.LinkTypeEthernet, gopacket.Default))
+	s.storePkt("got-0", gopacket.NewPacket(jumbo, layers.LinkTypeEthernet, gopacket.Default))
+
+	// Nothing is written before flush.
+	_, err := os.Stat(dir)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// An existing longer file is replaced.
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "want.pcap"),
+		bytes.Repeat([]byte{0xff}, 4096), 0o600))
+
+	s.flush()
+	for name, want := range map[string][]byte{"want": small, "got-0": jumbo} {
+		f, err := os.Open(filepath.Join(dir, name+".pcap"))
+		require.NoError(t, err)
+		defer f.Close()
+		r, err := pcapgo.NewReader(f)
+		require.NoError(t, err)
+		assert.Equal(t, layers.LinkTypeEthernet, r.LinkType())
+		data, _, err := r.ReadPacketData()
+		require.NoError(t, err, name)
+		assert.Equal(t, want, data, name)
+		_, _, err = r.ReadPacketData()
+		assert.Error(t, err, "%s contains a single packet", name)
+	}
+}
+
+func TestPacketStorerNil(t *testing.T) {
+	var s *packetStorer
+	pkt := gopacket.NewPacket(prepareInput(t, nil), layers.LinkTypeEthernet, gopacket.Default)
+	assert.NotPanics(t, func() { s.storePkt("got-0", pkt) })
+}
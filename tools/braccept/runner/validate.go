@@ -0,0 +1,220 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+
+	"github.com/scionproto/scion/pkg/private/serrors"
+	"github.com/scionproto/scion/pkg/slayers"
+	"github.com/scionproto/scion/pkg/slayers/path/scion"
+)
+
+// ValidateInput checks that the input packet of the case is well-formed. It
+// applies the checks that are done on captured packets, and additionally
+// checks the header lengths, the consistency of SCION paths and the
+// checksums. Cases with InputMayBeMalformed set are not validated.
+func (t *Case) ValidateInput() error {
+	if t.InputMayBeMalformed {
+		return nil
+	}
+	pkt := gopacket.NewPacket(t.Input, layers.LinkTypeEthernet, gopacket.Default)
+	if err := validatePacket(pkt); err != nil {
+		return serrors.Wrap("invalid input packet", err, "case", t.Name)
+	}
+	return nil
+}
+
+// decodeError returns the error that occurred while decoding the packet, if
+// any. This is the check that is applied to captured packets.
+func decodeError(pkt gopacket.Packet) error {
+	if err := pkt.ErrorLayer(); err != nil {
+		return serrors.Wrap("error decoding packet", err.Error())
+	}
+	return nil
+}
+
+// validatePacket checks that the packet is well-formed. Checksums that are set
+// to zero are not verified.
+func validatePacket(pkt gopacket.Packet) error {
+	if err := decodeError(pkt); err != nil {
+		return err
+	}
+	if pkt.Metadata().Truncated {
+		return serrors.New("packet truncated")
+	}
+	var errs serrors.List
+	var ip gopacket.NetworkLayer
+	var scn *slayers.SCION
+	for _, l := range pkt.Layers() {
+		var err error
+		switch v := l.(type) {
+		case *layers.IPv4:
+			ip = v
+			err = validateIPv4(v)
+		case *layers.IPv6:
+			ip = v
+			err = validateIPv6(v)
+		case *layers.UDP:
+			err = validateUDP(v, ip)
+		case *slayers.SCION:
+			scn = v
+			err = validateSCION(v)
+		case *slayers.UDP:
+			err = validateSCIONUDP(v, scn)
+		case *slayers.SCMP:
+			err = validateSCMP(v, scn)
+		}
+		if err != nil {
+			errs = append(errs, serrors.Wrap("invalid layer", err,
+				"layer", l.LayerType()))
+		}
+	}
+	return errs.ToError()
+}
+
+func validateIPv4(ip *layers.IPv4) error {
+	if int(ip.Length) != len(ip.Contents)+len(ip.Payload) {
+		return serrors.New("length mismatch",
+			"header", ip.Length, "actual", len(ip.Contents)+len(ip.Payload))
+	}
+	if ip.Checksum == 0 {
+		return nil
+	}
+	// The checksum over a header with a valid checksum is zero.
+	var sum uint32
+	for i := 0; i+1 < len(ip.Contents); i += 2 {
+		sum += uint32(ip.Contents[i])<<8 | uint32(ip.Contents[i+1])
+	}
+	for sum > 0xffff {
+		sum = (sum >> 16) + (sum & 0xffff)
+	}
+	if sum != 0xffff {
+		return serrors.New("bad checksum", "checksum", ip.Checksum)
+	}
+	return nil
+}
+
+func validateIPv6(ip *layers.IPv6) error {
+	if int(ip.Length) != len(ip.Payload) {
+		return serrors.New("payload length mismatch",
+			"header", ip.Length, "actual", len(ip.Payload))
+	}
+	return nil
+}
+
+func validateUDP(udp *layers.UDP, ip gopacket.NetworkLayer) error {
+	if int(udp.Length) != len(udp.Contents)+len(udp.Payload) {
+		return serrors.New("length mismatch",
+			"header", udp.Length, "actual", len(udp.Contents)+len(udp.Payload))
+	}
+	if udp.Checksum == 0 || ip == nil {
+		return nil
+	}
+	c := *udp
+	if err := c.SetNetworkLayerForChecksum(ip); err != nil {
+		return err
+	}
+	if err := serializeWithChecksum(&c, udp.Payload); err != nil {
+		return err
+	}
+	if c.Checksum != udp.Checksum {
+		return serrors.New("bad checksum", "checksum", udp.Checksum, "expected", c.Checksum)
+	}
+	return nil
+}
+
+func validateSCION(s *slayers.SCION) error {
+	if int(s.PayloadLen) != len(s.Payload) {
+		return serrors.New("payload length mismatch",
+			"header", s.PayloadLen, "actual", len(s.Payload))
+	}
+	if s.PathType != scion.PathType {
+		return nil
+	}
+	raw, ok := s.Path.(*scion.Raw)
+	if !ok {
+		return nil
+	}
+	var p scion.Decoded
+	if err := p.DecodeFromBytes(raw.Raw); err != nil {
+		return serrors.Wrap("decoding path", err)
+	}
+	if p.Len() != len(raw.Raw) {
+		return serrors.New("path length mismatch", "expected", p.Len(), "actual", len(raw.Raw))
+	}
+	if p.NumINF == 0 {
+		return serrors.New("path without info fields")
+	}
+	currHF, currINF := int(p.PathMeta.CurrHF), int(p.PathMeta.CurrINF)
+	if currHF >= p.NumHops {
+		return serrors.New("CurrHF out of range", "curr_hf", currHF, "num_hops", p.NumHops)
+	}
+	if currINF >= p.NumINF {
+		return serrors.New("CurrINF out of range", "curr_inf", currINF, "num_inf", p.NumINF)
+	}
+	start := 0
+	for i := 0; i < currINF; i++ {
+		start += int(p.PathMeta.SegLen[i])
+	}
+	if currHF < start || currHF >= start+int(p.PathMeta.SegLen[currINF]) {
+		return serrors.New("CurrHF not in current segment",
+			"curr_hf", currHF, "curr_inf", currINF)
+	}
+	return nil
+}
+
+func validateSCIONUDP(udp *slayers.UDP, s *slayers.SCION) error {
+	if int(udp.Length) != len(udp.Contents)+len(udp.Payload) {
+		return serrors.New("length mismatch",
+			"header", udp.Length, "actual", len(udp.Contents)+len(udp.Payload))
+	}
+	if s == nil {
+		return nil
+	}
+	c := *udp
+	c.SetNetworkLayerForChecksum(s)
+	if err := serializeWithChecksum(&c, udp.Payload); err != nil {
+		return err
+	}
+	if c.Checksum != udp.Checksum {
+		return serrors.New("bad checksum", "checksum", udp.Checksum, "expected", c.Checksum)
+	}
+	return nil
+}
+
+func validateSCMP(scmp *slayers.SCMP, s *slayers.SCION) error {
+	if s == nil {
+		return nil
+	}
+	c := *scmp
+	c.SetNetworkLayerForChecksum(s)
+	if err := serializeWithChecksum(&c, scmp.Payload); err != nil {
+		return err
+	}
+	if c.Checksum != scmp.Checksum {
+		return serrors.New("bad checksum", "checksum", scmp.Checksum, "expected", c.Checksum)
+	}
+	return nil
+}
+
+// serializeWithChecksum serializes the layer followed by the payload, which
+// updates the checksum field of the layer.
+func serializeWithChecksum(l gopacket.SerializableLayer, payload []byte) error {
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{ComputeChecksums: true}
+	return gopacket.SerializeLayers(buf, opts, l, gopacket.Payload(payload))
+}
@@ -0,0 +1,228 @@
+// Copyright 2026 SCION Association
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"net"
+	"testing"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scionproto/scion/pkg/addr"
+	"github.com/scionproto/scion/pkg/slayers"
+	"github.com/scionproto/scion/pkg/slayers/path"
+	"github.com/scionproto/scion/pkg/slayers/path/scion"
+)
+
+func TestValidateInput(t *testing.T) {
+	layers.RegisterUDPPortLayerType(layers.UDPPort(30001), slayers.LayerTypeSCION)
+
+	testCases := map[string]struct {
+		modify    func(scionL *slayers.SCION, raw []byte) []byte
+		malformed bool
+		assertErr assert.ErrorAssertionFunc
+	}{
+		"valid": {
+			assertErr: assert.NoError,
+		},
+		"bad IPv4 checksum": {
+			modify: func(_ *slayers.SCION, raw []byte) []byte {
+				// The IPv4 checksum is at offset 10 of the IPv4 header.
+				raw[14+10] ^= 0xff
+				return raw
+			},
+			assertErr: assert.Error,
+		},
+		"bad SCION/UDP checksum": {
+			modify: func(_ *slayers.SCION, raw []byte) []byte {
+				raw[len(raw)-1] ^= 0xff
+				return raw
+			},
+			assertErr: assert.Error,
+		},
+		"bad payload length": {
+			modify: func(scionL *slayers.SCION, raw []byte) []byte {
+				scionL.PayloadLen = 2
+				return nil
+			},
+			assertErr: assert.Error,
+		},
+		"CurrHF not in segment": {
+			modify: func(scionL *slayers.SCION, raw []byte) []byte {
+				scionL.Path.(*scion.Decoded).PathMeta.CurrHF = 2
+				return nil
+			},
+			assertErr: assert.Error,
+		},
+		"IPv6 underlay": {
+			modify: func(_ *slayers.SCION, raw []byte) []byte {
+				return toIPv6(t, raw, 0)
+			},
+			assertErr: assert.NoError,
+		},
+		"bad UDP checksum over IPv6": {
+			modify: func(_ *slayers.SCION, raw []byte) []byte {
+				raw = toIPv6(t, raw, 0)
+				// The UDP checksum is at offset 6 of the UDP header.
+				raw[14+40+6] ^= 0xff
+				return raw
+			},
+			assertErr: assert.Error,
+		},
+		"malformed allowed": {
+			modify: func(scionL *slayers.SCION, raw []byte) []byte {
+				scionL.PayloadLen = 2
+				return nil
+			},
+			malformed: true,
+			assertErr: assert.NoError,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			c := Case{
+				Name:                name,
+				Input:               prepareInput(t, tc.modify),
+				InputMayBeMalformed: tc.malformed,
+			}
+			tc.assertErr(t, c.ValidateInput())
+		})
+	}
+}
+
+// prepareInput serializes an IPv4/UDP/SCION/UDP packet. If modify is set, it
+// is called with the SCION layer and the serialized packet. If modify returns
+// nil, the layers are serialized again without fixing the lengths.
+func prepareInput(t *testing.T, modify func(*slayers.SCION, []byte) []byte) []byte {
+	t.Helper()
+	ethernet := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0xbe, 0xef},
+		DstMAC:       net.HardwareAddr{0xf0, 0x0d, 0xca, 0xfe, 0x00, 0x01},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		SrcIP:    net.IP{192, 168, 0, 13},
+		DstIP:    net.IP{192, 168, 0, 11},
+		Protocol: layers.IPProtocolUDP,
+		Flags:    layers.IPv4DontFragment,
+	}
+	udp := &layers.UDP{
+		SrcPort: layers.UDPPort(30003),
+		DstPort: layers.UDPPort(30001),
+	}
+	require.NoError(t, udp.SetNetworkLayerForChecksum(ip))
+	sp := &scion.Decoded{
+		Base: scion.Base{
+			PathMeta: scion.MetaHdr{
+				CurrHF: 1,
+				SegLen: [3]uint8{2, 0, 0},
+			},
+			NumINF:  1,
+			NumHops: 2,
+		},
+		InfoFields: []path.InfoField{{SegID: 0x111}},
+		HopFields: []path.HopField{
+			{ConsIngress: 0, ConsEgress: 141},
+			{ConsIngress: 311, ConsEgress: 0},
+		},
+	}
+	scionL := &slayers.SCION{
+		Version:  0,
+		NextHdr:  slayers.L4UDP,
+		PathType: scion.PathType,
+		SrcIA:    addr.MustParseIA("1-ff00:0:4"),
+		DstIA:    addr.MustParseIA("1-ff00:0:1"),
+		Path:     sp,
+	}
+	require.NoError(t, scionL.SetSrcAddr(addr.MustParseHost("172.16.4.1")))
+	require.NoError(t, scionL.SetDstAddr(addr.MustParseHost("192.168.0.51")))
+	scionudp := &slayers.UDP{}
+	scionudp.SrcPort = 40111
+	scionudp.DstPort = 40222
+	scionudp.SetNetworkLayerForChecksum(scionL)
+	payload := gopacket.Payload("actualpayloadbytes")
+
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+	buf := gopacket.NewSerializeBuffer()
+	require.NoError(t, gopacket.SerializeLayers(buf, options,
+		ethernet, ip, udp, scionL, scionudp, payload))
+	raw := buf.Bytes()
+	if modify == nil {
+		return raw
+	}
+	if raw = modify(scionL, raw); raw != nil {
+		return raw
+	}
+	options.FixLengths = false
+	buf = gopacket.NewSerializeBuffer()
+	require.NoError(t, gopacket.SerializeLayers(buf, options,
+		ethernet, ip, udp, scionL, scionudp, payload))
+	return buf.Bytes()
+}
+
+// toIPv6 replaces the IPv4 underlay of the packet with an IPv6 underlay with
+// the given flow label.
+func toIPv6(t *testing.T, raw []byte, flowLabel uint32) []byte {
+	t.Helper()
+	pkt := gopacket.NewPacket(raw, layers.LinkTypeEthernet, gopacket.Default)
+	ethernet := pkt.Layer(layers.LayerTypeEthernet).(*layers.Ethernet)
+	ethernet.EthernetType = layers.EthernetTypeIPv6
+	ip := &layers.IPv6{
+		Version:    6,
+		HopLimit:   64,
+		FlowLabel:  flowLabel,
+		SrcIP:      net.ParseIP("fd00:f00d:cafe::13"),
+		DstIP:      net.ParseIP("fd00:f00d:cafe::11"),
+		NextHeader: layers.IPProtocolUDP,
+	}
+	udp := pkt.Layer(layers.LayerTypeUDP).(*layers.UDP)
+	require.NoError(t, udp.SetNetworkLayerForChecksum(ip))
+	buf := gopacket.NewSerializeBuffer()
+	require.NoError(t, gopacket.SerializeLayers(buf,
+		gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true},
+		ethernet, ip, udp, gopacket.Payload(udp.Payload)))
+	return buf.Bytes()
+}
+
+// toVLAN inserts an 802.1Q tag with the VLAN ID into the Ethernet frame and
+// sets the ID of the IPv4 header.
+func toVLAN(t *testing.T, raw []byte, vlan uint16, ipID uint16) []byte {
+	t.Helper()
+	pkt := gopacket.NewPacket(raw, layers.LinkTypeEthernet, gopacket.Default)
+	ethernet := pkt.Layer(layers.LayerTypeEthernet).(*layers.Ethernet)
+	ethernet.EthernetType = layers.EthernetTypeDot1Q
+	tag := &layers.Dot1Q{
+		VLANIdentifier: vlan,
+		Type:           layers.EthernetTypeIPv4,
+	}
+	ip := pkt.Layer(layers.LayerTypeIPv4).(*layers.IPv4)
+	ip.Id = ipID
+	udp := pkt.Layer(layers.LayerTypeUDP).(*layers.UDP)
+	require.NoError(t, udp.SetNetworkLayerForChecksum(ip))
+	buf := gopacket.NewSerializeBuffer()
+	require.NoError(t, gopacket.SerializeLayers(buf,
+		gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true},
+		ethernet, tag, ip, udp, gopacket.Payload(udp.Payload)))
+	return buf.Bytes()
+}